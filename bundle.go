@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	databases "gitlab.com/fibocloud/medtech/gin/databases"
+	services "gitlab.com/fibocloud/medtech/gin/services"
+)
+
+// refBundleExport лавлахын багцыг файлд бичнэ, формат нь өргөтгөлөөс
+func refBundleExport(path string) error {
+	db := databases.InitDB()
+	if err := services.EnsureRefCodeColumns(db); err != nil {
+		return err
+	}
+
+	bundle, err := services.ExportRefBundle(db)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := services.WriteRefBundle(file, bundle, path); err != nil {
+		return err
+	}
+
+	for _, table := range bundle.Tables {
+		fmt.Printf("%s: %d\n", table.Table, len(table.Rows))
+	}
+	return nil
+}
+
+// refBundleImport файлаас багц импортлоод тайланг хэвлэнэ. dryRun бол хадгалахгүй.
+func refBundleImport(path string, dryRun bool) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	bundle, err := services.ReadRefBundle(file, path)
+	if err != nil {
+		return err
+	}
+
+	db := databases.InitDB()
+	if err := services.EnsureRefCodeColumns(db); err != nil {
+		return err
+	}
+
+	tx := db.Begin()
+	report, err := services.ImportRefBundle(tx, bundle, 0)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	report.DryRun = dryRun
+	if dryRun {
+		tx.Rollback()
+	} else if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	output, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(output))
+	return nil
+}
+
+// refCodesSeed кодгүй хуучин өгөгдлийн санд системийн кодыг LegacyID бичлэгт онооно.
+// dryRun бол хадгалахгүй.
+func refCodesSeed(dryRun bool) error {
+	db := databases.InitDB()
+
+	tx := db.Begin()
+	assigned, err := services.SeedLegacyRefCodes(tx)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if dryRun {
+		tx.Rollback()
+	} else if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	for _, line := range assigned {
+		fmt.Println(line)
+	}
+	return nil
+}
@@ -1,10 +1,12 @@
 package constracts
 
+// Бичиг баримтын төрлийн код, med_content_types.code
+
 // ContentLicence // Тусгай зөвшөөрөл
-var ContentLicence = 1
+const ContentLicence = "licence"
 
 // ContentCertification // Улсын бүртгэлийн гэрчилгээ хуулбар
-var ContentCertification = 2
+const ContentCertification = "certification"
 
 // ContentDirectorCards //Захиралын иргэний үнэмлэх 2 тал
-var ContentDirectorCards = 3
+const ContentDirectorCards = "director_cards"
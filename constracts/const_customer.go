@@ -1,4 +1,30 @@
 package constracts
 
-// CustomerPermissionCreated Эрх үүссэн
-var CustomerPermissionCreated = 13
+// Харилцагчийн төлөвийн код, med_statuses.code
+
+// StatusTypeCustomer харилцагчийн төлөвийн төрөл, med_status_types.code
+const StatusTypeCustomer = "customer"
+
+// StatusCustomerAccountConfirmed Бүртгэл баталгаажсан
+const StatusCustomerAccountConfirmed = "customer_account_confirmed"
+
+// StatusCustomerPermissionCreated Эрх үүссэн
+const StatusCustomerPermissionCreated = "customer_permission_created"
+
+// StatusOrderReturned Захиалга буцаагдсан, med_statuses.code
+const StatusOrderReturned = "order_returned"
+
+// MongoliaCountry Монгол улсын код, ref_countries.code
+const MongoliaCountry = "MN"
+
+// AddressTypeDefault хаягийн төрөл сонгоогүй үеийн төрөл, med_address_types.code
+const AddressTypeDefault = "default"
+
+// ClassificationDefault шинэ толгой байгууллагын ангиллын код, med_customer_classifications.code
+const ClassificationDefault = "default"
+
+// PriceTypeSales зарах үнийн төрлийн код, med_price_types.code
+const PriceTypeSales = "sales"
+
+// PriceTypeCustomer харилцагчийн тусгай үнийн төрлийн код, med_price_types.code
+const PriceTypeCustomer = "customer"
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"log"
 	"net/http"
 
 	gin "github.com/gin-gonic/gin"
@@ -9,6 +10,7 @@ import (
 	User "gitlab.com/fibocloud/medtech/gin/controllers/user"
 	databases "gitlab.com/fibocloud/medtech/gin/databases"
 	middlewares "gitlab.com/fibocloud/medtech/gin/middlewares"
+	services "gitlab.com/fibocloud/medtech/gin/services"
 	structs "gitlab.com/fibocloud/medtech/gin/structs"
 )
 
@@ -26,6 +28,21 @@ func Init(router *gin.RouterGroup) {
 		},
 		DB: db,
 	}
+
+	services.InitRefCache(db)
+	if err := services.InitRefCodes(db); err != nil {
+		log.Fatalln("ref codes:", err)
+	}
+
+	// region [ Jobs ]
+	services.SeedWorkflow(db)
+	services.StartTaxRefreshJob(db)
+	services.StartPriceChangeJob(db)
+	services.StartClassificationJob(db)
+	services.StartExchangeRateJob(db)
+	services.StartPaymentScheduleJob(db)
+	// endregion
+
 	AuthController{bc}.Init(router.Group("/auth"))
 	authRouter := router.Group("")
 	authRouter.Use(middlewares.Authenticate(db))
@@ -37,7 +54,11 @@ func Init(router *gin.RouterGroup) {
 
 		// region [ User ]
 		User.CustomerController{bc}.Init(authRouter.Group("/customer"))
+		User.CustomerContactController{bc}.Init(authRouter.Group("/customerContact"))
+		User.CustomerAddressController{bc}.Init(authRouter.Group("/customerAddress"))
 		User.CustomerTypeController{bc}.Init(authRouter.Group("/customerType"))
+		User.PriceRuleController{bc}.Init(authRouter.Group("/priceRule"))
+		User.PriceChangeController{bc}.Init(authRouter.Group("/priceChange"))
 		User.CustomerClassificationController{bc}.Init(authRouter.Group("/customerClassification"))
 		User.DepartmentController{bc}.Init(authRouter.Group("/department"))
 		User.PersonController{bc}.Init(authRouter.Group("/person"))
@@ -51,6 +72,7 @@ func Init(router *gin.RouterGroup) {
 		// region [ Reference ]
 		reference.PaymentTypeController{bc}.Init(authRouter.Group("/paymentType"))
 		reference.PaymentMethodController{bc}.Init(authRouter.Group("/paymentMethod"))
+		reference.PaymentTermController{bc}.Init(authRouter.Group("/paymentTerm"))
 		reference.CountryController{bc}.Init(authRouter.Group("/country"))
 		reference.CityController{bc}.Init(authRouter.Group("/city"))
 		reference.DistrictController{bc}.Init(authRouter.Group("/district"))
@@ -59,6 +81,11 @@ func Init(router *gin.RouterGroup) {
 		reference.StatusTypeController{bc}.Init(authRouter.Group("/statusType"))
 		reference.ValuteController{bc}.Init(authRouter.Group("/valute"))
 		reference.AddressTypeController{bc}.Init(authRouter.Group("/addressType"))
+		reference.NumberFormatController{bc}.Init(authRouter.Group("/numberFormat"))
+		reference.StatusTransitionController{bc}.Init(authRouter.Group("/statusTransition"))
+		reference.TranslationController{bc}.Init(authRouter.Group("/translation"))
+		reference.GeoController{bc}.Init(authRouter.Group("/geo"))
+		reference.RefBundleController{bc}.Init(authRouter.Group("/refBundle"))
 		// endregion
 	}
 }
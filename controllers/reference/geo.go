@@ -0,0 +1,117 @@
+package reference
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	gin "github.com/gin-gonic/gin"
+	"gitlab.com/fibocloud/medtech/gin/controllers/shared"
+	services "gitlab.com/fibocloud/medtech/gin/services"
+)
+
+// GeoController struct
+type GeoController struct {
+	shared.BaseController
+}
+
+// Init Controller
+func (co GeoController) Init(router *gin.RouterGroup) {
+	router.GET("/search", co.Search) // Search
+	router.GET("/tree", co.Tree)     // Tree
+}
+
+// Search geo
+// @Summary Search geo
+// @Description Search countries, cities, districts and streets by name prefix, tolerating typos and ө/ү/ё typed as о/у/е.
+// @Description Several words narrow by path, e.g. "улаанбаатар баянзүрх". Results carry the full path from the country.
+// @Tags Geo
+// @Accept json
+// @Produce json
+// @Param q query string true "search text"
+// @Param types query string false "comma separated: country, city, district, street"
+// @Param limit query int false "default 20, max 100"
+// @Param lang query string false "locale, Accept-Language when empty"
+// @Success 200 {object} structs.ResponseBody{body=[]services.GeoMatch}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /geo/search [get]
+func (co GeoController) Search(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		co.SetError(http.StatusBadRequest, "Хайх утга оруулна уу")
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	switch {
+	case limit <= 0:
+		limit = 20
+	case limit > 100:
+		limit = 100
+	}
+
+	var types []string
+	for _, geoType := range strings.Split(c.Query("types"), ",") {
+		if geoType = strings.TrimSpace(geoType); geoType != "" {
+			types = append(types, geoType)
+		}
+	}
+
+	matches, err := services.GeoSearch(co.DB, query, types, limit, shared.RequestLocales(c))
+	if err != nil {
+		co.SetError(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	co.SetBody(matches)
+	return
+}
+
+// Tree geo
+// @Summary Tree geo
+// @Description Children of one node for lazy tree loading. Without type the countries are returned.
+// @Tags Geo
+// @Accept json
+// @Produce json
+// @Param type query string false "parent type: country, city, district"
+// @Param id query uint false "parent ID"
+// @Param lang query string false "locale, Accept-Language when empty"
+// @Success 200 {object} structs.ResponseBody{body=[]services.GeoNode}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /geo/tree [get]
+func (co GeoController) Tree(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	var parentID int
+	parentType := c.Query("type")
+	if parentType != "" {
+		var err error
+		parentID, err = strconv.Atoi(c.Query("id"))
+		if err != nil || parentID <= 0 {
+			co.SetError(http.StatusBadRequest, "id буруу байна")
+			return
+		}
+	}
+
+	nodes, err := services.GeoChildren(co.DB, parentType, uint(parentID), shared.RequestLocales(c))
+	if err != nil {
+		if errors.Is(err, services.ErrGeoType) {
+			co.SetError(http.StatusBadRequest, err.Error())
+			return
+		}
+		co.SetError(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	co.SetBody(nodes)
+	return
+}
@@ -12,6 +12,7 @@ import (
 	"gitlab.com/fibocloud/medtech/gin/controllers/shared"
 	databases "gitlab.com/fibocloud/medtech/gin/databases"
 	form "gitlab.com/fibocloud/medtech/gin/form"
+	services "gitlab.com/fibocloud/medtech/gin/services"
 	structs "gitlab.com/fibocloud/medtech/gin/structs"
 )
 
@@ -44,6 +45,7 @@ func (co AddressTypeController) Init(router *gin.RouterGroup) {
 // @Accept json
 // @Produce json
 // @Param filter body form.CityFilter true "filter"
+// @Param lang query string false "locale, Accept-Language when empty"
 // @Success 200 {object} structs.ResponseBody{body=[]databases.MedAddressType}
 // @Failure 400 {object} structs.ErrorResponse
 // @Failure 500 {object} structs.ErrorResponse
@@ -73,6 +75,7 @@ func (co AddressTypeController) List(c *gin.Context) {
 
 	var types []databases.MedAddressType
 	db.Find(&types)
+	co.Localize(c, &types)
 
 	db.Table("ref_cities").Count(&count)
 
@@ -90,19 +93,23 @@ func (co AddressTypeController) List(c *gin.Context) {
 // @Tags City
 // @Accept json
 // @Produce json
+// @Param lang query string false "locale, Accept-Language when empty"
+// @Param If-None-Match header string false "өмнөх ETag"
 // @Success 200 {object} structs.ResponseBody{body=[]databases.MedAddressType}
+// @Header 200 {string} ETag "лавлахын хувилбар"
+// @Success 304 "өөрчлөгдөөгүй"
 // @Failure 400 {object} structs.ErrorResponse
 // @Failure 500 {object} structs.ErrorResponse
 // @Router /city/list/active [get]
 func (co AddressTypeController) ListActive(c *gin.Context) {
-	defer func() {
-		c.JSON(co.GetBody())
-	}()
-
-	var cities []databases.MedAddressType
-	co.DB.Find(&cities)
-	co.SetBody(cities)
-	return
+	co.CachedJSON(c, "addressType.active", []string{"med_address_types", "med_translations"}, func() (interface{}, error) {
+		var cities []databases.MedAddressType
+		if err := co.DB.Find(&cities).Error; err != nil {
+			return nil, err
+		}
+		co.Localize(c, &cities)
+		return cities, nil
+	})
 }
 
 // Get city
@@ -112,6 +119,7 @@ func (co AddressTypeController) ListActive(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path uint true "city ID"
+// @Param lang query string false "locale, Accept-Language when empty"
 // @Success 200 {object} structs.ResponseBody{body=databases.MedAddressType}
 // @Failure 400 {object} structs.ErrorResponse
 // @Failure 500 {object} structs.ErrorResponse
@@ -128,6 +136,7 @@ func (co AddressTypeController) Get(c *gin.Context) {
 		return
 	}
 
+	co.Localize(c, &city)
 	co.SetBody(city)
 	return
 }
@@ -247,6 +256,13 @@ func (co AddressTypeController) Delete(c *gin.Context) {
 		return
 	}
 
+	for _, v := range params.IDs {
+		if services.RegisteredRef(services.TableAddressTypes, v) {
+			co.SetError(http.StatusBadRequest, services.ErrRefCodeRequired.Error())
+			return
+		}
+	}
+
 	for _, v := range params.IDs {
 		result := co.DB.Delete(&databases.MedAddressType{}, v)
 		if result.Error != nil {
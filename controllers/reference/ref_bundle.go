@@ -0,0 +1,150 @@
+package reference
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"net/http"
+
+	gin "github.com/gin-gonic/gin"
+	"gitlab.com/fibocloud/medtech/gin/controllers/shared"
+	services "gitlab.com/fibocloud/medtech/gin/services"
+)
+
+// RefBundleController struct
+type RefBundleController struct {
+	shared.BaseController
+}
+
+// Init Controller
+func (co RefBundleController) Init(router *gin.RouterGroup) {
+	router.GET("/export", co.Export)  // Export
+	router.POST("/import", co.Import) // Import
+}
+
+// Export refBundle
+// @Summary Export refBundle
+// @Description Statuses, status types, payment types and methods, address types, content types, measures,
+// @Description specifications, classifications and valutes keyed by name instead of ID, with the rows holding each system code.
+// @Tags RefBundle
+// @Accept json
+// @Produce json,text/yaml
+// @Param format query string false "json, yaml"
+// @Success 200 {file} file
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /refBundle/export [get]
+func (co RefBundleController) Export(c *gin.Context) {
+	format := services.RefBundleFormat(c.Query("format"))
+
+	bundle, err := services.ExportRefBundle(co.DB)
+	if err != nil {
+		co.SetError(http.StatusInternalServerError, err.Error())
+		c.JSON(co.GetBody())
+		return
+	}
+
+	var buffer bytes.Buffer
+	if err := services.WriteRefBundle(&buffer, bundle, format); err != nil {
+		co.setBundleError(err)
+		c.JSON(co.GetBody())
+		return
+	}
+
+	contentType := "application/json; charset=utf-8"
+	if format == "yaml" {
+		contentType = "text/yaml; charset=utf-8"
+	}
+	fileName := "refs-" + bundle.ExportedDate.Format("20060102") + "." + format
+	c.Header("Content-Disposition", "attachment; filename="+fileName)
+	c.Data(http.StatusOK, contentType, buffer.Bytes())
+}
+
+// Import refBundle
+// @Summary Import refBundle
+// @Description Creates missing records and updates changed ones by key. Records absent from the bundle are reported, not deleted.
+// @Description The report lists every system code with its ID here and flags missing ones. dry_run reports the diff without saving.
+// @Tags RefBundle
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "json, yaml"
+// @Param dry_run formData bool false "diff only"
+// @Success 200 {object} structs.ResponseBody{body=services.RefBundleReport}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 403 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /refBundle/import [post]
+func (co RefBundleController) Import(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	authUser := co.GetAuth(c)
+	allowed, err := services.UserHasPermission(co.DB, authUser, services.RefBundleImportPermission())
+	if err != nil {
+		co.SetError(http.StatusInternalServerError, err.Error())
+		return
+	}
+	if !allowed {
+		co.setBundleError(services.ErrBundleRight)
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		co.SetError(http.StatusBadRequest, "Файл оруулна уу")
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		co.SetError(http.StatusBadRequest, err.Error())
+		return
+	}
+	defer file.Close()
+
+	bundle, err := services.ReadRefBundle(file, fileHeader.Filename)
+	if err != nil {
+		co.SetError(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	tx := co.DB.Begin()
+
+	report, err := services.ImportRefBundle(tx, bundle, authUser.Base.ID)
+	if err != nil {
+		tx.Rollback()
+		co.setBundleError(err)
+		return
+	}
+
+	report.DryRun = c.PostForm("dry_run") == "true"
+	if report.DryRun {
+		tx.Rollback()
+	} else {
+		if err := tx.Commit().Error; err != nil {
+			co.SetError(http.StatusInternalServerError, err.Error())
+			return
+		}
+		// Кодтой бичлэг шинээр үүссэн, код нь өөр бичлэг рүү шилжсэн байж болно
+		if err := services.InitRefCodes(co.DB); err != nil {
+			log.Println("ref codes reload:", err)
+		}
+	}
+
+	co.SetBody(report)
+	return
+}
+
+func (co RefBundleController) setBundleError(err error) {
+	switch {
+	case errors.Is(err, services.ErrBundleFormat),
+		errors.Is(err, services.ErrBundleVersion),
+		errors.Is(err, services.ErrBundleParent):
+		co.SetError(http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrBundleRight):
+		co.SetError(http.StatusForbidden, err.Error())
+	default:
+		co.SetError(http.StatusInternalServerError, err.Error())
+	}
+}
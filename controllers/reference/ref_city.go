@@ -1,9 +1,9 @@
 package reference
 
 import (
-	"encoding/json"
-	"io/ioutil"
+	"io"
 	"net/http"
+	"os"
 	"reflect"
 	"time"
 
@@ -11,6 +11,7 @@ import (
 	"gitlab.com/fibocloud/medtech/gin/controllers/shared"
 	databases "gitlab.com/fibocloud/medtech/gin/databases"
 	form "gitlab.com/fibocloud/medtech/gin/form"
+	services "gitlab.com/fibocloud/medtech/gin/services"
 	structs "gitlab.com/fibocloud/medtech/gin/structs"
 )
 
@@ -19,20 +20,8 @@ type CityController struct {
 	shared.BaseController
 }
 
-// RegionStruct ...
-type RegionStruct struct {
-	ID           int         `json:"id"`
-	RegionName   string      `json:"region_name"`
-	RegionNameEn interface{} `json:"region_name_en"`
-	RegionTypeID int         `json:"region_type_id"`
-	ParentID     int         `json:"parent_id"`
-	Order        int         `json:"order"`
-	CreatedAt    string      `json:"created_at"`
-	UpdatedAt    string      `json:"updated_at"`
-	IsDeleted    bool        `json:"is_deleted"`
-	CreateID     int         `json:"create_id"`
-	UpdateID     int         `json:"update_id"`
-}
+// regionFile улсын бүс нутгийн мод
+const regionFile = "files/region.json"
 
 // ListCities ...
 type ListCities struct {
@@ -49,6 +38,7 @@ func (co CityController) Init(router *gin.RouterGroup) {
 	router.PUT("/:id", co.Update)                        // Update
 	router.DELETE("", co.Delete)                         // Delete
 	router.GET("/initdb", co.InitDB)                     // InitDB
+	router.POST("/region/import", co.ImportRegions)      // ImportRegions
 }
 
 // List city
@@ -58,6 +48,7 @@ func (co CityController) Init(router *gin.RouterGroup) {
 // @Accept json
 // @Produce json
 // @Param filter body form.CityFilter true "filter"
+// @Param lang query string false "locale, Accept-Language when empty"
 // @Success 200 {object} structs.ResponseBody{body=[]databases.RefCity}
 // @Failure 400 {object} structs.ErrorResponse
 // @Failure 500 {object} structs.ErrorResponse
@@ -87,6 +78,7 @@ func (co CityController) List(c *gin.Context) {
 
 	var cities []databases.RefCity
 	db.Preload("Country").Find(&cities)
+	co.Localize(c, &cities)
 
 	db.Table("ref_cities").Count(&count)
 
@@ -104,25 +96,29 @@ func (co CityController) List(c *gin.Context) {
 // @Tags City
 // @Accept json
 // @Produce json
+// @Param lang query string false "locale, Accept-Language when empty"
+// @Param If-None-Match header string false "өмнөх ETag"
 // @Success 200 {object} structs.ResponseBody{body=[]databases.RefCity}
+// @Header 200 {string} ETag "лавлахын хувилбар"
+// @Success 304 "өөрчлөгдөөгүй"
 // @Failure 400 {object} structs.ErrorResponse
 // @Failure 500 {object} structs.ErrorResponse
 // @Router /city/list/active/{county_id} [get]
 func (co CityController) ListActive(c *gin.Context) {
-	defer func() {
-		c.JSON(co.GetBody())
-	}()
+	countryID := c.Param("county_id")
 
-	var cities []databases.RefCity
-
-	if c.Param("county_id") == "" {
-		co.DB.Find(&cities)
-	} else {
-		co.DB.Where("country_id = ?", c.Param("county_id")).Find(&cities)
-	}
-
-	co.SetBody(cities)
-	return
+	co.CachedJSON(c, "city.active:"+countryID, []string{"ref_cities", "med_translations"}, func() (interface{}, error) {
+		var cities []databases.RefCity
+		db := co.DB
+		if countryID != "" {
+			db = db.Where("country_id = ?", countryID)
+		}
+		if err := db.Find(&cities).Error; err != nil {
+			return nil, err
+		}
+		co.Localize(c, &cities)
+		return cities, nil
+	})
 }
 
 // Get city
@@ -132,6 +128,7 @@ func (co CityController) ListActive(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path uint true "city ID"
+// @Param lang query string false "locale, Accept-Language when empty"
 // @Success 200 {object} structs.ResponseBody{body=databases.RefCity}
 // @Failure 400 {object} structs.ErrorResponse
 // @Failure 500 {object} structs.ErrorResponse
@@ -148,6 +145,7 @@ func (co CityController) Get(c *gin.Context) {
 		return
 	}
 
+	co.Localize(c, &city)
 	co.SetBody(city)
 	return
 }
@@ -281,91 +279,90 @@ func (co CityController) Delete(c *gin.Context) {
 	return
 }
 
-// InitDB classification
-// @Summary Delete classification
-// @Description Remove classification
-// @Tags Classification
+// InitDB city
+// @Summary InitDB city
+// @Description Import files/region.json, same as /city/region/import without a file
+// @Tags City
 // @Accept json
 // @Produce json
-// @Success 200 {object} structs.ResponseBody{body=structs.SuccessResponse}
+// @Success 200 {object} structs.ResponseBody{body=services.RegionImportReport}
 // @Failure 400 {object} structs.ErrorResponse
 // @Failure 500 {object} structs.ErrorResponse
-// @Router /initdb [get]
+// @Router /city/initdb [get]
 func (co CityController) InitDB(c *gin.Context) {
 	defer func() {
 		c.JSON(co.GetBody())
 	}()
 
-	file, _ := ioutil.ReadFile("files/region.json")
-	authUser := co.GetAuth(c)
+	file, err := os.Open(regionFile)
+	if err != nil {
+		co.SetError(http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer file.Close()
 
-	var data []RegionStruct
-
-	_ = json.Unmarshal([]byte(file), &data)
-	for _, region := range data {
-		if region.RegionTypeID == 39 {
-			// aiamg = hot
-			city := databases.RefCity{
-				Name:         region.RegionName,
-				CreatedUser:  &authUser,
-				ModifiedUser: &authUser,
-				CountryID:    67,
-				Base: databases.Base{
-					ID:          uint(region.ID),
-					CreatedDate: time.Now(),
-				},
-			}
-
-			result := co.DB.Create(&city)
-			if result.Error != nil {
-				co.SetError(http.StatusInternalServerError, result.Error.Error())
-				return
-			}
-		}
+	co.importRegions(c, file, false)
+	return
+}
 
-		if region.RegionTypeID == 58 {
-			// aiamg = hot
-			district := databases.RefDistrict{
-				Name:         region.RegionName,
-				CreatedUser:  &authUser,
-				ModifiedUser: &authUser,
-				CityID:       uint(region.ParentID),
-				Base: databases.Base{
-					ID:          uint(region.ID),
-					CreatedDate: time.Now(),
-				},
-			}
-
-			result := co.DB.Create(&district)
-			if result.Error != nil {
-				co.SetError(http.StatusInternalServerError, result.Error.Error())
-				return
-			}
-		}
+// ImportRegions city
+// @Summary ImportRegions city
+// @Description Upsert cities, districts and streets from region.json by external ID, keeping sort order.
+// @Description Reports renamed regions and previously imported regions missing from the file; those are not deleted.
+// @Description Without a file files/region.json is used.
+// @Tags City
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file false "region.json"
+// @Param dry_run formData bool false "report only"
+// @Success 200 {object} structs.ResponseBody{body=services.RegionImportReport}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /city/region/import [post]
+func (co CityController) ImportRegions(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
 
-		if region.RegionTypeID == 102 {
-			// aiamg = hot
-			street := databases.RefStreet{
-				Name:         region.RegionName,
-				CreatedUser:  &authUser,
-				ModifiedUser: &authUser,
-				DistrictID:   uint(region.ParentID),
-				Base: databases.Base{
-					ID:          uint(region.ID),
-					CreatedDate: time.Now(),
-				},
-			}
-
-			result := co.DB.Create(&street)
-			if result.Error != nil {
-				co.SetError(http.StatusInternalServerError, result.Error.Error())
-				return
-			}
-		}
+	var reader io.ReadCloser
+	fileHeader, err := c.FormFile("file")
+	if err == nil {
+		reader, err = fileHeader.Open()
+	} else {
+		reader, err = os.Open(regionFile)
 	}
+	if err != nil {
+		co.SetError(http.StatusBadRequest, err.Error())
+		return
+	}
+	defer reader.Close()
 
-	co.SetBody(structs.SuccessResponse{
-		Success: true,
-	})
+	co.importRegions(c, reader, c.PostForm("dry_run") == "true")
 	return
 }
+
+func (co CityController) importRegions(c *gin.Context, reader io.Reader, dryRun bool) {
+	regions, err := services.ReadRegions(reader)
+	if err != nil {
+		co.SetError(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	authUser := co.GetAuth(c)
+	tx := co.DB.Begin()
+	report, err := services.ImportRegions(tx, regions, services.MongoliaCountryID(), authUser.Base.ID)
+	if err != nil {
+		tx.Rollback()
+		co.SetError(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if dryRun {
+		tx.Rollback()
+	} else if result := tx.Commit(); result.Error != nil {
+		co.SetError(http.StatusInternalServerError, result.Error.Error())
+		return
+	}
+
+	co.SetBody(report)
+}
@@ -12,6 +12,7 @@ import (
 	"gitlab.com/fibocloud/medtech/gin/controllers/shared"
 	databases "gitlab.com/fibocloud/medtech/gin/databases"
 	form "gitlab.com/fibocloud/medtech/gin/form"
+	services "gitlab.com/fibocloud/medtech/gin/services"
 	structs "gitlab.com/fibocloud/medtech/gin/structs"
 )
 
@@ -44,6 +45,7 @@ func (co CountryController) Init(router *gin.RouterGroup) {
 // @Accept json
 // @Produce json
 // @Param filter body form.CountryFilter true "filter"
+// @Param lang query string false "locale, Accept-Language when empty"
 // @Success 200 {object} structs.ResponseBody{body=[]databases.RefCountry}
 // @Failure 400 {object} structs.ErrorResponse
 // @Failure 500 {object} structs.ErrorResponse
@@ -73,6 +75,7 @@ func (co CountryController) List(c *gin.Context) {
 
 	var countries []databases.RefCountry
 	db.Find(&countries)
+	co.Localize(c, &countries)
 
 	db.Table("ref_countries").Count(&count)
 
@@ -90,19 +93,23 @@ func (co CountryController) List(c *gin.Context) {
 // @Tags City
 // @Accept json
 // @Produce json
+// @Param lang query string false "locale, Accept-Language when empty"
+// @Param If-None-Match header string false "өмнөх ETag"
 // @Success 200 {object} structs.ResponseBody{body=[]databases.RefCountry}
+// @Header 200 {string} ETag "лавлахын хувилбар"
+// @Success 304 "өөрчлөгдөөгүй"
 // @Failure 400 {object} structs.ErrorResponse
 // @Failure 500 {object} structs.ErrorResponse
 // @Router /country/list/active [get]
 func (co CountryController) ListActive(c *gin.Context) {
-	defer func() {
-		c.JSON(co.GetBody())
-	}()
-
-	var counties []databases.RefCountry
-	co.DB.Find(&counties)
-	co.SetBody(counties)
-	return
+	co.CachedJSON(c, "country.active", []string{"ref_countries", "med_translations"}, func() (interface{}, error) {
+		var counties []databases.RefCountry
+		if err := co.DB.Find(&counties).Error; err != nil {
+			return nil, err
+		}
+		co.Localize(c, &counties)
+		return counties, nil
+	})
 }
 
 // Get country
@@ -112,6 +119,7 @@ func (co CountryController) ListActive(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path uint true "country ID"
+// @Param lang query string false "locale, Accept-Language when empty"
 // @Success 200 {object} structs.ResponseBody{body=databases.RefCountry}
 // @Failure 400 {object} structs.ErrorResponse
 // @Failure 500 {object} structs.ErrorResponse
@@ -128,6 +136,7 @@ func (co CountryController) Get(c *gin.Context) {
 		return
 	}
 
+	co.Localize(c, &country)
 	co.SetBody(country)
 	return
 }
@@ -154,9 +163,15 @@ func (co CountryController) Create(c *gin.Context) {
 		return
 	}
 
+	if err := services.CheckRefCode(co.DB, services.TableCountries, 0, params.Code); err != nil {
+		co.SetError(http.StatusBadRequest, err.Error())
+		return
+	}
+
 	authUser := co.GetAuth(c)
 	country := databases.RefCountry{
 		Name:         params.Name,
+		Code:         params.Code,
 		CreatedUser:  &authUser,
 		ModifiedUser: &authUser,
 		Base: databases.Base{
@@ -206,9 +221,15 @@ func (co CountryController) Update(c *gin.Context) {
 		return
 	}
 
+	if err := services.CheckRefCode(co.DB, services.TableCountries, country.Base.ID, params.Code); err != nil {
+		co.SetError(http.StatusBadRequest, err.Error())
+		return
+	}
+
 	authUser := co.GetAuth(c)
 
 	country.Name = params.Name
+	country.Code = params.Code
 	country.Base.ModifiedDate = time.Now()
 	country.ModifiedUser = &authUser
 
@@ -246,6 +267,13 @@ func (co CountryController) Delete(c *gin.Context) {
 		return
 	}
 
+	for _, v := range params.IDs {
+		if services.RegisteredRef(services.TableCountries, v) {
+			co.SetError(http.StatusBadRequest, services.ErrRefCodeRequired.Error())
+			return
+		}
+	}
+
 	for _, v := range params.IDs {
 		result := co.DB.Delete(&databases.RefCountry{}, v)
 		if result.Error != nil {
@@ -44,6 +44,7 @@ func (co DistrictController) Init(router *gin.RouterGroup) {
 // @Accept json
 // @Produce json
 // @Param filter body form.DistrictFilter true "filter"
+// @Param lang query string false "locale, Accept-Language when empty"
 // @Success 200 {object} structs.ResponseBody{body=[]databases.RefDistrict}
 // @Failure 400 {object} structs.ErrorResponse
 // @Failure 500 {object} structs.ErrorResponse
@@ -73,6 +74,7 @@ func (co DistrictController) List(c *gin.Context) {
 
 	var countries []databases.RefDistrict
 	db.Preload("City").Preload("City.Country").Find(&countries)
+	co.Localize(c, &countries)
 
 	co.DB.Table("ref_districts").Count(&count)
 
@@ -90,24 +92,29 @@ func (co DistrictController) List(c *gin.Context) {
 // @Tags City
 // @Accept json
 // @Produce json
+// @Param lang query string false "locale, Accept-Language when empty"
+// @Param If-None-Match header string false "өмнөх ETag"
 // @Success 200 {object} structs.ResponseBody{body=[]databases.RefDistrict}
+// @Header 200 {string} ETag "лавлахын хувилбар"
+// @Success 304 "өөрчлөгдөөгүй"
 // @Failure 400 {object} structs.ErrorResponse
 // @Failure 500 {object} structs.ErrorResponse
 // @Router /districts/list/active/{city_id} [get]
 func (co DistrictController) ListActive(c *gin.Context) {
-	defer func() {
-		c.JSON(co.GetBody())
-	}()
-
-	var counties []databases.RefDistrict
-	if c.Param("city_id") == "" {
-		co.DB.Find(&counties)
-	} else {
-		co.DB.Where("city_id = ?", c.Param("city_id")).Find(&counties)
-	}
+	cityID := c.Param("city_id")
 
-	co.SetBody(counties)
-	return
+	co.CachedJSON(c, "district.active:"+cityID, []string{"ref_districts", "med_translations"}, func() (interface{}, error) {
+		var counties []databases.RefDistrict
+		db := co.DB
+		if cityID != "" {
+			db = db.Where("city_id = ?", cityID)
+		}
+		if err := db.Find(&counties).Error; err != nil {
+			return nil, err
+		}
+		co.Localize(c, &counties)
+		return counties, nil
+	})
 }
 
 // Get districts
@@ -117,6 +124,7 @@ func (co DistrictController) ListActive(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path uint true "districts ID"
+// @Param lang query string false "locale, Accept-Language when empty"
 // @Success 200 {object} structs.ResponseBody{body=databases.RefDistrict}
 // @Failure 400 {object} structs.ErrorResponse
 // @Failure 500 {object} structs.ErrorResponse
@@ -133,6 +141,7 @@ func (co DistrictController) Get(c *gin.Context) {
 		return
 	}
 
+	co.Localize(c, &districts)
 	co.SetBody(districts)
 	return
 }
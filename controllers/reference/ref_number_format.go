@@ -0,0 +1,257 @@
+package reference
+
+import (
+	"net/http"
+	"reflect"
+	"time"
+
+	gin "github.com/gin-gonic/gin"
+	"gitlab.com/fibocloud/medtech/gin/controllers/shared"
+	databases "gitlab.com/fibocloud/medtech/gin/databases"
+	form "gitlab.com/fibocloud/medtech/gin/form"
+	"gitlab.com/fibocloud/medtech/gin/services"
+	structs "gitlab.com/fibocloud/medtech/gin/structs"
+)
+
+// NumberFormatController struct
+type NumberFormatController struct {
+	shared.BaseController
+}
+
+// ListNumberFormat ...
+type ListNumberFormat struct {
+	Total int64                       `json:"total"`
+	List  []databases.MedNumberFormat `json:"list"`
+}
+
+// NumberPreview ...
+type NumberPreview struct {
+	Code   string `json:"code"`
+	Number string `json:"number"`
+}
+
+// Init Controller
+func (co NumberFormatController) Init(router *gin.RouterGroup) {
+	router.POST("/list", co.List)           // List
+	router.GET("get/:id", co.Get)           // Show
+	router.GET("preview/:code", co.Preview) // Preview
+	router.POST("", co.Create)              // Create
+	router.PUT("/:id", co.Update)           // Update
+}
+
+// List numberFormat
+// @Summary List numberFormat
+// @Description Get numberFormat
+// @Tags NumberFormat
+// @Accept json
+// @Produce json
+// @Param filter body form.NumberFormatFilter true "filter"
+// @Success 200 {object} structs.ResponseBody{body=ListNumberFormat}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /numberFormat/list [post]
+func (co NumberFormatController) List(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	var count int64
+	var params form.NumberFormatFilter
+	if err := c.ShouldBindJSON(&params); err != nil {
+		co.SetError(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	db := co.DB
+
+	// filter hiij bgaa heseg
+	v := reflect.ValueOf(params.Filter)
+
+	db = db.Scopes(shared.TableSearch(v, params.Sort))
+	db = db.Scopes(shared.Paginate(params.Page, params.Size))
+
+	var listRepsonse ListNumberFormat
+
+	var formats []databases.MedNumberFormat
+	db.Find(&formats)
+
+	db.Table("med_number_formats").Count(&count)
+
+	listRepsonse.List = formats
+	listRepsonse.Total = count
+
+	co.SetBody(listRepsonse)
+	return
+}
+
+// Get numberFormat
+// @Summary Get numberFormat
+// @Description Show numberFormat
+// @Tags NumberFormat
+// @Accept json
+// @Produce json
+// @Param id path uint true "numberFormat ID"
+// @Success 200 {object} structs.ResponseBody{body=databases.MedNumberFormat}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /numberFormat/get/{id} [get]
+func (co NumberFormatController) Get(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	var format databases.MedNumberFormat
+	result := co.DB.First(&format, c.Param("id"))
+	if result.Error != nil {
+		co.SetError(http.StatusInternalServerError, result.Error.Error())
+		return
+	}
+
+	co.SetBody(format)
+	return
+}
+
+// Preview numberFormat
+// @Summary Preview numberFormat
+// @Description Show the next number without reserving it
+// @Tags NumberFormat
+// @Accept json
+// @Produce json
+// @Param code path string true "document type code"
+// @Success 200 {object} structs.ResponseBody{body=NumberPreview}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /numberFormat/preview/{code} [get]
+func (co NumberFormatController) Preview(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	number, err := services.PreviewNumber(co.DB, c.Param("code"), time.Now())
+	if err != nil {
+		co.SetError(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	co.SetBody(NumberPreview{
+		Code:   c.Param("code"),
+		Number: number,
+	})
+	return
+}
+
+// Create numberFormat
+// @Summary Create numberFormat
+// @Description Add numberFormat. date_pattern must contain the year for yearly reset, year and MM for monthly and year, MM and DD for daily.
+// @Tags NumberFormat
+// @Accept json
+// @Produce json
+// @Param numberFormat body form.NumberFormatParams true "numberFormat"
+// @Success 200 {object} structs.ResponseBody{body=structs.SuccessResponse}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /numberFormat [post]
+func (co NumberFormatController) Create(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	var params form.NumberFormatParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		co.SetError(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	authUser := co.GetAuth(c)
+	format := databases.MedNumberFormat{
+		Code:         params.Code,
+		Name:         params.Name,
+		Prefix:       params.Prefix,
+		DatePattern:  params.DatePattern,
+		Separator:    params.Separator,
+		Padding:      params.Padding,
+		ResetPeriod:  params.ResetPeriod,
+		IsActive:     params.IsActive,
+		CreatedUser:  &authUser,
+		ModifiedUser: &authUser,
+		Base: databases.Base{
+			CreatedDate: time.Now(),
+		},
+	}
+
+	if err := services.ValidateNumberFormat(format); err != nil {
+		co.SetError(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result := co.DB.Create(&format)
+	if result.Error != nil {
+		co.SetError(http.StatusInternalServerError, result.Error.Error())
+		return
+	}
+
+	co.SetBody(structs.SuccessResponse{
+		Success: true,
+	})
+	return
+}
+
+// Update numberFormat
+// @Summary Update numberFormat
+// @Description Edit numberFormat. Code is not changed so issued sequences stay linked.
+// @Description date_pattern must contain the date parts of reset_period, as on create.
+// @Tags NumberFormat
+// @Accept json
+// @Produce json
+// @Param id path uint true "numberFormat ID"
+// @Param numberFormat body form.NumberFormatParams true "numberFormat"
+// @Success 200 {object} structs.ResponseBody{body=structs.SuccessResponse}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /numberFormat/{id} [put]
+func (co NumberFormatController) Update(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	var params form.NumberFormatParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		co.SetError(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var format databases.MedNumberFormat
+	result := co.DB.First(&format, c.Param("id"))
+	if result.Error != nil {
+		co.SetError(http.StatusInternalServerError, result.Error.Error())
+		return
+	}
+
+	authUser := co.GetAuth(c)
+
+	format.Name = params.Name
+	format.Prefix = params.Prefix
+	format.DatePattern = params.DatePattern
+	format.Separator = params.Separator
+	format.Padding = params.Padding
+	format.ResetPeriod = params.ResetPeriod
+	format.IsActive = params.IsActive
+	format.Base.ModifiedDate = time.Now()
+	format.ModifiedUser = &authUser
+
+	if err := services.ValidateNumberFormat(format); err != nil {
+		co.SetError(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result = co.DB.Save(&format)
+	if result.Error != nil {
+		co.SetError(http.StatusInternalServerError, result.Error.Error())
+		return
+	}
+
+	co.SetBody(structs.SuccessResponse{
+		Success: true,
+	})
+	return
+}
@@ -39,6 +39,7 @@ func (co PaymentMethodController) Init(router *gin.RouterGroup) {
 // @Accept json
 // @Produce json
 // @Param filter body form.PaymentTypeFilter true "filter"
+// @Param lang query string false "locale, Accept-Language when empty"
 // @Success 200 {object} structs.ResponseBody{body=[]databases.MedPaymentMethod}
 // @Failure 400 {object} structs.ErrorResponse
 // @Failure 500 {object} structs.ErrorResponse
@@ -67,6 +68,7 @@ func (co PaymentMethodController) List(c *gin.Context) {
 
 	var paymentMethod []databases.MedPaymentMethod
 	db.Find(&paymentMethod)
+	co.Localize(c, &paymentMethod)
 
 	db.Table("med_payment_methods").Count(&count)
 
@@ -85,6 +87,7 @@ func (co PaymentMethodController) List(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path uint true "paymentMethod ID"
+// @Param lang query string false "locale, Accept-Language when empty"
 // @Success 200 {object} structs.ResponseBody{body=databases.MedPaymentMethod}
 // @Failure 400 {object} structs.ErrorResponse
 // @Failure 500 {object} structs.ErrorResponse
@@ -101,6 +104,7 @@ func (co PaymentMethodController) Get(c *gin.Context) {
 		return
 	}
 
+	co.Localize(c, &paymentMethod)
 	co.SetBody(paymentMethod)
 	return
 }
@@ -0,0 +1,491 @@
+package reference
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"strconv"
+	"time"
+
+	gin "github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"gitlab.com/fibocloud/medtech/gin/controllers/shared"
+	databases "gitlab.com/fibocloud/medtech/gin/databases"
+	form "gitlab.com/fibocloud/medtech/gin/form"
+	services "gitlab.com/fibocloud/medtech/gin/services"
+	structs "gitlab.com/fibocloud/medtech/gin/structs"
+	gorm "gorm.io/gorm"
+)
+
+// PaymentTermController struct
+type PaymentTermController struct {
+	shared.BaseController
+}
+
+// ListPaymentTerm ...
+type ListPaymentTerm struct {
+	Total int64                      `json:"total"`
+	List  []databases.MedPaymentTerm `json:"list"`
+}
+
+// Init Controller
+func (co PaymentTermController) Init(router *gin.RouterGroup) {
+	router.POST("/list", co.List)                     // List
+	router.GET("get/:id", co.Get)                     // Show
+	router.POST("", co.Create)                        // Create
+	router.PUT("/:id", co.Update)                     // Update
+	router.DELETE("", co.Delete)                      // Delete
+	router.GET("/preview", co.Preview)                // Preview
+	router.GET("/schedule/:outcome_id", co.Schedule)  // Schedule
+	router.POST("/schedule/:outcome_id", co.Generate) // Generate
+	router.GET("/overdue", co.Overdue)                // Overdue
+}
+
+// List paymentTerm
+// @Summary List paymentTerm
+// @Description Get paymentTerm
+// @Tags PaymentTerm
+// @Accept json
+// @Produce json
+// @Param filter body form.PaymentTermFilter true "filter"
+// @Success 200 {object} structs.ResponseBody{body=ListPaymentTerm}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /paymentTerm/list [post]
+func (co PaymentTermController) List(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	var count int64
+	var params form.PaymentTermFilter
+	if err := c.ShouldBindJSON(&params); err != nil {
+		co.SetError(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	db := co.DB
+
+	// filter hiij bgaa heseg
+	v := reflect.ValueOf(params.Filter)
+
+	db = db.Scopes(shared.TableSearch(v, params.Sort))
+	db = db.Scopes(shared.Paginate(params.Page, params.Size))
+
+	var paymentTerms []databases.MedPaymentTerm
+	result := db.Preload("PaymentType").Preload("Installments", func(db *gorm.DB) *gorm.DB {
+		return db.Order("seq")
+	}).Find(&paymentTerms)
+	if result.Error != nil {
+		co.SetError(http.StatusInternalServerError, result.Error.Error())
+		return
+	}
+
+	co.DB.Table("med_payment_terms").Count(&count)
+
+	co.SetBody(ListPaymentTerm{
+		Total: count,
+		List:  paymentTerms,
+	})
+	return
+}
+
+// Get paymentTerm
+// @Summary Get paymentTerm
+// @Description Show paymentTerm with its installments
+// @Tags PaymentTerm
+// @Accept json
+// @Produce json
+// @Param id path uint true "paymentTerm ID"
+// @Success 200 {object} structs.ResponseBody{body=databases.MedPaymentTerm}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 404 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /paymentTerm/get/{id} [get]
+func (co PaymentTermController) Get(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	var paymentTerm databases.MedPaymentTerm
+	result := co.DB.Preload("PaymentType").Preload("Installments", func(db *gorm.DB) *gorm.DB {
+		return db.Order("seq")
+	}).First(&paymentTerm, c.Param("id"))
+	if result.Error != nil {
+		co.setPaymentTermError(result.Error)
+		return
+	}
+
+	co.SetBody(paymentTerm)
+	return
+}
+
+// Create paymentTerm
+// @Summary Create paymentTerm
+// @Description Payment term of a payment type. Installment percents must add up to 100 and the last one is due on net_days.
+// @Description Without installments the whole amount is due on net_days. The discount applies to parts due after discount_days.
+// @Description The discount is shown on the schedule only; an installment is settled once its full amount is paid.
+// @Tags PaymentTerm
+// @Accept json
+// @Produce json
+// @Param paymentTerm body form.PaymentTermParams true "paymentTerm"
+// @Success 200 {object} structs.ResponseBody{body=structs.SuccessResponse}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 404 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /paymentTerm [post]
+func (co PaymentTermController) Create(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	var params form.PaymentTermParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		co.SetError(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	authUser := co.GetAuth(c)
+	now := time.Now()
+	paymentTerm := databases.MedPaymentTerm{
+		CreatedUserID:  authUser.Base.ID,
+		ModifiedUserID: authUser.Base.ID,
+		Base: databases.Base{
+			CreatedDate:  now,
+			ModifiedDate: now,
+		},
+	}
+	setPaymentTerm(&paymentTerm, params)
+
+	tx := co.DB.Begin()
+	if err := services.SavePaymentTerm(tx, &paymentTerm); err != nil {
+		tx.Rollback()
+		co.setPaymentTermError(err)
+		return
+	}
+	if err := tx.Commit().Error; err != nil {
+		co.SetError(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	co.SetBody(structs.SuccessResponse{
+		Success: true,
+	})
+	return
+}
+
+// Update paymentTerm
+// @Summary Update paymentTerm
+// @Description Edit paymentTerm, installments are replaced. Existing outcome schedules are kept until regenerated.
+// @Tags PaymentTerm
+// @Accept json
+// @Produce json
+// @Param id path uint true "paymentTerm ID"
+// @Param paymentTerm body form.PaymentTermParams true "paymentTerm"
+// @Success 200 {object} structs.ResponseBody{body=structs.SuccessResponse}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 404 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /paymentTerm/{id} [put]
+func (co PaymentTermController) Update(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	var params form.PaymentTermParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		co.SetError(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var paymentTerm databases.MedPaymentTerm
+	result := co.DB.First(&paymentTerm, c.Param("id"))
+	if result.Error != nil {
+		co.setPaymentTermError(result.Error)
+		return
+	}
+
+	authUser := co.GetAuth(c)
+	paymentTerm.ModifiedUserID = authUser.Base.ID
+	paymentTerm.Base.ModifiedDate = time.Now()
+	setPaymentTerm(&paymentTerm, params)
+
+	tx := co.DB.Begin()
+	if err := services.SavePaymentTerm(tx, &paymentTerm); err != nil {
+		tx.Rollback()
+		co.setPaymentTermError(err)
+		return
+	}
+	if err := tx.Commit().Error; err != nil {
+		co.SetError(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	co.SetBody(structs.SuccessResponse{
+		Success: true,
+	})
+	return
+}
+
+// Delete paymentTerm
+// @Summary Delete paymentTerm
+// @Description Remove paymentTerm, the payment type falls back to its payment_day and prepaid_percent
+// @Tags PaymentTerm
+// @Accept json
+// @Produce json
+// @Param paymentTerm body form.DeleteParams true "paymentTerm"
+// @Success 200 {object} structs.ResponseBody{body=structs.SuccessResponse}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /paymentTerm [delete]
+func (co PaymentTermController) Delete(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	var params form.DeleteParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		co.SetError(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	tx := co.DB.Begin()
+	for _, v := range params.IDs {
+		result := tx.Where("payment_term_id = ?", v).Delete(&databases.MedPaymentTermInstallment{})
+		if result.Error != nil {
+			tx.Rollback()
+			co.SetError(http.StatusInternalServerError, result.Error.Error())
+			return
+		}
+
+		result = tx.Delete(&databases.MedPaymentTerm{}, v)
+		if result.Error != nil {
+			tx.Rollback()
+			co.SetError(http.StatusInternalServerError, result.Error.Error())
+			return
+		}
+	}
+	if err := tx.Commit().Error; err != nil {
+		co.SetError(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	co.SetBody(structs.SuccessResponse{
+		Success: true,
+	})
+	return
+}
+
+// Preview paymentTerm
+// @Summary Preview paymentTerm
+// @Description Schedule an outcome of the customer would get from the customer's payment type, without saving it.
+// @Description due_date is the order due date.
+// @Tags PaymentTerm
+// @Accept json
+// @Produce json
+// @Param customer_id query uint true "customer ID"
+// @Param amount query number false "outcome total"
+// @Param date query string false "outcome date (2006-01-02), today by default"
+// @Success 200 {object} structs.ResponseBody{body=services.PaymentSchedule}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 404 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /paymentTerm/preview [get]
+func (co PaymentTermController) Preview(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	customerID, err := strconv.Atoi(c.Query("customer_id"))
+	if err != nil {
+		co.SetError(http.StatusBadRequest, "customer_id буруу байна")
+		return
+	}
+
+	amount := decimal.Zero
+	if c.Query("amount") != "" {
+		amount, err = decimal.NewFromString(c.Query("amount"))
+		if err != nil {
+			co.SetError(http.StatusBadRequest, "amount буруу байна")
+			return
+		}
+	}
+
+	date, ok := co.queryDate(c, "date")
+	if !ok {
+		return
+	}
+
+	schedule, err := services.PreviewSchedule(co.DB, uint(customerID), date, amount)
+	if err != nil {
+		co.setPaymentTermError(err)
+		return
+	}
+
+	co.SetBody(schedule)
+	return
+}
+
+// Schedule paymentTerm
+// @Summary Schedule paymentTerm
+// @Description Installments of an outcome with paid amounts, after matching the customer's payments
+// @Tags PaymentTerm
+// @Accept json
+// @Produce json
+// @Param outcome_id path uint true "outcome ID"
+// @Success 200 {object} structs.ResponseBody{body=services.PaymentSchedule}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 404 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /paymentTerm/schedule/{outcome_id} [get]
+func (co PaymentTermController) Schedule(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	outcomeID, err := strconv.Atoi(c.Param("outcome_id"))
+	if err != nil {
+		co.SetError(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	schedule, err := services.OutcomeSchedule(co.DB, uint(outcomeID))
+	if err != nil {
+		co.setPaymentTermError(err)
+		return
+	}
+
+	co.SetBody(schedule)
+	return
+}
+
+// Generate paymentTerm
+// @Summary Generate paymentTerm
+// @Description Replaces the schedule of an outcome with one from the customer's current payment type
+// @Tags PaymentTerm
+// @Accept json
+// @Produce json
+// @Param outcome_id path uint true "outcome ID"
+// @Success 200 {object} structs.ResponseBody{body=services.PaymentSchedule}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 404 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /paymentTerm/schedule/{outcome_id} [post]
+func (co PaymentTermController) Generate(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	outcomeID, err := strconv.Atoi(c.Param("outcome_id"))
+	if err != nil {
+		co.SetError(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	tx := co.DB.Begin()
+	schedule, err := services.GenerateOutcomeSchedule(tx, uint(outcomeID))
+	if err != nil {
+		tx.Rollback()
+		co.setPaymentTermError(err)
+		return
+	}
+	if err := tx.Commit().Error; err != nil {
+		co.SetError(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	co.SetBody(schedule)
+	return
+}
+
+// Overdue paymentTerm
+// @Summary Overdue paymentTerm
+// @Description Unpaid installments due before as_of, oldest first
+// @Tags PaymentTerm
+// @Accept json
+// @Produce json
+// @Param as_of query string false "2006-01-02, today by default"
+// @Param customer_id query uint false "customer ID"
+// @Success 200 {object} structs.ResponseBody{body=[]services.OverdueItem}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /paymentTerm/overdue [get]
+func (co PaymentTermController) Overdue(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	asOf, ok := co.queryDate(c, "as_of")
+	if !ok {
+		return
+	}
+
+	filter := services.OverdueFilter{AsOf: asOf}
+	if c.Query("customer_id") != "" {
+		customerID, err := strconv.Atoi(c.Query("customer_id"))
+		if err != nil {
+			co.SetError(http.StatusBadRequest, "customer_id буруу байна")
+			return
+		}
+		filter.CustomerID = uint(customerID)
+	}
+
+	items, err := services.OverdueInstallments(co.DB, filter)
+	if err != nil {
+		co.setPaymentTermError(err)
+		return
+	}
+
+	co.SetBody(items)
+	return
+}
+
+func setPaymentTerm(paymentTerm *databases.MedPaymentTerm, params form.PaymentTermParams) {
+	paymentTerm.PaymentTypeID = params.PaymentTypeID
+	paymentTerm.NetDays = params.NetDays
+	paymentTerm.DiscountPercent = params.DiscountPercent
+	paymentTerm.DiscountDays = params.DiscountDays
+	paymentTerm.Description = params.Description
+	paymentTerm.IsActive = params.IsActive
+
+	paymentTerm.Installments = []*databases.MedPaymentTermInstallment{}
+	for _, installment := range params.Installments {
+		paymentTerm.Installments = append(paymentTerm.Installments, &databases.MedPaymentTermInstallment{
+			Percent: installment.Percent,
+			DueDays: installment.DueDays,
+		})
+	}
+}
+
+func (co PaymentTermController) queryDate(c *gin.Context, key string) (time.Time, bool) {
+	now := time.Now()
+	if c.Query(key) == "" {
+		return now, true
+	}
+
+	date, err := time.ParseInLocation("2006-01-02", c.Query(key), now.Location())
+	if err != nil {
+		co.SetError(http.StatusBadRequest, key+" буруу байна")
+		return now, false
+	}
+	return date, true
+}
+
+// setPaymentTermError нөхцөл, хуваарийн алдааг http код руу хөрвүүлнэ
+func (co PaymentTermController) setPaymentTermError(err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		co.SetError(http.StatusNotFound, "Бичлэг олдсонгүй")
+	case errors.Is(err, services.ErrOutcomeNotFound),
+		errors.Is(err, services.ErrScheduleNotFound):
+		co.SetError(http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrTermPercent),
+		errors.Is(err, services.ErrTermDueDays),
+		errors.Is(err, services.ErrTermDiscount),
+		errors.Is(err, services.ErrTermTaken),
+		errors.Is(err, services.ErrPaymentTypeMissing):
+		co.SetError(http.StatusBadRequest, err.Error())
+	default:
+		co.SetError(http.StatusInternalServerError, err.Error())
+	}
+}
@@ -39,6 +39,7 @@ func (co PaymentTypeController) Init(router *gin.RouterGroup) {
 // @Accept json
 // @Produce json
 // @Param filter body form.PaymentTypeFilter true "filter"
+// @Param lang query string false "locale, Accept-Language when empty"
 // @Success 200 {object} structs.ResponseBody{body=[]databases.MedPaymentType}
 // @Failure 400 {object} structs.ErrorResponse
 // @Failure 500 {object} structs.ErrorResponse
@@ -67,6 +68,7 @@ func (co PaymentTypeController) List(c *gin.Context) {
 
 	var paymentType []databases.MedPaymentType
 	db.Find(&paymentType)
+	co.Localize(c, &paymentType)
 
 	db.Table("med_payment_types").Count(&count)
 
@@ -85,6 +87,7 @@ func (co PaymentTypeController) List(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path uint true "paymentType ID"
+// @Param lang query string false "locale, Accept-Language when empty"
 // @Success 200 {object} structs.ResponseBody{body=databases.MedPaymentType}
 // @Failure 400 {object} structs.ErrorResponse
 // @Failure 500 {object} structs.ErrorResponse
@@ -101,6 +104,7 @@ func (co PaymentTypeController) Get(c *gin.Context) {
 		return
 	}
 
+	co.Localize(c, &paymentType)
 	co.SetBody(paymentType)
 	return
 }
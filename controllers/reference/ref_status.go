@@ -9,6 +9,7 @@ import (
 	"gitlab.com/fibocloud/medtech/gin/controllers/shared"
 	databases "gitlab.com/fibocloud/medtech/gin/databases"
 	form "gitlab.com/fibocloud/medtech/gin/form"
+	services "gitlab.com/fibocloud/medtech/gin/services"
 	structs "gitlab.com/fibocloud/medtech/gin/structs"
 )
 
@@ -39,6 +40,7 @@ func (co StatusController) Init(router *gin.RouterGroup) {
 // @Accept json
 // @Produce json
 // @Param filter body form.StatusFilter true "filter"
+// @Param lang query string false "locale, Accept-Language when empty"
 // @Success 200 {object} structs.ResponseBody{body=[]databases.MedStatus}
 // @Failure 400 {object} structs.ErrorResponse
 // @Failure 500 {object} structs.ErrorResponse
@@ -67,6 +69,7 @@ func (co StatusController) List(c *gin.Context) {
 
 	var status []databases.MedStatus
 	db.Preload("StatusType").Find(&status)
+	co.Localize(c, &status)
 	db.Table("med_statuses").Count(&count)
 
 	listRepsonse.List = status
@@ -84,6 +87,7 @@ func (co StatusController) List(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path uint true "status ID"
+// @Param lang query string false "locale, Accept-Language when empty"
 // @Success 200 {object} structs.ResponseBody{body=databases.MedStatus}
 // @Failure 400 {object} structs.ErrorResponse
 // @Failure 500 {object} structs.ErrorResponse
@@ -100,6 +104,7 @@ func (co StatusController) Get(c *gin.Context) {
 		return
 	}
 
+	co.Localize(c, &status)
 	co.SetBody(status)
 	return
 }
@@ -188,6 +193,17 @@ func (co StatusController) Update(c *gin.Context) {
 	}
 
 	authUser := co.GetAuth(c)
+	tx := co.DB.Begin()
+
+	// Өөр төрөлд шилжсэн төлөв хуучин төрлийн графаас гарна
+	if status.StatusTypeID != uint(params.TypeID) {
+		result = tx.Where("status_id = ?", status.Base.ID).Delete(&databases.MedStatusNode{})
+		if result.Error != nil {
+			tx.Rollback()
+			co.SetError(http.StatusInternalServerError, result.Error.Error())
+			return
+		}
+	}
 
 	status.Name = params.Name
 	status.Description = params.Description
@@ -197,12 +213,18 @@ func (co StatusController) Update(c *gin.Context) {
 	status.ModifiedUser = &authUser
 	status.StatusTypeID = uint(params.TypeID)
 
-	result = co.DB.Save(&status)
+	result = tx.Save(&status)
 	if result.Error != nil {
+		tx.Rollback()
 		co.SetError(http.StatusInternalServerError, result.Error.Error())
 		return
 	}
 
+	if err := tx.Commit().Error; err != nil {
+		co.SetError(http.StatusInternalServerError, err.Error())
+		return
+	}
+
 	co.SetBody(structs.SuccessResponse{
 		Success: true,
 	})
@@ -232,7 +254,20 @@ func (co StatusController) Delete(c *gin.Context) {
 	}
 
 	for _, v := range params.IDs {
-		result := co.DB.Delete(&databases.MedStatus{}, v)
+		if services.RegisteredRef(services.TableStatuses, v) {
+			co.SetError(http.StatusBadRequest, services.ErrRefCodeRequired.Error())
+			return
+		}
+	}
+
+	for _, v := range params.IDs {
+		result := co.DB.Where("status_id = ?", v).Delete(&databases.MedStatusNode{})
+		if result.Error != nil {
+			co.SetError(http.StatusInternalServerError, result.Error.Error())
+			return
+		}
+
+		result = co.DB.Delete(&databases.MedStatus{}, v)
 		if result.Error != nil {
 			co.SetError(http.StatusInternalServerError, result.Error.Error())
 			return
@@ -0,0 +1,142 @@
+package reference
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	gin "github.com/gin-gonic/gin"
+	"gitlab.com/fibocloud/medtech/gin/controllers/shared"
+	form "gitlab.com/fibocloud/medtech/gin/form"
+	services "gitlab.com/fibocloud/medtech/gin/services"
+	gorm "gorm.io/gorm"
+)
+
+// Graph statusType
+// @Summary Graph statusType
+// @Description Statuses of the type as nodes in display order with colours, and transitions between them as edges with role requirements.
+// @Description unreachable lists active statuses that no single document type can reach from the start status.
+// @Tags StatusType
+// @Accept json
+// @Produce json
+// @Param id path uint true "statusType ID"
+// @Param lang query string false "locale, Accept-Language when empty"
+// @Success 200 {object} structs.ResponseBody{body=services.StatusGraph}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 404 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /statusType/graph/{id} [get]
+func (co StatusTypeController) Graph(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	statusTypeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		co.SetError(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	graph, err := services.LoadStatusGraph(co.DB, uint(statusTypeID), shared.RequestLocales(c))
+	if err != nil {
+		co.setGraphError(err)
+		return
+	}
+
+	co.SetBody(graph)
+	return
+}
+
+// SaveGraph statusType
+// @Summary SaveGraph statusType
+// @Description Replaces the order, start status, colours and transitions of the type. Every active status must be listed,
+// @Description exactly one must be the start and all of them must be reachable from it through the active transitions of one document type.
+// @Description Transitions between the type's statuses that are not listed are deleted.
+// @Description Once a start status is set, documents of the type can only be started in it.
+// @Tags StatusType
+// @Accept json
+// @Produce json
+// @Param id path uint true "statusType ID"
+// @Param graph body form.StatusGraphParams true "graph"
+// @Success 200 {object} structs.ResponseBody{body=services.StatusGraph}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 404 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /statusType/graph/{id} [post]
+func (co StatusTypeController) SaveGraph(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	statusTypeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		co.SetError(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var params form.StatusGraphParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		co.SetError(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var input services.StatusGraphInput
+	for _, node := range params.Nodes {
+		input.Nodes = append(input.Nodes, services.StatusNodeInput{
+			StatusID:  node.StatusID,
+			Seq:       node.Seq,
+			IsStart:   node.IsStart,
+			ColorCode: node.ColorCode,
+		})
+	}
+	for _, edge := range params.Edges {
+		input.Edges = append(input.Edges, services.StatusEdgeInput{
+			DocumentType:   edge.DocumentType,
+			Name:           edge.Name,
+			FromStatusID:   edge.FromStatusID,
+			ToStatusID:     edge.ToStatusID,
+			RequireComment: edge.RequireComment,
+			RoleIDs:        edge.RoleIDs,
+			IsActive:       edge.IsActive,
+		})
+	}
+
+	authUser := co.GetAuth(c)
+	tx := co.DB.Begin()
+	if err := services.SaveStatusGraph(tx, uint(statusTypeID), input, authUser.Base.ID); err != nil {
+		tx.Rollback()
+		co.setGraphError(err)
+		return
+	}
+	if err := tx.Commit().Error; err != nil {
+		co.SetError(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	graph, err := services.LoadStatusGraph(co.DB, uint(statusTypeID), shared.RequestLocales(c))
+	if err != nil {
+		co.setGraphError(err)
+		return
+	}
+
+	co.SetBody(graph)
+	return
+}
+
+// setGraphError графын алдааг http код руу хөрвүүлнэ
+func (co StatusTypeController) setGraphError(err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		co.SetError(http.StatusNotFound, "Төлөвийн төрөл олдсонгүй")
+	case errors.Is(err, services.ErrGraphStatus),
+		errors.Is(err, services.ErrGraphNodes),
+		errors.Is(err, services.ErrGraphStart),
+		errors.Is(err, services.ErrGraphEdge),
+		errors.Is(err, services.ErrGraphRole),
+		errors.Is(err, services.ErrGraphUnreachable),
+		errors.Is(err, services.ErrUnknownDocument):
+		co.SetError(http.StatusBadRequest, err.Error())
+	default:
+		co.SetError(http.StatusInternalServerError, err.Error())
+	}
+}
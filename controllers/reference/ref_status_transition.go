@@ -0,0 +1,338 @@
+package reference
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"time"
+
+	gin "github.com/gin-gonic/gin"
+	"gitlab.com/fibocloud/medtech/gin/controllers/shared"
+	databases "gitlab.com/fibocloud/medtech/gin/databases"
+	form "gitlab.com/fibocloud/medtech/gin/form"
+	"gitlab.com/fibocloud/medtech/gin/services"
+	structs "gitlab.com/fibocloud/medtech/gin/structs"
+)
+
+// StatusTransitionController struct
+type StatusTransitionController struct {
+	shared.BaseController
+}
+
+// ListStatusTransition ...
+type ListStatusTransition struct {
+	Total int64                           `json:"total"`
+	List  []databases.MedStatusTransition `json:"list"`
+}
+
+// Init Controller
+func (co StatusTransitionController) Init(router *gin.RouterGroup) {
+	router.POST("/list", co.List)                           // List
+	router.GET("get/:id", co.Get)                           // Show
+	router.GET("available/:documentType/:id", co.Available) // Available
+	router.POST("/change", co.Change)                       // Change
+	router.POST("", co.Create)                              // Create
+	router.PUT("/:id", co.Update)                           // Update
+	router.DELETE("", co.Delete)                            // Delete
+}
+
+// List statusTransition
+// @Summary List statusTransition
+// @Description Get statusTransition
+// @Tags StatusTransition
+// @Accept json
+// @Produce json
+// @Param filter body form.StatusTransitionFilter true "filter"
+// @Success 200 {object} structs.ResponseBody{body=ListStatusTransition}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /statusTransition/list [post]
+func (co StatusTransitionController) List(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	var count int64
+	var params form.StatusTransitionFilter
+	if err := c.ShouldBindJSON(&params); err != nil {
+		co.SetError(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	db := co.DB
+
+	// filter hiij bgaa heseg
+	v := reflect.ValueOf(params.Filter)
+
+	db = db.Scopes(shared.TableSearch(v, params.Sort))
+	db = db.Scopes(shared.Paginate(params.Page, params.Size))
+
+	var listRepsonse ListStatusTransition
+
+	var transitions []databases.MedStatusTransition
+	db.Preload("FromStatus").Preload("ToStatus").Preload("Roles").Find(&transitions)
+
+	db.Table("med_status_transitions").Count(&count)
+
+	listRepsonse.List = transitions
+	listRepsonse.Total = count
+
+	co.SetBody(listRepsonse)
+	return
+}
+
+// Get statusTransition
+// @Summary Get statusTransition
+// @Description Show statusTransition
+// @Tags StatusTransition
+// @Accept json
+// @Produce json
+// @Param id path uint true "statusTransition ID"
+// @Success 200 {object} structs.ResponseBody{body=databases.MedStatusTransition}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /statusTransition/get/{id} [get]
+func (co StatusTransitionController) Get(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	var transition databases.MedStatusTransition
+	result := co.DB.Preload("FromStatus").Preload("ToStatus").Preload("Roles").First(&transition, c.Param("id"))
+	if result.Error != nil {
+		co.SetError(http.StatusInternalServerError, result.Error.Error())
+		return
+	}
+
+	co.SetBody(transition)
+	return
+}
+
+// Available statusTransition
+// @Summary Available statusTransition
+// @Description Transitions the current user may perform on the document from its current status
+// @Tags StatusTransition
+// @Accept json
+// @Produce json
+// @Param documentType path string true "customer, order_book, income, outcome"
+// @Param id path uint true "document ID"
+// @Success 200 {object} structs.ResponseBody{body=[]databases.MedStatusTransition}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /statusTransition/available/{documentType}/{id} [get]
+func (co StatusTransitionController) Available(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	recordID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		co.SetError(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	transitions, err := services.NewWorkflow(co.DB).Available(c.Param("documentType"), uint(recordID), co.GetAuth(c))
+	if err != nil {
+		co.SetWorkflowError(err)
+		return
+	}
+
+	co.SetBody(transitions)
+	return
+}
+
+// Change statusTransition
+// @Summary Change statusTransition
+// @Description Move a document to another status through the workflow
+// @Tags StatusTransition
+// @Accept json
+// @Produce json
+// @Param change body form.StatusChangeParams true "change"
+// @Success 200 {object} structs.ResponseBody{body=services.StatusChange}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 403 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /statusTransition/change [post]
+func (co StatusTransitionController) Change(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	var params form.StatusChangeParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		co.SetError(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	tx := co.DB.Begin()
+	change, err := services.NewWorkflow(tx).Transition(params.DocumentType, params.RecordID, params.StatusID, params.Description, co.GetAuth(c))
+	if err != nil {
+		tx.Rollback()
+		co.SetWorkflowError(err)
+		return
+	}
+
+	tx.Commit()
+	services.PublishStatusChange(change)
+	co.SetBody(change)
+	return
+}
+
+// Create statusTransition
+// @Summary Create statusTransition
+// @Description Add statusTransition
+// @Tags StatusTransition
+// @Accept json
+// @Produce json
+// @Param statusTransition body form.StatusTransitionParams true "statusTransition"
+// @Success 200 {object} structs.ResponseBody{body=structs.SuccessResponse}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /statusTransition [post]
+func (co StatusTransitionController) Create(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	var params form.StatusTransitionParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		co.SetError(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var roles []*databases.MedSystemRole
+	if len(params.RoleIDs) > 0 {
+		co.DB.Find(&roles, params.RoleIDs)
+	}
+
+	authUser := co.GetAuth(c)
+	transition := databases.MedStatusTransition{
+		DocumentType:   params.DocumentType,
+		Name:           params.Name,
+		FromStatusID:   params.FromStatusID,
+		ToStatusID:     params.ToStatusID,
+		RequireComment: params.RequireComment,
+		Roles:          roles,
+		IsActive:       params.IsActive,
+		CreatedUser:    &authUser,
+		ModifiedUser:   &authUser,
+		Base: databases.Base{
+			CreatedDate: time.Now(),
+		},
+	}
+
+	result := co.DB.Create(&transition)
+	if result.Error != nil {
+		co.SetError(http.StatusInternalServerError, result.Error.Error())
+		return
+	}
+
+	co.SetBody(structs.SuccessResponse{
+		Success: true,
+	})
+	return
+}
+
+// Update statusTransition
+// @Summary Update statusTransition
+// @Description Edit statusTransition
+// @Tags StatusTransition
+// @Accept json
+// @Produce json
+// @Param id path uint true "statusTransition ID"
+// @Param statusTransition body form.StatusTransitionParams true "statusTransition"
+// @Success 200 {object} structs.ResponseBody{body=structs.SuccessResponse}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /statusTransition/{id} [put]
+func (co StatusTransitionController) Update(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	var params form.StatusTransitionParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		co.SetError(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var transition databases.MedStatusTransition
+	result := co.DB.First(&transition, c.Param("id"))
+	if result.Error != nil {
+		co.SetError(http.StatusInternalServerError, result.Error.Error())
+		return
+	}
+
+	var roles []*databases.MedSystemRole
+	if len(params.RoleIDs) > 0 {
+		co.DB.Find(&roles, params.RoleIDs)
+	}
+
+	authUser := co.GetAuth(c)
+	tx := co.DB.Begin()
+
+	transition.DocumentType = params.DocumentType
+	transition.Name = params.Name
+	transition.FromStatusID = params.FromStatusID
+	transition.ToStatusID = params.ToStatusID
+	transition.RequireComment = params.RequireComment
+	transition.IsActive = params.IsActive
+	transition.Base.ModifiedDate = time.Now()
+	transition.ModifiedUser = &authUser
+
+	result = tx.Save(&transition)
+	if result.Error != nil {
+		tx.Rollback()
+		co.SetError(http.StatusInternalServerError, result.Error.Error())
+		return
+	}
+
+	err := tx.Model(&transition).Association("Roles").Replace(roles)
+	if err != nil {
+		tx.Rollback()
+		co.SetError(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	tx.Commit()
+	co.SetBody(structs.SuccessResponse{
+		Success: true,
+	})
+	return
+}
+
+// Delete statusTransition
+// @Summary Delete statusTransition
+// @Description Remove statusTransition
+// @Tags StatusTransition
+// @Accept json
+// @Produce json
+// @Param ids body form.DeleteParams true "ids"
+// @Success 200 {object} structs.ResponseBody{body=structs.SuccessResponse}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /statusTransition [delete]
+func (co StatusTransitionController) Delete(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	var params form.DeleteParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		co.SetError(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	for _, v := range params.IDs {
+		result := co.DB.Delete(&databases.MedStatusTransition{}, v)
+		if result.Error != nil {
+			co.SetError(http.StatusInternalServerError, result.Error.Error())
+			return
+		}
+	}
+
+	co.SetBody(structs.SuccessResponse{
+		Success: true,
+	})
+	return
+}
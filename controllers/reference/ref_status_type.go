@@ -28,6 +28,8 @@ func (co StatusTypeController) Init(router *gin.RouterGroup) {
 	router.POST("/list", co.List)             // List
 	router.GET("/list/active", co.ListActive) // ListActive
 	router.GET("get/:id", co.Get)             // Show
+	router.GET("/graph/:id", co.Graph)        // Graph
+	router.POST("/graph/:id", co.SaveGraph)   // SaveGraph
 	router.POST("", co.Create)                // Create
 	router.PUT("/:id", co.Update)             // Update
 	router.DELETE("", co.Delete)              // Delete
@@ -40,6 +42,7 @@ func (co StatusTypeController) Init(router *gin.RouterGroup) {
 // @Accept json
 // @Produce json
 // @Param filter body form.MeasureFilter true "filter"
+// @Param lang query string false "locale, Accept-Language when empty"
 // @Success 200 {object} structs.ResponseBody{body=[]databases.MedStatusType}
 // @Failure 400 {object} structs.ErrorResponse
 // @Failure 500 {object} structs.ErrorResponse
@@ -68,6 +71,7 @@ func (co StatusTypeController) List(c *gin.Context) {
 
 	var statusType []databases.MedStatusType
 	db.Find(&statusType)
+	co.Localize(c, &statusType)
 
 	db.Table("med_status_types").Count(&count)
 
@@ -85,20 +89,23 @@ func (co StatusTypeController) List(c *gin.Context) {
 // @Tags StatusType
 // @Accept json
 // @Produce json
+// @Param lang query string false "locale, Accept-Language when empty"
+// @Param If-None-Match header string false "өмнөх ETag"
 // @Success 200 {object} structs.ResponseBody{body=[]databases.MedStatusType}
+// @Header 200 {string} ETag "лавлахын хувилбар"
+// @Success 304 "өөрчлөгдөөгүй"
 // @Failure 400 {object} structs.ErrorResponse
 // @Failure 500 {object} structs.ErrorResponse
 // @Router /statusType/list/active [get]
 func (co StatusTypeController) ListActive(c *gin.Context) {
-	defer func() {
-		c.JSON(co.GetBody())
-	}()
-
-	var statusTypes []databases.MedStatusType
-	co.DB.Where("is_active = ?", true).Preload("Status").Find(&statusTypes)
-
-	co.SetBody(statusTypes)
-	return
+	co.CachedJSON(c, "statusType.active", []string{"med_status_types", "med_statuses", "med_translations"}, func() (interface{}, error) {
+		var statusTypes []databases.MedStatusType
+		if err := co.DB.Where("is_active = ?", true).Preload("Status").Find(&statusTypes).Error; err != nil {
+			return nil, err
+		}
+		co.Localize(c, &statusTypes)
+		return statusTypes, nil
+	})
 }
 
 // Get statusType
@@ -108,6 +115,7 @@ func (co StatusTypeController) ListActive(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path uint true "statusType ID"
+// @Param lang query string false "locale, Accept-Language when empty"
 // @Success 200 {object} structs.ResponseBody{body=databases.MedStatusType}
 // @Failure 400 {object} structs.ErrorResponse
 // @Failure 500 {object} structs.ErrorResponse
@@ -124,6 +132,7 @@ func (co StatusTypeController) Get(c *gin.Context) {
 		return
 	}
 
+	co.Localize(c, &statusType)
 	co.SetBody(statusType)
 	return
 }
@@ -44,6 +44,7 @@ func (co StreetController) Init(router *gin.RouterGroup) {
 // @Accept json
 // @Produce json
 // @Param filter body form.StreetFilter true "filter"
+// @Param lang query string false "locale, Accept-Language when empty"
 // @Success 200 {object} structs.ResponseBody{body=[]databases.RefStreet}
 // @Failure 400 {object} structs.ErrorResponse
 // @Failure 500 {object} structs.ErrorResponse
@@ -73,6 +74,7 @@ func (co StreetController) List(c *gin.Context) {
 
 	var countries []databases.RefStreet
 	db.Preload("District").Preload("District.City").Preload("District.City.Country").Find(&countries)
+	co.Localize(c, &countries)
 
 	db.Table("ref_streets").Count(&count)
 
@@ -90,24 +92,29 @@ func (co StreetController) List(c *gin.Context) {
 // @Tags City
 // @Accept json
 // @Produce json
+// @Param lang query string false "locale, Accept-Language when empty"
+// @Param If-None-Match header string false "өмнөх ETag"
 // @Success 200 {object} structs.ResponseBody{body=[]databases.RefStreet}
+// @Header 200 {string} ETag "лавлахын хувилбар"
+// @Success 304 "өөрчлөгдөөгүй"
 // @Failure 400 {object} structs.ErrorResponse
 // @Failure 500 {object} structs.ErrorResponse
 // @Router /streets/list/active/{district_id} [get]
 func (co StreetController) ListActive(c *gin.Context) {
-	defer func() {
-		c.JSON(co.GetBody())
-	}()
-
-	var counties []databases.RefStreet
-	if c.Param("district_id") == "" {
-		co.DB.Find(&counties)
-	} else {
-		co.DB.Where("district_id = ?", c.Param("district_id")).Find(&counties)
-	}
+	districtID := c.Param("district_id")
 
-	co.SetBody(counties)
-	return
+	co.CachedJSON(c, "street.active:"+districtID, []string{"ref_streets", "med_translations"}, func() (interface{}, error) {
+		var counties []databases.RefStreet
+		db := co.DB
+		if districtID != "" {
+			db = db.Where("district_id = ?", districtID)
+		}
+		if err := db.Find(&counties).Error; err != nil {
+			return nil, err
+		}
+		co.Localize(c, &counties)
+		return counties, nil
+	})
 }
 
 // Get streets
@@ -117,6 +124,7 @@ func (co StreetController) ListActive(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path uint true "streets ID"
+// @Param lang query string false "locale, Accept-Language when empty"
 // @Success 200 {object} structs.ResponseBody{body=databases.RefStreet}
 // @Failure 400 {object} structs.ErrorResponse
 // @Failure 500 {object} structs.ErrorResponse
@@ -133,6 +141,7 @@ func (co StreetController) Get(c *gin.Context) {
 		return
 	}
 
+	co.Localize(c, &streets)
 	co.SetBody(streets)
 	return
 }
@@ -33,6 +33,9 @@ func (co ValuteController) Init(router *gin.RouterGroup) {
 	router.PUT("/:id", co.Update)                  // Update
 	router.DELETE("", co.Delete)                   // Delete
 	router.GET("/mongolBank/:name", co.MongolBank) //MongolBank
+	router.GET("/rate", co.Rate)                   // Rate
+	router.GET("/rate/history", co.RateHistory)    // RateHistory
+	router.POST("/rate/fetch", co.FetchRate)       // FetchRate
 }
 
 // List valute
@@ -42,6 +45,7 @@ func (co ValuteController) Init(router *gin.RouterGroup) {
 // @Accept json
 // @Produce json
 // @Param filter body form.ValuteFilter true "filter"
+// @Param lang query string false "locale, Accept-Language when empty"
 // @Success 200 {object} structs.ResponseBody{body=[]databases.RefValute}
 // @Failure 400 {object} structs.ErrorResponse
 // @Failure 500 {object} structs.ErrorResponse
@@ -70,6 +74,7 @@ func (co ValuteController) List(c *gin.Context) {
 
 	var valute []databases.RefValute
 	db.Find(&valute)
+	co.Localize(c, &valute)
 
 	db.Table("ref_valutes").Count(&count)
 
@@ -88,6 +93,7 @@ func (co ValuteController) List(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path uint true "valute ID"
+// @Param lang query string false "locale, Accept-Language when empty"
 // @Success 200 {object} structs.ResponseBody{body=databases.RefValute}
 // @Failure 400 {object} structs.ErrorResponse
 // @Failure 500 {object} structs.ErrorResponse
@@ -104,6 +110,7 @@ func (co ValuteController) Get(c *gin.Context) {
 		return
 	}
 
+	co.Localize(c, &valute)
 	co.SetBody(valute)
 	return
 }
@@ -0,0 +1,197 @@
+package reference
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	gin "github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	databases "gitlab.com/fibocloud/medtech/gin/databases"
+	services "gitlab.com/fibocloud/medtech/gin/services"
+	gorm "gorm.io/gorm"
+)
+
+// ValuteRateResponse өдрийн ханш ба шалгасан утга
+type ValuteRateResponse struct {
+	databases.MedExchangeRate
+	Value databases.Decimal `json:"value"` // value өгсөн бол шалгагдсан утга, үгүй бол ханш
+}
+
+// Rate valute
+// @Summary Rate valute
+// @Description Exchange rate in effect on a date: that day's rate or the latest one before it.
+// @Description With value the typed-in rate is checked against it, as order and income creation do.
+// @Tags Valute
+// @Accept json
+// @Produce json
+// @Param code query string true "USD, EUR"
+// @Param date query string false "2006-01-02, today by default"
+// @Param value query number false "rate to check"
+// @Success 200 {object} structs.ResponseBody{body=ValuteRateResponse}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 404 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /valute/rate [get]
+func (co ValuteController) Rate(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	date, ok := co.rateDate(c, "date")
+	if !ok {
+		return
+	}
+
+	valute, ok := co.valuteByCode(c.Query("code"))
+	if !ok {
+		return
+	}
+
+	rate, err := services.RateOn(co.DB, valute, date)
+	if err != nil {
+		co.setRateError(err)
+		return
+	}
+
+	response := ValuteRateResponse{MedExchangeRate: *rate, Value: rate.Rate}
+	if c.Query("value") != "" {
+		entered, err := decimal.NewFromString(c.Query("value"))
+		if err != nil {
+			co.SetError(http.StatusBadRequest, "value буруу байна")
+			return
+		}
+		value, err := services.ResolveValuteValue(co.DB, valute.Base.ID, date, entered)
+		if err != nil {
+			co.setRateError(err)
+			return
+		}
+		response.Value = databases.NewDecimal(value)
+	}
+
+	co.SetBody(response)
+	return
+}
+
+// RateHistory valute
+// @Summary RateHistory valute
+// @Description Stored daily rates of a currency, newest first
+// @Tags Valute
+// @Accept json
+// @Produce json
+// @Param code query string true "USD, EUR"
+// @Param start_date query string false "2006-01-02, 30 days ago by default"
+// @Param end_date query string false "2006-01-02, today by default"
+// @Success 200 {object} structs.ResponseBody{body=[]databases.MedExchangeRate}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /valute/rate/history [get]
+func (co ValuteController) RateHistory(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	end, ok := co.rateDate(c, "end_date")
+	if !ok {
+		return
+	}
+	start := end.AddDate(0, 0, -30)
+	if c.Query("start_date") != "" {
+		if start, ok = co.rateDate(c, "start_date"); !ok {
+			return
+		}
+	}
+
+	valute, ok := co.valuteByCode(c.Query("code"))
+	if !ok {
+		return
+	}
+
+	rates, err := services.RateHistory(co.DB, valute.Base.ID, start, end)
+	if err != nil {
+		co.SetError(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	co.SetBody(rates)
+	return
+}
+
+// FetchRate valute
+// @Summary FetchRate valute
+// @Description Fetch today's rates from the configured provider now and store them, as the daily job does
+// @Tags Valute
+// @Accept json
+// @Produce json
+// @Success 200 {object} structs.ResponseBody{body=[]databases.MedExchangeRate}
+// @Failure 500 {object} structs.ErrorResponse
+// @Failure 503 {object} structs.ErrorResponse
+// @Router /valute/rate/fetch [post]
+func (co ValuteController) FetchRate(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	rates, err := services.IngestRates(ctx, co.DB, services.NewRateProvider(), time.Now())
+	if err != nil {
+		co.setRateError(err)
+		return
+	}
+
+	co.SetBody(rates)
+	return
+}
+
+// valuteByCode валютыг кодоор нь (Name) олно
+func (co ValuteController) valuteByCode(code string) (databases.RefValute, bool) {
+	var valute databases.RefValute
+
+	code = strings.TrimSpace(code)
+	if code == "" {
+		co.SetError(http.StatusBadRequest, "Валютын код оруулна уу")
+		return valute, false
+	}
+
+	result := co.DB.Where("UPPER(name) = ?", strings.ToUpper(code)).First(&valute)
+	if result.Error != nil {
+		co.SetError(http.StatusNotFound, "Валют олдсонгүй")
+		return valute, false
+	}
+	return valute, true
+}
+
+// rateDate query-н огноо, хоосон бол өнөөдөр
+func (co ValuteController) rateDate(c *gin.Context, key string) (time.Time, bool) {
+	now := time.Now()
+	if c.Query(key) == "" {
+		return now, true
+	}
+
+	date, err := time.ParseInLocation("2006-01-02", c.Query(key), now.Location())
+	if err != nil {
+		co.SetError(http.StatusBadRequest, key+" буруу байна")
+		return now, false
+	}
+	return date, true
+}
+
+// setRateError ханшийн алдааг http код руу хөрвүүлнэ
+func (co ValuteController) setRateError(err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		co.SetError(http.StatusNotFound, "Валют олдсонгүй")
+	case errors.Is(err, services.ErrRateNotFound):
+		co.SetError(http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrRateMismatch):
+		co.SetError(http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrRateUnavailable):
+		co.SetError(http.StatusServiceUnavailable, err.Error())
+	default:
+		co.SetError(http.StatusInternalServerError, err.Error())
+	}
+}
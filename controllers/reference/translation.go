@@ -0,0 +1,117 @@
+package reference
+
+import (
+	"errors"
+	"net/http"
+
+	gin "github.com/gin-gonic/gin"
+	"gitlab.com/fibocloud/medtech/gin/controllers/shared"
+	form "gitlab.com/fibocloud/medtech/gin/form"
+	services "gitlab.com/fibocloud/medtech/gin/services"
+	structs "gitlab.com/fibocloud/medtech/gin/structs"
+)
+
+// TranslationController struct
+type TranslationController struct {
+	shared.BaseController
+}
+
+// Init Controller
+func (co TranslationController) Init(router *gin.RouterGroup) {
+	router.GET("/:entityType", co.List) // List
+	router.PUT("/:entityType", co.Save) // Save
+}
+
+// List translation
+// @Summary List translation
+// @Description Every record of a reference table with its name in each locale, for bulk editing.
+// @Description entityType is the table name, e.g. ref_countries, med_statuses, med_payment_types.
+// @Tags Translation
+// @Accept json
+// @Produce json
+// @Param entityType path string true "table name"
+// @Param locale query string false "only this locale"
+// @Success 200 {object} structs.ResponseBody{body=[]services.TranslationRow}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /translation/{entityType} [get]
+func (co TranslationController) List(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	rows, err := services.ListTranslations(co.DB, c.Param("entityType"), c.Query("locale"))
+	if err != nil {
+		co.setTranslationError(err)
+		return
+	}
+
+	co.SetBody(rows)
+	return
+}
+
+// Save translation
+// @Summary Save translation
+// @Description Bulk upsert names per locale for one reference table. An empty name removes the translation.
+// @Description The default locale is the record's own name and cannot be set here.
+// @Tags Translation
+// @Accept json
+// @Produce json
+// @Param entityType path string true "table name"
+// @Param translation body form.TranslationParams true "translation"
+// @Success 200 {object} structs.ResponseBody{body=structs.SuccessResponse}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /translation/{entityType} [put]
+func (co TranslationController) Save(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	var params form.TranslationParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		co.SetValidationError(shared.FieldErrors(err))
+		return
+	}
+
+	var items []services.TranslationInput
+	for _, item := range params.Items {
+		items = append(items, services.TranslationInput{
+			EntityID: item.EntityID,
+			Locale:   item.Locale,
+			Name:     item.Name,
+		})
+	}
+
+	authUser := co.GetAuth(c)
+	tx := co.DB.Begin()
+	err := services.SaveTranslations(tx, c.Param("entityType"), items, authUser.Base.ID)
+	if err != nil {
+		tx.Rollback()
+		co.setTranslationError(err)
+		return
+	}
+
+	result := tx.Commit()
+	if result.Error != nil {
+		co.SetError(http.StatusInternalServerError, result.Error.Error())
+		return
+	}
+
+	co.SetBody(structs.SuccessResponse{
+		Success: true,
+	})
+	return
+}
+
+// setTranslationError орчуулгын алдааг http код руу хөрвүүлнэ
+func (co TranslationController) setTranslationError(err error) {
+	switch {
+	case errors.Is(err, services.ErrTranslationEntity),
+		errors.Is(err, services.ErrTranslationLocale),
+		errors.Is(err, services.ErrTranslationEntityID):
+		co.SetError(http.StatusBadRequest, err.Error())
+	default:
+		co.SetError(http.StatusInternalServerError, err.Error())
+	}
+}
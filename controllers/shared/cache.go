@@ -0,0 +1,54 @@
+package shared
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	services "gitlab.com/fibocloud/medtech/gin/services"
+)
+
+// CachedJSON лавлахын хариуг кэшээс өгнө. Хариу нь key, хүсэлтийн хэл, tables-ын
+// хувилбараас хамаарна. If-None-Match ETag-тай таарвал 304 буцаана.
+// Хариуг өөрөө бичдэг тул дуудсан handler defer-ээр c.JSON хийх ёсгүй.
+func (co BaseController) CachedJSON(c *gin.Context, key string, tables []string, load func() (interface{}, error)) {
+	cache := services.GetRefCache()
+	etag := cache.ETag(key+"@"+strings.Join(RequestLocales(c), ","), tables)
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Vary", "Accept-Language")
+	if etagMatch(c.GetHeader("If-None-Match"), etag) {
+		c.Header("ETag", etag)
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	body, err := cache.Load(etag, func() ([]byte, error) {
+		value, err := load()
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(value)
+	})
+	if err != nil {
+		co.SetError(http.StatusInternalServerError, err.Error())
+		c.JSON(co.GetBody())
+		return
+	}
+
+	c.Header("ETag", etag)
+	co.SetBody(json.RawMessage(body))
+	c.JSON(co.GetBody())
+}
+
+// etagMatch If-None-Match толгойд etag байгаа эсэх, W/ угтварыг үл тооно
+func etagMatch(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package shared
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	services "gitlab.com/fibocloud/medtech/gin/services"
+)
+
+// RequestLocales lang query, үгүй бол Accept-Language-аас хэлний дараалал гаргана
+func RequestLocales(c *gin.Context) []string {
+	if lang := c.Query("lang"); lang != "" {
+		return services.LocaleChain([]string{lang})
+	}
+	return services.LocaleChain(parseAcceptLanguage(c.GetHeader("Accept-Language")))
+}
+
+// Localize лавлахын нэрийг хүсэлтийн хэлээр солино. Орчуулга уншиж чадаагүй бол
+// үндсэн нэрээрээ үлдэнэ.
+func (co BaseController) Localize(c *gin.Context, records interface{}) {
+	_ = services.TranslateNames(co.DB, RequestLocales(c), records)
+}
+
+// parseAcceptLanguage "en-US,en;q=0.9,mn;q=0.8" -> [en-US en mn], q-ээр эрэмбэлсэн
+func parseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		tag := strings.TrimSpace(fields[0])
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if value, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = value
+				}
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+		tags = append(tags, weighted{tag: tag, q: q})
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].q > tags[j].q
+	})
+
+	var locales []string
+	for _, tag := range tags {
+		locales = append(locales, tag.tag)
+	}
+	return locales
+}
@@ -0,0 +1,106 @@
+package shared
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	databases "gitlab.com/fibocloud/medtech/gin/databases"
+	structs "gitlab.com/fibocloud/medtech/gin/structs"
+)
+
+// validationMessages дүрэм тус бүрийн алдааны текст
+var validationMessages = map[string]string{
+	"required": "Заавал оруулна уу",
+	"email":    "И-мэйл хаяг буруу байна",
+	"min":      "Утга хэт бага байна",
+	"max":      "Утга хэт их байна",
+	"gt":       "Утга хэт бага байна",
+	"gte":      "Утга хэт бага байна",
+	"oneof":    "Зөвшөөрөгдөөгүй утга байна",
+	"numeric":  "Зөвхөн тоо оруулна уу",
+	"phone":    "Утасны дугаар буруу байна",
+}
+
+// phonePattern 8 оронтой дугаар, +976 кодтой эсвэл кодгүй
+var phonePattern = regexp.MustCompile(`^(\+?976[ -]?)?\d{4}[ -]?\d{4}$`)
+
+func init() {
+	// алдааны мэдээлэлд struct-ын нэрийн оронд json нэрийг ашиглана
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+			if name == "-" {
+				return ""
+			}
+			return name
+		})
+
+		v.RegisterValidation("phone", func(fl validator.FieldLevel) bool {
+			return phonePattern.MatchString(strings.TrimSpace(fl.Field().String()))
+		})
+
+		// мөнгөн дүнг required, gt зэрэг дүрмээр тоо шиг шалгана
+		v.RegisterCustomTypeFunc(func(field reflect.Value) interface{} {
+			if value, ok := field.Interface().(databases.Decimal); ok {
+				amount, _ := value.Float64()
+				return amount
+			}
+			return nil
+		}, databases.Decimal{})
+	}
+}
+
+// FieldErrors bind хийх үеийн алдааг талбар тус бүрээр задална
+func FieldErrors(err error) []structs.FieldError {
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		var fields []structs.FieldError
+		for _, fe := range validationErrors {
+			message, ok := validationMessages[fe.Tag()]
+			if !ok {
+				message = "Утга буруу байна"
+			}
+
+			// CustomerCreateParams.addresses[0].street_id -> addresses[0].street_id
+			field := fe.Namespace()
+			if i := strings.Index(field, "."); i >= 0 {
+				field = field[i+1:]
+			}
+
+			fields = append(fields, structs.FieldError{
+				Field:   field,
+				Rule:    fe.Tag(),
+				Message: message,
+			})
+		}
+		return fields
+	}
+
+	var typeError *json.UnmarshalTypeError
+	if errors.As(err, &typeError) {
+		return []structs.FieldError{{
+			Field:   typeError.Field,
+			Rule:    "type",
+			Message: "Утгын төрөл буруу байна",
+		}}
+	}
+
+	return []structs.FieldError{{
+		Rule:    "body",
+		Message: err.Error(),
+	}}
+}
+
+// SetValidationError талбарын алдаатай хариу
+func (co BaseController) SetValidationError(fields []structs.FieldError) {
+	co.Response.StatusCode = 200
+	co.Response.Body.StatusCode = http.StatusBadRequest
+	co.Response.Body.ErrorMsg = "Оруулсан мэдээлэл буруу байна"
+	co.Response.Body.Body = fields
+}
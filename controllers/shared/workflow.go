@@ -0,0 +1,29 @@
+package shared
+
+import (
+	"errors"
+	"net/http"
+
+	services "gitlab.com/fibocloud/medtech/gin/services"
+	gorm "gorm.io/gorm"
+)
+
+// SetWorkflowError төлөв шилжүүлэх үеийн алдааг хариу болгоно
+func (co BaseController) SetWorkflowError(err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		co.SetError(http.StatusNotFound, "Баримт олдсонгүй")
+	case errors.Is(err, services.ErrTransitionRole),
+		errors.Is(err, services.ErrCreditOverrideRole):
+		co.SetError(http.StatusForbidden, err.Error())
+	case errors.Is(err, services.ErrUnknownDocument),
+		errors.Is(err, services.ErrTransitionNotAllowed),
+		errors.Is(err, services.ErrTransitionComment),
+		errors.Is(err, services.ErrWorkflowStart),
+		errors.Is(err, services.ErrCreditExceeded),
+		errors.Is(err, services.ErrCreditOverrideReason):
+		co.SetError(http.StatusBadRequest, err.Error())
+	default:
+		co.SetError(http.StatusInternalServerError, err.Error())
+	}
+}
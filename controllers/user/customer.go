@@ -1,25 +1,25 @@
 package user
 
 import (
-	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io/ioutil"
 	"net/http"
-	"path/filepath"
 	"reflect"
 	"strconv"
 	"strings"
 	"time"
 
 	gin "github.com/gin-gonic/gin"
-	"github.com/minio/minio-go/v7"
+	"github.com/gin-gonic/gin/binding"
 	"gitlab.com/fibocloud/medtech/gin/constracts"
 	"gitlab.com/fibocloud/medtech/gin/controllers/shared"
 	databases "gitlab.com/fibocloud/medtech/gin/databases"
 	form "gitlab.com/fibocloud/medtech/gin/form"
+	services "gitlab.com/fibocloud/medtech/gin/services"
 	structs "gitlab.com/fibocloud/medtech/gin/structs"
 	utils "gitlab.com/fibocloud/medtech/gin/utils"
+	gorm "gorm.io/gorm"
 )
 
 // AllRefs ...
@@ -31,6 +31,22 @@ type AllRefs struct {
 	PaymentTypes   []databases.MedPaymentType            `json:"payment_types"`
 }
 
+// Улсын бүртгэлээс хайсан үр дүн
+const (
+	RegistryFound       = "found"
+	RegistryNotFound    = "not_found"
+	RegistryUnavailable = "unavailable"
+)
+
+// CompanyLookupResponse ...
+type CompanyLookupResponse struct {
+	IsRegistered    bool                    `json:"is_registered"`
+	ChildCustomers  []databases.MedCustomer `json:"child_customers"`
+	RegistryStatus  string                  `json:"registry_status"`  // found, not_found, unavailable
+	RegistryMessage string                  `json:"registry_message"` //
+	Ebarint         *services.CompanyInfo   `json:"company"`
+}
+
 // CustomerController struct
 type CustomerController struct {
 	shared.BaseController
@@ -48,12 +64,14 @@ func (co CustomerController) Init(router *gin.RouterGroup) {
 	router.GET("get/:id", co.Get)                                  // Show
 	router.GET("/find/company/:rdCode", co.FindComapnyRD)          // Show
 	router.POST("", co.Create)                                     // Create
+	router.POST("/import", co.Import)                              // Import
 	router.POST("/permission", co.CreatePermission)                // Erh uusgeh
 	router.GET("/status/list", co.ListStatuses)                    // Status list
 	router.POST("/status/change", co.ChangeStatus)                 // Status Change
 	router.POST("/item/price", co.UpdateItemPrice)                 // Erh uusgeh
 	router.POST("/list/price", co.ListPrice)                       // ListPrice
 	router.POST("/get/price", co.GetPriceDetail)                   // ListPrice
+	router.POST("/price/explain", co.PriceExplain)                 // PriceExplain
 	router.GET("/refs", co.Refs)                                   // Refs
 	router.PUT("/:id", co.Update)                                  // Update
 	router.GET("/country/:id", co.CustomerCountry)                 // CustomerCountry
@@ -62,6 +80,24 @@ func (co CustomerController) Init(router *gin.RouterGroup) {
 	router.GET("/list/active", co.ListActive)                      // ListActive
 	router.GET("/change/active/:id", co.ChangeActive)              // ChangeActive
 	router.POST("/warehouse/item/list", co.WarehouseItemPriceList) // WarehouseItemPriceList
+	router.POST("/document/upload/:id", co.UploadDocument)         // UploadDocument
+	router.GET("/document/history/:id", co.DocumentHistory)        // DocumentHistory
+	router.POST("/tax/refresh", co.TaxRefresh)                     // TaxRefresh
+	router.GET("/tax/changes", co.TaxChanges)                      // TaxChanges
+	router.GET("/tree/descendants/:id", co.TreeDescendants)        // TreeDescendants
+	router.GET("/tree/ancestors/:id", co.TreeAncestors)            // TreeAncestors
+	router.GET("/tree/summary/:id", co.TreeSummary)                // TreeSummary
+	router.POST("/tree/move", co.TreeMove)                         // TreeMove
+	router.POST("/credit/check", co.CreditCheck)                   // CreditCheck
+	router.POST("/credit/override", co.CreditOverride)             // CreditOverride
+	router.POST("/payment/list", co.ListPayments)                  // ListPayments
+	router.POST("/payment", co.CreatePayment)                      // CreatePayment
+	router.GET("/duplicate/list", co.DuplicateList)                // DuplicateList
+	router.POST("/duplicate/merge", co.DuplicateMerge)             // DuplicateMerge
+	router.GET("/duplicate/history", co.DuplicateHistory)          // DuplicateHistory
+	router.GET("/statement/:id", co.Statement)                     // Statement
+	router.POST("/aging/report", co.AgingReport)                   // AgingReport
+	router.POST("/aging/export", co.AgingExport)                   // AgingExport
 }
 
 // HistoryStatuses customer
@@ -88,11 +124,12 @@ func (co CustomerController) HistoryStatuses(c *gin.Context) {
 
 // FindComapnyRD customer
 // @Summary FindComapnyRD customer
-// @Description FindComapnyRD customer
+// @Description Look up a company in the state registry and list the branches registered under it
 // @Tags City
 // @Accept json
 // @Produce json
-// @Success 200 {object} structs.ResponseBody{body=[]databases.MedCustomer}
+// @Param rdCode path string true "company registry number"
+// @Success 200 {object} structs.ResponseBody{body=CompanyLookupResponse}
 // @Failure 400 {object} structs.ErrorResponse
 // @Failure 500 {object} structs.ErrorResponse
 // @Router /customer/find/company/{rdCode} [get]
@@ -101,49 +138,30 @@ func (co CustomerController) FindComapnyRD(c *gin.Context) {
 		c.JSON(co.GetBody())
 	}()
 
-	type EbarintCompany struct {
-		VatpayerRegisteredDate string `json:"vatpayer_registered_date"`
-		LastReceiptDate        string `json:"last_receipt_date"`
-		ReceiptFound           string `json:"receipt_found"`
-		Name                   string `json:"name"`
-		Citypayer              string `json:"citypayer"`
-		Vatpayer               string `json:"vatpayer"`
-		Found                  string `json:"found"`
-	}
-
-	type Response struct {
-		IsRegistered   bool                    `json:"is_registered"`
-		ChildCustomers []databases.MedCustomer `json:"child_customers"`
-		Ebarint        interface{}             `json:"company"`
-	}
-
-	var response Response
-	var ebarimtCompany EbarintCompany
+	registryNumber := services.NormalizeRegistryNumber(c.Param("rdCode"))
 
-	url := "http://info.ebarimt.mn/rest/merchant/info?regno=" + c.Param("rdCode")
-	method := "GET"
-
-	client := &http.Client{}
-	req, err := http.NewRequest(method, url, nil)
-
-	if err != nil {
-		return
-	}
-	res, err := client.Do(req)
-	if err != nil {
+	var response CompanyLookupResponse
+	company, err := services.NewCompanyRegistry(co.DB).Lookup(c.Request.Context(), registryNumber)
+	switch {
+	case err == nil:
+		response.RegistryStatus = RegistryFound
+		response.Ebarint = company
+	case errors.Is(err, services.ErrRegistryInvalid):
+		co.SetError(http.StatusBadRequest, err.Error())
 		return
-	}
-	defer res.Body.Close()
-
-	body, err := ioutil.ReadAll(res.Body)
-	if err != nil {
+	case errors.Is(err, services.ErrRegistryNotFound):
+		response.RegistryStatus = RegistryNotFound
+		response.RegistryMessage = err.Error()
+	case errors.Is(err, services.ErrRegistryUnavailable):
+		response.RegistryStatus = RegistryUnavailable
+		response.RegistryMessage = services.ErrRegistryUnavailable.Error()
+	default:
+		co.SetError(http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	json.Unmarshal([]byte(string(body)), &ebarimtCompany)
-
 	var customer databases.MedCustomer
-	result := co.DB.Where("company_registry_number = ?", c.Param("rdCode")).First(&customer)
+	result := co.DB.Where("company_registry_number = ?", registryNumber).First(&customer)
 	if result.Error != nil {
 		if result.Error.Error() == "record not found" {
 			response.IsRegistered = false
@@ -155,16 +173,13 @@ func (co CustomerController) FindComapnyRD(c *gin.Context) {
 		response.ChildCustomers = childCustomers
 	}
 
-	if ebarimtCompany.Name != "" {
-		response.Ebarint = ebarimtCompany
-	}
 	co.SetBody(response)
 	return
 }
 
 // ListPrice customer
 // @Summary ListPrice customer
-// @Description ListPrice customer
+// @Description Customer special prices grouped by customer. price is the price resolved for today like /customer/price/explain
 // @Tags City
 // @Accept json
 // @Produce json
@@ -203,6 +218,8 @@ func (co CustomerController) ListPrice(c *gin.Context) {
 		StartDate    time.Time                `json:"start_date"`       //
 		EndDate      time.Time                `json:"end_date"`         //
 		IsActive     bool                     `json:"is_active"`        // Идэвхитэй эсэх
+		Price        databases.Decimal        `json:"price"`            // Өнөөдөр хэрэгжих үнэ, үлдэгдэлгүй бол 0
+		PriceSource  string                   `json:"price_source"`     // base, rule, customer
 		CreatedUser  *databases.MedSystemUser `json:"created_user"`     // Үүсгэсэн хэрэглэгч
 		ModifiedUser *databases.MedSystemUser `json:"modified_user"`    // Өөрчилсөн хэрэглэгч
 	}
@@ -245,6 +262,16 @@ func (co CustomerController) ListPrice(c *gin.Context) {
 	indexCounter := 1
 
 	for _, i := range customerPrices {
+		// тусгай үнэ хугацаа, дүрмээс хамааран хэрэгжихгүй байж болох тул ResolvePrice-ээр тооцно
+		resolution, err := services.ResolvePrice(co.DB, services.PriceQuery{
+			CustomerID: i.CustomerID,
+			ItemID:     i.ItemID,
+		})
+		if err != nil && !errors.Is(err, services.ErrPriceNotFound) {
+			co.SetError(http.StatusInternalServerError, err.Error())
+			return
+		}
+
 		if temp[i.CustomerID] == 0 {
 
 			temp[i.CustomerID] = indexCounter
@@ -263,6 +290,10 @@ func (co CustomerController) ListPrice(c *gin.Context) {
 			eachChild.StartDate = i.StartDate
 			eachChild.EndDate = i.EndDate
 			eachChild.IsActive = i.IsActive
+			if resolution != nil {
+				eachChild.Price = resolution.Price
+				eachChild.PriceSource = resolution.Winner.Source
+			}
 			eachChild.CreatedUser = i.CreatedUser
 			eachChild.ModifiedUser = i.ModifiedUser
 
@@ -278,6 +309,10 @@ func (co CustomerController) ListPrice(c *gin.Context) {
 			eachChild.StartDate = i.StartDate
 			eachChild.EndDate = i.EndDate
 			eachChild.IsActive = i.IsActive
+			if resolution != nil {
+				eachChild.Price = resolution.Price
+				eachChild.PriceSource = resolution.Winner.Source
+			}
 			eachChild.CreatedUser = i.CreatedUser
 			eachChild.ModifiedUser = i.ModifiedUser
 
@@ -366,7 +401,7 @@ func (co CustomerController) ListStatuses(c *gin.Context) {
 		c.JSON(co.GetBody())
 	}()
 	var statuses []databases.MedStatus
-	co.DB.Where("status_type_id = ?", 3).Find(&statuses)
+	co.DB.Where("status_type_id = ?", services.StatusTypeID(constracts.StatusTypeCustomer)).Find(&statuses)
 	co.SetBody(statuses)
 	return
 }
@@ -534,6 +569,9 @@ func (co CustomerController) Get(c *gin.Context) {
 		Preload("Classification").
 		Preload("Parent").
 		Preload("Files.ContentType").
+		Preload("Documents", "is_current = ? AND content_type_id IN (?)", true, RequiredDocuments()).
+		Preload("Documents.Content").
+		Preload("Documents.ContentType").
 		Preload("CreatedUser.Person").
 		Preload("ModifiedUser.Person").
 		First(&customer, c.Param("id"))
@@ -548,12 +586,12 @@ func (co CustomerController) Get(c *gin.Context) {
 
 // GetPriceDetail customer
 // @Summary GetPriceDetail customer
-// @Description GetPriceDetail customer
+// @Description Customer price of the item for every warehouse item in stock, resolved like /customer/price/explain
 // @Tags Customer
 // @Accept json
 // @Produce json
 // @Param Customer body form.GetPriceDetailParam true "Customer"
-// @Success 200 {object} structs.ResponseBody{body=databases.MedCustomer}
+// @Success 200 {object} structs.ResponseBody{body=[]services.WarehousePrice}
 // @Failure 400 {object} structs.ErrorResponse
 // @Failure 500 {object} structs.ErrorResponse
 // @Router /customer/get/price [post]
@@ -568,55 +606,20 @@ func (co CustomerController) GetPriceDetail(c *gin.Context) {
 		return
 	}
 
-	var customerPrice databases.MedPriceCustomer
-	result := co.DB.
-		Where("customer_id = ?", params.CustomerID).
-		Where("item_id = ?", params.ItemID).
-		Where("is_active = ?", true).
-		Preload("CreatedUser.Person").
-		Preload("ModifiedUser.Person").
-		Last(&customerPrice)
-	if result.Error != nil {
-		co.SetError(http.StatusInternalServerError, result.Error.Error())
+	prices, err := services.ResolveWarehousePrices(co.DB, services.PriceQuery{
+		CustomerID: uint(params.CustomerID),
+		ItemID:     uint(params.ItemID),
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		co.SetError(http.StatusNotFound, "Харилцагч олдсонгүй")
 		return
 	}
-
-	type Reponse struct {
-		WareHouseItem *databases.MedWarehouseItem `json:"warehouse_item"` // Агуулах ID
-		Price         float64                     `json:"price"`
-	}
-
-	var responses []Reponse
-
-	var warehousePrices []databases.MedSalesPriceDtl
-
-	var ids []int
-	result = co.DB.
-		Where("warehouse_item_id IN (?)", co.DB.Table("med_warehouse_items").Where("item_id = ?", params.ItemID).Not("total_qty = ?", 0).Pluck("id", &ids)).
-		Where("is_active = ?", true).
-		Where("price_type_id = ?", 1).
-		Preload("WarehouseItem.Item").
-		Find(&warehousePrices)
-
-	if result.Error != nil {
-		co.SetError(http.StatusInternalServerError, result.Error.Error())
+	if err != nil {
+		co.SetError(http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	for _, i := range warehousePrices {
-		var each Reponse
-		each.WareHouseItem = i.WarehouseItem
-
-		if customerPrice.IsPercent {
-			each.Price = i.SalesPrice + i.SalesPrice*customerPrice.Percent/100
-		} else {
-			each.Price = i.SalesPrice
-		}
-
-		responses = append(responses, each)
-	}
-
-	co.SetBody(responses)
+	co.SetBody(prices)
 	return
 }
 
@@ -644,34 +647,16 @@ func (co CustomerController) ChangeStatus(c *gin.Context) {
 
 	tx := co.DB.Begin()
 
-	var customer databases.MedCustomer
-	result := co.DB.First(&customer, params.CustomerID)
-	if result.Error != nil {
-		co.SetError(http.StatusInternalServerError, result.Error.Error())
-		return
-	}
-
-	customer.StatusID = uint(params.StatusID)
-	resultOldSave := tx.Save(&customer)
-	if resultOldSave.Error != nil {
+	authUser := co.GetAuth(c)
+	statusChange, err := services.NewWorkflow(tx).Transition(services.DocumentCustomer, uint(params.CustomerID), uint(params.StatusID), params.Description, authUser)
+	if err != nil {
 		tx.Rollback()
-		co.SetError(http.StatusInternalServerError, "Харилцагчийн төлөв өөрчлөхөд алдаа гарлаа "+resultOldSave.Error.Error())
+		co.SetWorkflowError(err)
 		return
 	}
 
-	authUser := co.GetAuth(c)
-	customerStatusLog := databases.MedStatusLog{
-		CreatedUser:  &authUser,
-		RecordID:     uint(params.CustomerID),
-		StatusID:     uint(params.StatusID),
-		Description:  params.Description,
-		HdrTableName: "med_customers",
-		Base: databases.Base{
-			CreatedDate: time.Now(),
-		},
-	}
-
-	result = tx.Create(&customerStatusLog)
+	var customer databases.MedCustomer
+	result := tx.First(&customer, params.CustomerID)
 	if result.Error != nil {
 		tx.Rollback()
 		co.SetError(http.StatusInternalServerError, result.Error.Error())
@@ -679,19 +664,20 @@ func (co CustomerController) ChangeStatus(c *gin.Context) {
 	}
 
 	tx.Commit()
+	services.PublishStatusChange(statusChange)
 	co.SetBody(customer)
 	return
 }
 
 // Create customer
 // @Summary Create customer
-// @Description Add customer
+// @Description Add customer. Documents are attached afterwards with /customer/document/upload/{id}
 // @Tags Customer
 // @Accept json
 // @Produce json
 // @Param customer body form.CustomerCreateParams true "customer"
-// @Success 200 {object} structs.ResponseBody{body=structs.SuccessResponse}
-// @Failure 400 {object} structs.ErrorResponse
+// @Success 200 {object} structs.ResponseBody{body=databases.MedCustomer}
+// @Failure 400 {object} structs.ValidationErrorResponse
 // @Failure 500 {object} structs.ErrorResponse
 // @Router /customer [post]
 func (co CustomerController) Create(c *gin.Context) {
@@ -699,531 +685,117 @@ func (co CustomerController) Create(c *gin.Context) {
 		c.JSON(co.GetBody())
 	}()
 
-	name, errName := c.GetPostForm("name")
-	if !errName {
-		co.SetError(http.StatusInternalServerError, "Харилцагч нэр оруулна уу!")
+	var params form.CustomerCreateParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		co.SetValidationError(shared.FieldErrors(err))
 		return
 	}
 
-	countryID, errCountryID := c.GetPostForm("country_id")
-	if !errCountryID {
-		co.SetError(http.StatusInternalServerError, "Харилцагчийн улсыг оруулна уу")
+	if fields := validateCustomerParams(&params); len(fields) > 0 {
+		co.SetValidationError(fields)
 		return
 	}
 
-	description, _ := c.GetPostForm("description")
-	companyRd, errCompanyRd := c.GetPostForm("company_rd")
-	if countryID == strconv.Itoa(constracts.MongoliaCountryCode) {
-		if !errCompanyRd {
-			co.SetError(http.StatusInternalServerError, "Байгууллагын РД оруулна уу!")
-			return
-		}
-	}
+	company := co.lookupParentCompany(c, params)
 
-	companyName, errCompanyName := c.GetPostForm("company_name")
-	if countryID == strconv.Itoa(constracts.MongoliaCountryCode) {
-		if !errCompanyName {
-			co.SetError(http.StatusInternalServerError, "Компани нэр оруулна уу!")
-			return
-		}
+	authUser := co.GetAuth(c)
+	tx := co.DB.Begin()
+
+	customer, statusChanges, err := co.createCustomer(tx, params, company, authUser)
+	if err != nil {
+		tx.Rollback()
+		co.SetError(http.StatusInternalServerError, err.Error())
+		return
 	}
 
-	cityID := ""
-	districtID := ""
-	errCityID := false
-	addressDescription := ""
-	intCountryID, _ := strconv.Atoi(countryID)
+	tx.Commit()
+	services.PublishStatusChange(statusChanges...)
+	co.SetBody(customer)
+	return
+}
 
-	if intCountryID == constracts.MongoliaCountryCode {
-		cityID, errCityID = c.GetPostForm("city_id")
-		if !errCityID {
-			co.SetError(http.StatusInternalServerError, "Харилцагч аймгыг оруулна уу")
-			return
-		}
-		districtID, errCityID = c.GetPostForm("district_id")
-		if !errCityID {
-			co.SetError(http.StatusInternalServerError, "Харилцагч сум/дүүрэг оруулна уу")
-			return
-		}
-	} else {
-		addressDescription, _ = c.GetPostForm("address_description")
+// validateCustomerParams binding tag-аас гадуурх шалгалт, РД-г жигдэлнэ
+func validateCustomerParams(params *form.CustomerCreateParams) []structs.FieldError {
+	params.CompanyRD = services.NormalizeRegistryNumber(params.CompanyRD)
+	if fields := params.Validate(); len(fields) > 0 {
+		return fields
 	}
 
-	paymentTypeID, errPaymentTypeID := c.GetPostForm("payment_type_id")
-	if !errPaymentTypeID {
-		co.SetError(http.StatusInternalServerError, "Төлбөрийн нөхцөл")
-		return
+	if params.CountryID == int(services.MongoliaCountryID()) && !services.ValidRegistryNumber(params.CompanyRD) {
+		return []structs.FieldError{{
+			Field:   "company_rd",
+			Rule:    "registry_number",
+			Message: "Регистрийн дугаар буруу байна",
+		}}
 	}
+	return nil
+}
 
-	maximumPurchase, _ := c.GetPostForm("maximum_purchase")
-	maximumReceivables, _ := c.GetPostForm("maximum_receivables")
-	oneTimePurchaseLimit, _ := c.GetPostForm("one_time_purchase_limit")
-	classificationID, errClassificationID := c.GetPostForm("classification_id")
-	if !errClassificationID {
-		co.SetError(http.StatusInternalServerError, "Ангилал сонгоно уу")
-		return
+// lookupParentCompany толгой байгууллага бүртгэгдээгүй бол РД-аар татварын мэдээллийг
+// transaction эхлэхээс өмнө лавлана. Олдохгүй бол nil буцаана.
+func (co CustomerController) lookupParentCompany(c *gin.Context, params form.CustomerCreateParams) *services.CompanyInfo {
+	if params.CountryID != int(services.MongoliaCountryID()) {
+		return nil
 	}
 
-	customerTypesString, errCustomerTypes := c.GetPostForm("customer_types")
-	if !errCustomerTypes {
-		co.SetError(http.StatusInternalServerError, "Төрөл сонгоно уу")
-		return
+	var count int64
+	co.DB.Model(&databases.MedCustomer{}).Where("company_registry_number = ?", params.CompanyRD).Count(&count)
+	if count > 0 {
+		return nil
 	}
-	addressesString, _ := c.GetPostForm("addresses")
-	contactsString, _ := c.GetPostForm("contacts")
-
-	var customerTypes []*databases.MedCustomerType
-	json.Unmarshal([]byte(customerTypesString), &customerTypes)
-
-	intDistrictID, _ := strconv.Atoi(districtID)
-	intClassificationID, _ := strconv.Atoi(classificationID)
-	intOneTimePurchaseLimit, _ := strconv.Atoi(oneTimePurchaseLimit)
-	intMaximumReceivables, _ := strconv.Atoi(maximumReceivables)
-	intMaximumPurchase, _ := strconv.Atoi(maximumPurchase)
-	intCityID, _ := strconv.Atoi(cityID)
 
-	intPaymentTypeID, _ := strconv.Atoi(paymentTypeID)
-
-	// types, _ := utils.JSONUnmarshal([]byte(customerTypes))
-	tx := co.DB.Begin()
-	authUser := co.GetAuth(c)
-	formdata, _ := c.MultipartForm()
-	minioClient := co.MinioClinet()
+	company, err := services.NewCompanyRegistry(co.DB).Lookup(c.Request.Context(), params.CompanyRD)
+	if err != nil {
+		return nil
+	}
+	return company
+}
 
-	var preCustomer databases.MedCustomer
-	todayTimeStump := time.Now().Format("200601")
-	resultPre := co.DB.Where("code LIKE ?", "%"+todayTimeStump+"%").Last(&preCustomer)
+// createCustomer шалгагдсан params-аар харилцагч, холбоо барих, хаягийг tx дотор бүртгэнэ.
+// company нь lookupParentCompany-аар урьдчилан авсан татварын мэдээлэл.
+// Төлөвийн өөрчлөлтийг commit хийсний дараа нийтэлнэ.
+func (co CustomerController) createCustomer(tx *gorm.DB, params form.CustomerCreateParams, company *services.CompanyInfo, authUser databases.MedSystemUser) (*databases.MedCustomer, []*services.StatusChange, error) {
+	isMongolia := params.CountryID == int(services.MongoliaCountryID())
 
+	// Монгол харилцагч бол РД-аар толгой байгууллагыг олно, байхгүй бол бүртгэнэ
 	var parentCustomer databases.MedCustomer
-
-	if intCountryID == constracts.MongoliaCountryCode {
-		result := co.DB.Where("company_registry_number = ?", companyRd).First(&parentCustomer)
-		// 202101020002
+	var statusChanges []*services.StatusChange
+	if isMongolia {
+		result := tx.Where("company_registry_number = ?", params.CompanyRD).Limit(1).Find(&parentCustomer)
 		if result.Error != nil {
-			if result.Error.Error() == "record not found" {
-				directorCards := formdata.File["director_cards"]
-				certs := formdata.File["certifications"]
-				licenses := formdata.File["licenses"]
-				var allFiles []*databases.MedContent
-				// Parent бүртгэх хэрэгтэй байна
-
-				newCode := time.Now().Format("200601")
-
-				if resultPre.Error != nil {
-					newCode = newCode + "001"
-				} else {
-					// 202012290001
-					tempstr := preCustomer.Code[6:9]
-					intCode, intErr := strconv.Atoi(tempstr)
-					if intErr != nil {
-						co.SetError(http.StatusBadRequest, intErr.Error())
-						return
-					}
-					s := fmt.Sprintf("%03d", intCode+1)
-					newCode = newCode + s
-				}
-
-				if intCountryID == constracts.MongoliaCountryCode {
-					companyName = name
-					// if directorCards == nil {
-					// 	tx.Rollback()
-					// 	co.SetError(http.StatusInternalServerError, "Захиралын иргэнийн үнэмлэх оруулана уу ")
-					// 	return
-					// }
-
-					// if certs == nil {
-					// 	tx.Rollback()
-					// 	co.SetError(http.StatusInternalServerError, "Улсын бүртгэлийн гэрчилгээ хуулбар оруулна уу ")
-					// 	return
-					// }
-
-					// if licenses == nil {
-					// 	tx.Rollback()
-					// 	co.SetError(http.StatusInternalServerError, "Тусгай зөвшөөрөл оруулна уу ")
-					// 	return
-					// }
-				}
-
-				createdParentCustomer := databases.MedCustomer{
-					Name:                  companyName,
-					Code:                  newCode,
-					Description:           description,
-					IsActive:              true,
-					AddressDescription:    addressDescription,
-					CountryID:             uint(intCountryID),
-					CityID:                uint(intCityID),
-					DistrictID:            uint(intDistrictID),
-					ClassificationID:      4,
-					PaymentTypeID:         uint(intPaymentTypeID),
-					MaximumReceivables:    float64(intMaximumReceivables),
-					OneTimePurchaseLimit:  float64(intOneTimePurchaseLimit),
-					MaximumPurchase:       float64(intMaximumPurchase),
-					CompanyRegistryNumber: companyRd,
-					StatusID:              uint(constracts.CustomerAccountConfirmed),
-					CreatedUser:           &authUser,
-					ModifiedUser:          &authUser,
-					Base: databases.Base{
-						CreatedDate: time.Now(),
-					},
-				}
-
-				result = tx.Create(&createdParentCustomer)
-				if result.Error != nil {
-					tx.Rollback()
-					co.SetError(http.StatusInternalServerError, "Харилцагч бүртгэж чадсангүй"+result.Error.Error())
-					return
-				}
-
-				customerStatusLog := databases.MedStatusLog{
-					CreatedUser:  &authUser,
-					RecordID:     uint(createdParentCustomer.Base.ID),
-					StatusID:     uint(constracts.CustomerAccountConfirmed),
-					Description:  "",
-					HdrTableName: "med_customers",
-					Base: databases.Base{
-						CreatedDate: time.Now(),
-					},
-				}
-
-				result = tx.Create(&customerStatusLog)
-				if result.Error != nil {
-					tx.Rollback()
-					co.SetError(http.StatusInternalServerError, result.Error.Error())
-					return
-				}
-
-				bucketName := companyRd
-				bucketError := minioClient.MakeBucket(context.Background(), bucketName, minio.MakeBucketOptions{})
-				if bucketError != nil {
-					fmt.Println("bucketError", bucketError.Error())
-				}
-
-				for _, cardFile := range directorCards {
-					types := cardFile.Header["Content-Type"]
-					openFile, errorfileOpen := cardFile.Open()
-					if errorfileOpen != nil {
-						co.SetError(http.StatusInternalServerError, "Файл нээх үед алдаа гарлаа "+errorfileOpen.Error())
-					}
-
-					fileName := time.Now().Format("20060102150405") + " - " + cardFile.Filename
-
-					uploadInfo, errUploadInfo := minioClient.PutObject(context.Background(), bucketName, fileName, openFile, cardFile.Size, minio.PutObjectOptions{ContentType: types[0]})
-					if errUploadInfo != nil {
-						co.SetError(http.StatusInternalServerError, "Файлын санруу хуулах үед алдаа гарлаа "+errUploadInfo.Error())
-						return
-					}
-					eachFile := databases.MedContent{
-						FileName:      fileName,
-						PhysicalPath:  uploadInfo.Bucket + "/" + uploadInfo.Key,
-						ContentTypeID: uint(constracts.ContentDirectorCards),
-						FileSize:      float64(cardFile.Size),
-						Extention:     filepath.Ext(cardFile.Filename),
-						CreatedUser:   &authUser,
-						Base: databases.Base{
-							CreatedDate: time.Now(),
-						},
-					}
-
-					result := tx.Create(&eachFile)
-					if result.Error != nil {
-						tx.Rollback()
-						co.SetError(http.StatusInternalServerError, "Файл датабайзруу хуулах үед алдаа гарлаа "+result.Error.Error())
-						return
-					}
-
-					allFiles = append(allFiles, &eachFile)
-				}
-
-				for _, certFile := range certs {
-					types := certFile.Header["Content-Type"]
-					openFile, errorfileOpen := certFile.Open()
-					if errorfileOpen != nil {
-						co.SetError(http.StatusInternalServerError, "Файл нээх үед алдаа гарлаа "+errorfileOpen.Error())
-					}
-					fileName := time.Now().Format("20060102150405") + " - " + certFile.Filename
-
-					uploadInfo, errUploadInfo := minioClient.PutObject(context.Background(), bucketName, fileName, openFile, certFile.Size, minio.PutObjectOptions{ContentType: types[0]})
-					if errUploadInfo != nil {
-						co.SetError(http.StatusInternalServerError, "Файлын санруу хуулах үед алдаа гарлаа "+errUploadInfo.Error())
-						return
-					}
-
-					eachFile := databases.MedContent{
-						FileName:      fileName,
-						PhysicalPath:  uploadInfo.Bucket + "/" + uploadInfo.Key,
-						ContentTypeID: uint(constracts.ContentCertification),
-						FileSize:      float64(certFile.Size),
-						Extention:     filepath.Ext(certFile.Filename),
-						CreatedUser:   &authUser,
-						Base: databases.Base{
-							CreatedDate: time.Now(),
-						},
-					}
-
-					result = tx.Create(&eachFile)
-					if result.Error != nil {
-						tx.Rollback()
-						co.SetError(http.StatusInternalServerError, "Файл датабайзруу хуулах үед алдаа гарлаа "+result.Error.Error())
-						return
-					}
-
-					allFiles = append(allFiles, &eachFile)
-
-					createdParentCustomer.Files = allFiles
-					createdParentCustomer.Types = customerTypes
-
-					result = tx.Save(&createdParentCustomer)
-					if result.Error != nil {
-						tx.Rollback()
-						co.SetError(http.StatusInternalServerError, result.Error.Error())
-						return
-					}
-				}
-
-				// 2021010222203
-				tempstr := newCode
-				intCode, intErr := strconv.Atoi(tempstr)
-				if intErr != nil {
-					co.SetError(http.StatusBadRequest, intErr.Error())
-					return
-				}
-				s := fmt.Sprintf("%03d", intCode+1)
-
-				var allLicenses []*databases.MedContent
-				customer := databases.MedCustomer{
-					Name:                 name,
-					CompanyName:          createdParentCustomer.Name,
-					Code:                 s,
-					Description:          description,
-					IsActive:             true,
-					AddressDescription:   addressDescription,
-					CountryID:            uint(intCountryID),
-					CityID:               uint(intCityID),
-					DistrictID:           uint(intDistrictID),
-					ClassificationID:     uint(intClassificationID),
-					PaymentTypeID:        uint(intPaymentTypeID),
-					MaximumReceivables:   float64(intMaximumReceivables),
-					OneTimePurchaseLimit: float64(intOneTimePurchaseLimit),
-					MaximumPurchase:      float64(intMaximumPurchase),
-					ParentID:             createdParentCustomer.Base.ID,
-					StatusID:             uint(constracts.CustomerAccountConfirmed),
-					CreatedUser:          &authUser,
-					ModifiedUser:         &authUser,
-					Base: databases.Base{
-						CreatedDate: time.Now(),
-					},
-				}
-
-				result = tx.Create(&customer)
-				if result.Error != nil {
-					tx.Rollback()
-					co.SetError(http.StatusInternalServerError, "Харилцагч бүртгэж чадсангүй"+result.Error.Error())
-					return
-				}
-
-				customersStatusLog := databases.MedStatusLog{
-					CreatedUser:  &authUser,
-					RecordID:     uint(customer.Base.ID),
-					StatusID:     uint(constracts.CustomerAccountConfirmed),
-					Description:  "",
-					HdrTableName: "med_customers",
-					Base: databases.Base{
-						CreatedDate: time.Now(),
-					},
-				}
-
-				result = tx.Create(&customersStatusLog)
-				if result.Error != nil {
-					tx.Rollback()
-					co.SetError(http.StatusInternalServerError, result.Error.Error())
-					return
-				}
-
-				for _, fileLicense := range licenses {
-					types := fileLicense.Header["Content-Type"]
-					openFile, errorfileOpen := fileLicense.Open()
-					if errorfileOpen != nil {
-						co.SetError(http.StatusInternalServerError, "Файл нээх үед алдаа гарлаа "+errorfileOpen.Error())
-					}
-
-					fileName := name + "/" + time.Now().Format("20060102150405") + " - " + fileLicense.Filename
-					uploadInfo, errUploadInfo := minioClient.PutObject(context.Background(), bucketName, fileName, openFile, fileLicense.Size, minio.PutObjectOptions{ContentType: types[0]})
-					if errUploadInfo != nil {
-						co.SetError(http.StatusInternalServerError, "Файлын санруу хуулах үед алдаа гарлаа "+errUploadInfo.Error())
-						return
-					}
-					eachFile := databases.MedContent{
-						FileName:      fileName,
-						PhysicalPath:  uploadInfo.Bucket + "/" + uploadInfo.Key,
-						ContentTypeID: uint(constracts.ContentLicence),
-						FileSize:      float64(fileLicense.Size),
-						CreatedUser:   &authUser,
-						Extention:     filepath.Ext(fileLicense.Filename),
-						Base: databases.Base{
-							CreatedDate: time.Now(),
-						},
-					}
-
-					result = tx.Create(&eachFile)
-					if result.Error != nil {
-						tx.Rollback()
-						co.SetError(http.StatusInternalServerError, "Файл датабайзруу хуулах үед алдаа гарлаа "+result.Error.Error())
-						return
-					}
-
-					allLicenses = append(allLicenses, &eachFile)
-				}
-
-				if contactsString != "" {
-					var customerContacts []*databases.MedCustomerContacts
-					json.Unmarshal([]byte(contactsString), &customerContacts)
-
-					for _, contanct := range customerContacts {
-						eachContact := databases.MedCustomerContacts{
-							CustomerID:     customer.Base.ID,
-							LastName:       strings.Trim(contanct.LastName, " "),
-							FirstName:      strings.Trim(contanct.FirstName, " "),
-							RegisterNumber: strings.Trim(contanct.RegisterNumber, " "),
-							PositionID:     contanct.PositionID,
-							PhoneNumber1:   strings.Trim(contanct.PhoneNumber1, " "),
-							PhoneNumber2:   strings.Trim(contanct.PhoneNumber2, " "),
-							Email1:         strings.Trim(contanct.Email1, " "),
-							Email2:         strings.Trim(contanct.Email2, " "),
-							CreatedUser:    &authUser,
-							ModifiedUser:   &authUser,
-							Base: databases.Base{
-								CreatedDate: time.Now(),
-							},
-						}
-
-						result := tx.Create(&eachContact)
-						if result.Error != nil {
-							tx.Rollback()
-							co.SetError(http.StatusInternalServerError, "Холбоо барих алдаа гарлаа "+result.Error.Error())
-							return
-						}
-					}
-				}
-
-				if addressesString != "" {
-					var customerAddresses []*databases.MedCustomerAddress
-					json.Unmarshal([]byte(addressesString), &customerAddresses)
-
-					for _, address := range customerAddresses {
-						eachAddress := databases.MedCustomerAddress{
-							CustomerID:    customer.Base.ID,
-							CountryID:     uint(constracts.MongoliaCountryCode),
-							CityID:        uint(intCityID),
-							DistrictID:    uint(intDistrictID),
-							StreetID:      address.StreetID,
-							AddressTypeID: address.AddressTypeID,
-							Description:   address.Description,
-							CreatedUser:   &authUser,
-							ModifiedUser:  &authUser,
-							Base: databases.Base{
-								CreatedDate: time.Now(),
-							},
-						}
-
-						result := tx.Create(&eachAddress)
-						if result.Error != nil {
-							tx.Rollback()
-							co.SetError(http.StatusInternalServerError, "Хаяг байршил нэмэх алдаа гарлаа "+result.Error.Error())
-							return
-						}
-					}
-				}
-
-				if intCountryID != constracts.MongoliaCountryCode {
-					eachAddress := databases.MedCustomerAddress{
-						CustomerID:    customer.Base.ID,
-						CountryID:     uint(intCountryID),
-						AddressTypeID: 1,
-						Description:   addressDescription,
-						CreatedUser:   &authUser,
-						ModifiedUser:  &authUser,
-						Base: databases.Base{
-							CreatedDate: time.Now(),
-						},
-					}
-
-					result := tx.Create(&eachAddress)
-					if result.Error != nil {
-						tx.Rollback()
-						co.SetError(http.StatusInternalServerError, "Хаяг байршил нэмэх алдаа гарлаа "+result.Error.Error())
-						return
-					}
-				}
-
-				customer.Files = allLicenses
-				customer.Types = customerTypes
-
-				result = tx.Save(&customer)
-				if result.Error != nil {
-					tx.Rollback()
-					co.SetError(http.StatusInternalServerError, result.Error.Error())
-					return
-				}
-
-				co.SetBody(structs.SuccessResponse{
-					Success: true,
-				})
-
-				tx.Commit()
-				return
-			}
-			tx.Rollback()
-			co.SetError(http.StatusInternalServerError, result.Error.Error())
-			return
+			return nil, nil, result.Error
 		}
-	}
-
-	newParentID := parentCustomer.Base.ID
-	newParentName := parentCustomer.Name
-
-	if intCountryID != constracts.MongoliaCountryCode {
-		newParentID = 0
-		newParentName = ""
-	}
-
-	newCode := time.Now().Format("200601")
-	var allLicenses []*databases.MedContent
 
-	if resultPre.Error != nil {
-		newCode = newCode + "001"
-	} else {
-		// 202012290001
-		fmt.Println(preCustomer.Code)
-		tempstr := preCustomer.Code[6:9]
-		fmt.Println(tempstr)
-		intCode, intErr := strconv.Atoi(tempstr)
-		if intErr != nil {
-			co.SetError(http.StatusBadRequest, intErr.Error())
-			return
+		if result.RowsAffected == 0 {
+			createdParent, parentChange, err := co.createParentCustomer(tx, params, company, authUser)
+			if err != nil {
+				return nil, nil, fmt.Errorf("Харилцагч бүртгэж чадсангүй %w", err)
+			}
+			parentCustomer = *createdParent
+			statusChanges = append(statusChanges, parentChange)
 		}
-		s := fmt.Sprintf("%03d", intCode+1)
-		newCode = newCode + s
 	}
 
-	fmt.Println(newCode)
-
 	customer := databases.MedCustomer{
-		Name:                 name,
-		CompanyName:          newParentName,
-		Code:                 newCode,
-		Description:          description,
+		Name:                 params.Name,
+		CompanyName:          parentCustomer.Name,
+		Description:          params.Description,
 		IsActive:             true,
-		AddressDescription:   addressDescription,
-		CountryID:            uint(intCountryID),
-		CityID:               uint(intCityID),
-		DistrictID:           uint(intDistrictID),
-		ClassificationID:     uint(intClassificationID),
-		PaymentTypeID:        uint(intPaymentTypeID),
-		MaximumReceivables:   float64(intMaximumReceivables),
-		OneTimePurchaseLimit: float64(intOneTimePurchaseLimit),
-		MaximumPurchase:      float64(intMaximumPurchase),
-		ParentID:             newParentID,
-		StatusID:             uint(constracts.CustomerAccountConfirmed),
+		AddressDescription:   params.AddressDescription,
+		CountryID:            uint(params.CountryID),
+		CityID:               uint(params.CityID),
+		DistrictID:           uint(params.DistrictID),
+		ClassificationID:     uint(params.ClassificationID),
+		PaymentTypeID:        uint(params.PaymentTypeID),
+		MaximumReceivables:   params.MaximumReceivables,
+		OneTimePurchaseLimit: params.OneTimePurchaseLimit,
+		MaximumPurchase:      params.MaximumPurchase,
+		ParentID:             parentCustomer.Base.ID,
+		StatusID:             services.StatusID(constracts.StatusCustomerAccountConfirmed),
+		VatPayer:             parentCustomer.VatPayer,
+		CityPayer:            parentCustomer.CityPayer,
+		VatRegisteredDate:    parentCustomer.VatRegisteredDate,
+		TaxCheckedDate:       parentCustomer.TaxCheckedDate,
 		CreatedUser:          &authUser,
 		ModifiedUser:         &authUser,
 		Base: databases.Base{
@@ -1231,192 +803,122 @@ func (co CustomerController) Create(c *gin.Context) {
 		},
 	}
 
+	// дугаарын мөрийг commit хүртэл түгжих тул хамгийн сүүлд олгоно
+	newCode, err := services.NextNumber(tx, services.NumberCustomer, time.Now())
+	if err != nil {
+		return nil, nil, fmt.Errorf("Харилцагчийн код олгох үед алдаа гарлаа %w", err)
+	}
+	customer.Code = newCode
+
 	result := tx.Create(&customer)
 	if result.Error != nil {
-		tx.Rollback()
-		co.SetError(http.StatusInternalServerError, "Харилцагч бүртгэж чадсангүй"+result.Error.Error())
-		return
+		return nil, nil, fmt.Errorf("Харилцагч бүртгэж чадсангүй %w", result.Error)
 	}
 
-	childCustomer := databases.MedStatusLog{
-		CreatedUser:  &authUser,
-		RecordID:     uint(customer.Base.ID),
-		StatusID:     uint(constracts.CustomerAccountConfirmed),
-		Description:  "",
-		HdrTableName: "med_customers",
-		Base: databases.Base{
-			CreatedDate: time.Now(),
-		},
+	statusChange, err := services.NewWorkflow(tx).Start(services.DocumentCustomer, customer.Base.ID, services.StatusID(constracts.StatusCustomerAccountConfirmed), authUser)
+	if err != nil {
+		return nil, nil, err
 	}
+	statusChanges = append(statusChanges, statusChange)
 
-	result = tx.Create(&childCustomer)
-	if result.Error != nil {
-		tx.Rollback()
-		co.SetError(http.StatusInternalServerError, result.Error.Error())
-		return
+	err = tx.Model(&customer).Association("Types").Append(params.Types)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	bucketName := companyRd
-	licenses := formdata.File["licenses"]
-
-	// if intCountryID == constracts.MongoliaCountryCode {
-	// 	if len(licenses) == 0 {
-	// 		tx.Rollback()
-	// 		co.SetError(http.StatusInternalServerError, "Тусгай зөвшөөрөл оруулна уу")
-	// 		return
-	// 	}
-	// }
-
-	for _, fileLicense := range licenses {
-		types := fileLicense.Header["Content-Type"]
-		openFile, errorfileOpen := fileLicense.Open()
-		if errorfileOpen != nil {
-			co.SetError(http.StatusInternalServerError, "Файл нээх үед алдаа гарлаа "+errorfileOpen.Error())
-		}
-
-		fileName := name + "/" + time.Now().Format("20060102150405") + " - " + fileLicense.Filename
-		uploadInfo, errUploadInfo := minioClient.PutObject(context.Background(), bucketName, fileName, openFile, fileLicense.Size, minio.PutObjectOptions{ContentType: types[0]})
-		if errUploadInfo != nil {
-			co.SetError(http.StatusInternalServerError, "Файлын санруу хуулах үед алдаа гарлаа "+errUploadInfo.Error())
-			return
-		}
-		eachFile := databases.MedContent{
-			FileName:      fileName,
-			PhysicalPath:  uploadInfo.Bucket + "/" + uploadInfo.Key,
-			ContentTypeID: uint(constracts.ContentLicence),
-			FileSize:      float64(fileLicense.Size),
-			CreatedUser:   &authUser,
-			Extention:     filepath.Ext(fileLicense.Filename),
+	for _, contact := range params.Contacts {
+		eachContact := databases.MedCustomerContacts{
+			CustomerID:  customer.Base.ID,
+			IsActive:    true,
+			CreatedUser: &authUser,
 			Base: databases.Base{
 				CreatedDate: time.Now(),
 			},
 		}
 
-		result = tx.Create(&eachFile)
-		if result.Error != nil {
-			tx.Rollback()
-			co.SetError(http.StatusInternalServerError, "Файл датабайзруу хуулах үед алдаа гарлаа "+result.Error.Error())
-			return
+		err = saveCustomerContact(tx, &eachContact, contact, authUser)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Холбоо барих алдаа гарлаа %w", err)
 		}
-
-		allLicenses = append(allLicenses, &eachFile)
 	}
 
-	if contactsString != "" {
-		var customerContacts []*databases.MedCustomerContacts
-		json.Unmarshal([]byte(contactsString), &customerContacts)
-
-		for _, contanct := range customerContacts {
-			eachContact := databases.MedCustomerContacts{
-				CustomerID:     customer.Base.ID,
-				LastName:       strings.Trim(contanct.LastName, " "),
-				FirstName:      strings.Trim(contanct.FirstName, " "),
-				RegisterNumber: strings.Trim(contanct.RegisterNumber, " "),
-				PositionID:     contanct.PositionID,
-				PhoneNumber1:   strings.Trim(contanct.PhoneNumber1, " "),
-				PhoneNumber2:   strings.Trim(contanct.PhoneNumber2, " "),
-				Email1:         strings.Trim(contanct.Email1, " "),
-				Email2:         strings.Trim(contanct.Email2, " "),
-				CreatedUser:    &authUser,
-				ModifiedUser:   &authUser,
-				Base: databases.Base{
-					CreatedDate: time.Now(),
-				},
-			}
-
-			result := tx.Create(&eachContact)
-			if result.Error != nil {
-				tx.Rollback()
-				co.SetError(http.StatusInternalServerError, "Холбоо барих алдаа гарлаа "+result.Error.Error())
-				return
-			}
-		}
-	}
-
-	if addressesString != "" {
-		var customerAddresses []*databases.MedCustomerAddress
-		json.Unmarshal([]byte(addressesString), &customerAddresses)
-
-		for _, address := range customerAddresses {
-			eachAddress := databases.MedCustomerAddress{
-				CustomerID:    customer.Base.ID,
-				CountryID:     uint(constracts.MongoliaCountryCode),
-				CityID:        uint(intCityID),
-				DistrictID:    uint(intDistrictID),
-				StreetID:      address.StreetID,
-				AddressTypeID: address.AddressTypeID,
-				Description:   address.Description,
-				CreatedUser:   &authUser,
-				ModifiedUser:  &authUser,
-				Base: databases.Base{
-					CreatedDate: time.Now(),
-				},
-			}
-
-			result := tx.Create(&eachAddress)
-			if result.Error != nil {
-				tx.Rollback()
-				co.SetError(http.StatusInternalServerError, "Хаяг байршил нэмэх алдаа гарлаа "+result.Error.Error())
-				return
-			}
-		}
+	addresses := params.Addresses
+	if !isMongolia {
+		addresses = []form.CustomerAddressParams{{
+			AddressTypeID: services.DefaultAddressTypeID(),
+			Description:   params.AddressDescription,
+			IsDefault:     true,
+		}}
 	}
 
-	if intCountryID != constracts.MongoliaCountryCode {
+	for _, address := range addresses {
 		eachAddress := databases.MedCustomerAddress{
-			CustomerID:    customer.Base.ID,
-			CountryID:     uint(intCountryID),
-			AddressTypeID: 1,
-			Description:   addressDescription,
-			CreatedUser:   &authUser,
-			ModifiedUser:  &authUser,
+			CustomerID:  customer.Base.ID,
+			IsActive:    true,
+			CreatedUser: &authUser,
 			Base: databases.Base{
 				CreatedDate: time.Now(),
 			},
 		}
 
-		result := tx.Create(&eachAddress)
-		if result.Error != nil {
-			tx.Rollback()
-			co.SetError(http.StatusInternalServerError, "Хаяг байршил нэмэх алдаа гарлаа "+result.Error.Error())
-			return
+		err = saveCustomerAddress(tx, customer, &eachAddress, address, authUser)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Хаяг байршил нэмэх алдаа гарлаа %w", err)
 		}
 	}
 
-	customer.Files = allLicenses
-	customer.Types = customerTypes
-
-	result = tx.Save(&customer)
-	if result.Error != nil {
-		tx.Rollback()
-		co.SetError(http.StatusInternalServerError, result.Error.Error())
-		return
-	}
+	return &customer, statusChanges, nil
+}
 
-	customerStatusLog := databases.MedStatusLog{
-		CreatedUser:  &authUser,
-		RecordID:     uint(customer.Base.ID),
-		StatusID:     uint(constracts.CustomerAccountConfirmed),
-		Description:  "",
-		HdrTableName: "med_customers",
+// createParentCustomer РД-аар бүртгэлгүй байгууллагыг толгой харилцагчаар бүртгэнэ
+func (co CustomerController) createParentCustomer(tx *gorm.DB, params form.CustomerCreateParams, company *services.CompanyInfo, authUser databases.MedSystemUser) (*databases.MedCustomer, *services.StatusChange, error) {
+	parent := databases.MedCustomer{
+		Name:                  params.CompanyName,
+		Description:           params.Description,
+		IsActive:              true,
+		CountryID:             uint(params.CountryID),
+		CityID:                uint(params.CityID),
+		DistrictID:            uint(params.DistrictID),
+		ClassificationID:      services.DefaultClassificationID(),
+		PaymentTypeID:         uint(params.PaymentTypeID),
+		MaximumReceivables:    params.MaximumReceivables,
+		OneTimePurchaseLimit:  params.OneTimePurchaseLimit,
+		MaximumPurchase:       params.MaximumPurchase,
+		CompanyRegistryNumber: params.CompanyRD,
+		StatusID:              services.StatusID(constracts.StatusCustomerAccountConfirmed),
+		CreatedUser:           &authUser,
+		ModifiedUser:          &authUser,
 		Base: databases.Base{
 			CreatedDate: time.Now(),
 		},
 	}
 
-	result = tx.Create(&customerStatusLog)
+	if company != nil {
+		services.ApplyTaxInfo(&parent, company)
+	}
+
+	newCode, err := services.NextNumber(tx, services.NumberCustomer, time.Now())
+	if err != nil {
+		return nil, nil, err
+	}
+	parent.Code = newCode
+
+	result := tx.Create(&parent)
 	if result.Error != nil {
-		tx.Rollback()
-		co.SetError(http.StatusInternalServerError, result.Error.Error())
-		return
+		return nil, nil, result.Error
 	}
 
-	co.SetBody(structs.SuccessResponse{
-		Success: true,
-	})
+	statusChange, err := services.NewWorkflow(tx).Start(services.DocumentCustomer, parent.Base.ID, services.StatusID(constracts.StatusCustomerAccountConfirmed), authUser)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	tx.Commit()
-	return
+	err = tx.Model(&parent).Association("Types").Append(params.Types)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &parent, statusChange, nil
 }
 
 // CreatePermission customer
@@ -1441,42 +943,17 @@ func (co CustomerController) CreatePermission(c *gin.Context) {
 		return
 	}
 	authUser := co.GetAuth(c)
-	var customer databases.MedCustomer
-	result := co.DB.First(&customer, params.CustomerID)
-	if result.Error != nil {
-		co.SetError(http.StatusInternalServerError, result.Error.Error())
-		return
-	}
-
-	customer.StatusID = uint(constracts.CustomerPermissionCreated)
-	customer.ModifiedDate = time.Now()
-	customer.ModifiedUser = &authUser
-	result = co.DB.Save(&customer)
-	if result.Error != nil {
-		co.SetError(http.StatusInternalServerError, result.Error.Error())
-		return
-	}
-
-	statusLog := databases.MedStatusLog{
-		CreatedUser:  &authUser,
-		Description:  "",
-		RecordID:     customer.Base.ID,
-		StatusID:     uint(constracts.CustomerPermissionCreated),
-		HdrTableName: "med_customer",
-		Base: databases.Base{
-			CreatedDate: time.Now(),
-		},
-	}
-
-	result = co.DB.Create(&statusLog)
-	if result.Error != nil {
-		co.SetError(http.StatusInternalServerError, result.Error.Error())
+	hashPwd, err := utils.GenerateHash(params.Password)
+	if err != nil {
+		co.SetError(http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	hashPwd, err := utils.GenerateHash(params.Password)
+	tx := co.DB.Begin()
+	statusChange, err := services.NewWorkflow(tx).Transition(services.DocumentCustomer, uint(params.CustomerID), services.StatusID(constracts.StatusCustomerPermissionCreated), "", authUser)
 	if err != nil {
-		co.SetError(http.StatusInternalServerError, err.Error())
+		tx.Rollback()
+		co.SetWorkflowError(err)
 		return
 	}
 
@@ -1492,12 +969,15 @@ func (co CustomerController) CreatePermission(c *gin.Context) {
 		},
 	}
 
-	result = co.DB.Create(&systemUser)
+	result := tx.Create(&systemUser)
 	if result.Error != nil {
+		tx.Rollback()
 		co.SetError(http.StatusInternalServerError, "Нэвтрэх эрх өгөхөд алдаа гарлаа "+result.Error.Error())
 		return
 	}
 
+	tx.Commit()
+	services.PublishStatusChange(statusChange)
 	co.SetBody(structs.SuccessResponse{
 		Success: true,
 	})
@@ -1507,14 +987,21 @@ func (co CustomerController) CreatePermission(c *gin.Context) {
 
 // Update customer
 // @Summary Update customer
-// @Description Edit customer
+// @Description Edit customer. Files in licenses, certifications and director_cards are saved as new document versions.
 // @Tags Customer
-// @Accept json
+// @Accept multipart/form-data
 // @Produce json
 // @Param id path uint true "customer ID"
-// @Param customer body form.CustomerCreateParams true "customer"
+// @Param name formData string true "name"
+// @Param customer_types formData string false "customer types json"
+// @Param addresses formData string false "addresses json (form.CustomerAddressParams), replaces existing"
+// @Param contacts formData string false "contacts json (form.CustomerContactParams), replaces existing"
+// @Param licenses formData file false "licenses"
+// @Param certifications formData file false "certifications"
+// @Param director_cards formData file false "director cards"
 // @Success 200 {object} structs.ResponseBody{body=structs.SuccessResponse}
 // @Failure 400 {object} structs.ErrorResponse
+// @Failure 404 {object} structs.ErrorResponse
 // @Failure 500 {object} structs.ErrorResponse
 // @Router /customer/{id} [put]
 func (co CustomerController) Update(c *gin.Context) {
@@ -1522,243 +1009,244 @@ func (co CustomerController) Update(c *gin.Context) {
 		c.JSON(co.GetBody())
 	}()
 
-	name, _ := c.GetPostForm("name")
-	countryID, _ := c.GetPostForm("country_id")
-	description, _ := c.GetPostForm("description")
-	companyRd, _ := c.GetPostForm("company_rd")
+	formdata, err := c.MultipartForm()
+	if err != nil {
+		co.SetError(http.StatusBadRequest, err.Error())
+		return
+	}
 
-	cityID, _ := c.GetPostForm("city_id")
-	districtID, _ := c.GetPostForm("district_id")
-	addressDescription, _ := c.GetPostForm("address_description")
+	numbers := map[string]int{}
+	for _, key := range []string{"country_id", "city_id", "district_id", "classification_id", "payment_type_id"} {
+		value, err := formInt(c, key)
+		if err != nil {
+			co.SetError(http.StatusBadRequest, key+" буруу байна")
+			return
+		}
+		numbers[key] = value
+	}
 
-	intCountryID, _ := strconv.Atoi(countryID)
-	intCustomerID, _ := strconv.Atoi(c.Param("id"))
+	amounts := map[string]float64{}
+	for _, key := range []string{"maximum_purchase", "maximum_receivables"} {
+		value, err := formFloat(c, key)
+		if err != nil {
+			co.SetError(http.StatusBadRequest, key+" буруу байна")
+			return
+		}
+		amounts[key] = value
+	}
 
-	fmt.Println("intCustomerID", intCustomerID)
+	var customerTypes []*databases.MedCustomerType
+	var relations form.CustomerRelationParams
+	jsonFields := map[string]interface{}{
+		"customer_types": &customerTypes,
+		"contacts":       &relations.Contacts,
+		"addresses":      &relations.Addresses,
+	}
+	for key, target := range jsonFields {
+		if value := c.PostForm(key); value != "" {
+			if err := json.Unmarshal([]byte(value), target); err != nil {
+				co.SetError(http.StatusBadRequest, key+" буруу байна: "+err.Error())
+				return
+			}
+		}
+	}
+	if err := binding.Validator.ValidateStruct(relations); err != nil {
+		co.SetValidationError(shared.FieldErrors(err))
+		return
+	}
 
-	paymentTypeID, _ := c.GetPostForm("payment_type_id")
-	maximumPurchase, _ := c.GetPostForm("maximum_purchase")
-	maximumReceivables, _ := c.GetPostForm("maximum_receivables")
-	classificationID, _ := c.GetPostForm("classification_id")
-	customerTypesString, _ := c.GetPostForm("customer_types")
-	addressesString, _ := c.GetPostForm("addresses")
-	contactsString, _ := c.GetPostForm("contacts")
+	var customer databases.MedCustomer
+	result := co.DB.First(&customer, c.Param("id"))
+	if result.Error != nil {
+		co.SetError(http.StatusNotFound, "Харилцагч олдсонгүй")
+		return
+	}
 
-	var customerTypes []*databases.MedCustomerType
-	json.Unmarshal([]byte(customerTypesString), &customerTypes)
+	var parentCustomer databases.MedCustomer
+	if companyRd := c.PostForm("company_rd"); companyRd != "" {
+		result = co.DB.Where("company_registry_number = ?", companyRd).Limit(1).Find(&parentCustomer)
+		if result.Error != nil {
+			co.SetError(http.StatusInternalServerError, result.Error.Error())
+			return
+		}
+	}
 
-	intDistrictID, _ := strconv.Atoi(districtID)
-	intClassificationID, _ := strconv.Atoi(classificationID)
-	intMaximumReceivables, _ := strconv.Atoi(maximumReceivables)
-	intMaximumPurchase, _ := strconv.Atoi(maximumPurchase)
-	intCityID, _ := strconv.Atoi(cityID)
-	intPaymentTypeID, _ := strconv.Atoi(paymentTypeID)
+	if parentCustomer.Base.ID != customer.ParentID {
+		err := services.ValidateCustomerParent(co.DB, customer.Base.ID, parentCustomer.Base.ID)
+		if err != nil {
+			co.setTreeError(err)
+			return
+		}
+	}
 
-	// types, _ := utils.JSONUnmarshal([]byte(customerTypes))
-	tx := co.DB.Begin()
 	authUser := co.GetAuth(c)
 
-	var customer databases.MedCustomer
-	co.DB.First(&customer, intCustomerID)
-
-	var parentCustomer databases.MedCustomer
-	if companyRd != "" {
-		co.DB.Where("company_registry_number = ?", companyRd).First(&parentCustomer)
-	}
-
-	customer.Name = name
-	customer.Description = description
-	customer.AddressDescription = addressDescription
-	customer.CountryID = uint(intCountryID)
-	customer.CityID = uint(intCityID)
-	customer.DistrictID = uint(intDistrictID)
-	customer.ClassificationID = uint(intClassificationID)
-	customer.PaymentTypeID = uint(intPaymentTypeID)
-	customer.MaximumReceivables = float64(intMaximumReceivables)
-	customer.MaximumPurchase = float64(intMaximumPurchase)
+	customer.Name = c.PostForm("name")
+	customer.Description = c.PostForm("description")
+	customer.AddressDescription = c.PostForm("address_description")
+	customer.CountryID = uint(numbers["country_id"])
+	customer.CityID = uint(numbers["city_id"])
+	customer.DistrictID = uint(numbers["district_id"])
+	customer.ClassificationID = uint(numbers["classification_id"])
+	customer.PaymentTypeID = uint(numbers["payment_type_id"])
+	customer.MaximumReceivables = amounts["maximum_receivables"]
+	customer.MaximumPurchase = amounts["maximum_purchase"]
 	customer.ParentID = parentCustomer.Base.ID
 	customer.ModifiedUser = &authUser
 	customer.ModifiedDate = time.Now()
 
-	result := tx.Save(&customer)
+	tx := co.DB.Begin()
+	result = tx.Omit("Types").Save(&customer)
 	if result.Error != nil {
 		tx.Rollback()
 		co.SetError(http.StatusInternalServerError, "Харилцагч бүртгэж чадсангүй"+result.Error.Error())
 		return
 	}
 
-	if contactsString != "" {
-		co.DB.Where("customer_id = ?", intCustomerID).Delete(&databases.MedCustomerContacts{})
+	if c.PostForm("customer_types") != "" {
+		if err := tx.Model(&customer).Association("Types").Replace(customerTypes); err != nil {
+			tx.Rollback()
+			co.SetError(http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
 
-		var customerContacts []*databases.MedCustomerContacts
-		json.Unmarshal([]byte(contactsString), &customerContacts)
+	// Илгээсэн холбоо барих хүн, хаягийг Create-тэй ижил шалгалт, туслах функцээр солино
+	if c.PostForm("contacts") != "" {
+		result = tx.Where("customer_id = ?", customer.Base.ID).Delete(&databases.MedCustomerContacts{})
+		if result.Error != nil {
+			tx.Rollback()
+			co.SetError(http.StatusInternalServerError, result.Error.Error())
+			return
+		}
 
-		for _, contanct := range customerContacts {
+		for _, contact := range relations.Contacts {
 			eachContact := databases.MedCustomerContacts{
-				CustomerID:     customer.Base.ID,
-				LastName:       strings.Trim(contanct.LastName, " "),
-				FirstName:      strings.Trim(contanct.FirstName, " "),
-				RegisterNumber: strings.Trim(contanct.RegisterNumber, " "),
-				PositionID:     contanct.PositionID,
-				PhoneNumber1:   strings.Trim(contanct.PhoneNumber1, " "),
-				PhoneNumber2:   strings.Trim(contanct.PhoneNumber2, " "),
-				Email1:         strings.Trim(contanct.Email1, " "),
-				Email2:         strings.Trim(contanct.Email2, " "),
-				IsActive:       true,
-				CreatedUser:    &authUser,
-				ModifiedUser:   &authUser,
+				CustomerID:  customer.Base.ID,
+				IsActive:    true,
+				CreatedUser: &authUser,
 				Base: databases.Base{
 					CreatedDate: time.Now(),
 				},
 			}
 
-			result := tx.Create(&eachContact)
-			if result.Error != nil {
+			if err := saveCustomerContact(tx, &eachContact, contact, authUser); err != nil {
 				tx.Rollback()
-				co.SetError(http.StatusInternalServerError, "Холбоо барих алдаа гарлаа "+result.Error.Error())
+				co.SetError(http.StatusInternalServerError, "Холбоо барих алдаа гарлаа "+err.Error())
 				return
 			}
 		}
 	}
 
-	if addressesString != "" {
-		co.DB.Where("customer_id = ?", intCustomerID).Delete(&databases.MedCustomerAddress{})
-
-		var customerAddresses []*databases.MedCustomerAddress
-		json.Unmarshal([]byte(addressesString), &customerAddresses)
+	if c.PostForm("addresses") != "" {
+		result = tx.Where("customer_id = ?", customer.Base.ID).Delete(&databases.MedCustomerAddress{})
+		if result.Error != nil {
+			tx.Rollback()
+			co.SetError(http.StatusInternalServerError, result.Error.Error())
+			return
+		}
 
-		for _, address := range customerAddresses {
+		for _, address := range relations.Addresses {
 			eachAddress := databases.MedCustomerAddress{
-				CustomerID:    customer.Base.ID,
-				CountryID:     uint(constracts.MongoliaCountryCode),
-				CityID:        address.CityID,
-				DistrictID:    address.DistrictID,
-				StreetID:      address.StreetID,
-				IsActive:      true,
-				AddressTypeID: address.AddressTypeID,
-				Description:   address.Description,
-				CreatedUser:   &authUser,
-				ModifiedUser:  &authUser,
+				CustomerID:  customer.Base.ID,
+				IsActive:    true,
+				CreatedUser: &authUser,
 				Base: databases.Base{
 					CreatedDate: time.Now(),
 				},
 			}
 
-			result := tx.Create(&eachAddress)
+			if err := saveCustomerAddress(tx, customer, &eachAddress, address, authUser); err != nil {
+				tx.Rollback()
+				co.SetError(http.StatusInternalServerError, "Хаяг байршил нэмэх алдаа гарлаа "+err.Error())
+				return
+			}
+		}
+	}
+
+	// Бичиг баримтыг UploadDocument-тэй адил шинэ хувилбараар хадгална
+	documentFields := map[string]string{
+		"licenses":       constracts.ContentLicence,
+		"certifications": constracts.ContentCertification,
+		"director_cards": constracts.ContentDirectorCards,
+	}
+	bucketName := co.documentBucket(customer)
+	for field, contentCode := range documentFields {
+		files := formdata.File[field]
+		if len(files) == 0 {
+			continue
+		}
+
+		contentTypeID := services.ContentTypeID(contentCode)
+		var contents []*databases.MedContent
+		for _, fileHeader := range files {
+			content, errUpload := co.uploadContent(bucketName, customer.Name+"/", fileHeader, contentTypeID, authUser)
+			if errUpload != nil {
+				tx.Rollback()
+				co.SetError(http.StatusInternalServerError, "Файлын санруу хуулах үед алдаа гарлаа "+errUpload.Error())
+				return
+			}
+
+			result := tx.Create(content)
 			if result.Error != nil {
 				tx.Rollback()
-				co.SetError(http.StatusInternalServerError, "Хаяг байршил нэмэх алдаа гарлаа "+result.Error.Error())
+				co.SetError(http.StatusInternalServerError, "Файл датабайзруу хуулах үед алдаа гарлаа "+result.Error.Error())
 				return
 			}
+			contents = append(contents, content)
+		}
+
+		_, err := co.saveDocumentVersion(tx, customer.Base.ID, contentTypeID, contents, c.PostForm("description"), authUser)
+		if err != nil {
+			tx.Rollback()
+			co.SetError(http.StatusInternalServerError, "Баримтын хувилбар хадгалах үед алдаа гарлаа "+err.Error())
+			return
+		}
+
+		if err := tx.Model(&customer).Association("Files").Append(contents); err != nil {
+			tx.Rollback()
+			co.SetError(http.StatusInternalServerError, err.Error())
+			return
 		}
 	}
 
-	// formdata, _ := c.MultipartForm()
-	// minioClient := co.MinioClinet()
-	// var allFiles []*databases.MedContent
-	// directorCards := formdata.File["director_cards"]
-	// certs := formdata.File["certifications"]
-	// bucketName := companyRd
-	// // licenses := formdata.File["licenses"]
-
-	// for _, cardFile := range directorCards {
-	// 	types := cardFile.Header["Content-Type"]
-	// 	openFile, errorfileOpen := cardFile.Open()
-	// 	if errorfileOpen != nil {
-	// 		co.SetError(http.StatusInternalServerError, "Файл нээх үед алдаа гарлаа "+errorfileOpen.Error())
-	// 	}
-
-	// 	fileName := time.Now().Format("20060102150405") + " - " + cardFile.Filename
-
-	// 	uploadInfo, errUploadInfo := minioClient.PutObject(context.Background(), bucketName, fileName, openFile, cardFile.Size, minio.PutObjectOptions{ContentType: types[0]})
-	// 	if errUploadInfo != nil {
-	// 		co.SetError(http.StatusInternalServerError, "Файлын санруу хуулах үед алдаа гарлаа "+errUploadInfo.Error())
-	// 		return
-	// 	}
-	// 	eachFile := databases.MedContent{
-	// 		FileName:      fileName,
-	// 		PhysicalPath:  uploadInfo.Bucket + "/" + uploadInfo.Key,
-	// 		ContentTypeID: uint(constracts.ContentDirectorCards),
-	// 		FileSize:      float64(cardFile.Size),
-	// 		Extention:     filepath.Ext(cardFile.Filename),
-	// 		CreatedUser:   &authUser,
-	// 		Base: databases.Base{
-	// 			CreatedDate: time.Now(),
-	// 		},
-	// 	}
-
-	// 	result := tx.Create(&eachFile)
-	// 	if result.Error != nil {
-	// 		tx.Rollback()
-	// 		co.SetError(http.StatusInternalServerError, "Файл датабайзруу хуулах үед алдаа гарлаа "+result.Error.Error())
-	// 		return
-	// 	}
-
-	// 	allFiles = append(allFiles, &eachFile)
-	// }
-
-	// for _, certFile := range certs {
-	// 	types := certFile.Header["Content-Type"]
-	// 	openFile, errorfileOpen := certFile.Open()
-	// 	if errorfileOpen != nil {
-	// 		co.SetError(http.StatusInternalServerError, "Файл нээх үед алдаа гарлаа "+errorfileOpen.Error())
-	// 	}
-	// 	fileName := time.Now().Format("20060102150405") + " - " + certFile.Filename
-
-	// 	uploadInfo, errUploadInfo := minioClient.PutObject(context.Background(), bucketName, fileName, openFile, certFile.Size, minio.PutObjectOptions{ContentType: types[0]})
-	// 	if errUploadInfo != nil {
-	// 		co.SetError(http.StatusInternalServerError, "Файлын санруу хуулах үед алдаа гарлаа "+errUploadInfo.Error())
-	// 		return
-	// 	}
-
-	// 	eachFile := databases.MedContent{
-	// 		FileName:      fileName,
-	// 		PhysicalPath:  uploadInfo.Bucket + "/" + uploadInfo.Key,
-	// 		ContentTypeID: uint(constracts.ContentCertification),
-	// 		FileSize:      float64(certFile.Size),
-	// 		Extention:     filepath.Ext(certFile.Filename),
-	// 		CreatedUser:   &authUser,
-	// 		Base: databases.Base{
-	// 			CreatedDate: time.Now(),
-	// 		},
-	// 	}
-
-	// 	result = tx.Create(&eachFile)
-	// 	if result.Error != nil {
-	// 		tx.Rollback()
-	// 		co.SetError(http.StatusInternalServerError, "Файл датабайзруу хуулах үед алдаа гарлаа "+result.Error.Error())
-	// 		return
-	// 	}
-
-	// 	allFiles = append(allFiles, &eachFile)
-	// }
-
-	co.DB.Model(&databases.MedCustomer{}).Association("Types").Clear()
-
-	customer.Types = customerTypes
-	result = tx.Save(&customer)
-	if result.Error != nil {
-		tx.Rollback()
-		co.SetError(http.StatusInternalServerError, result.Error.Error())
+	if err := tx.Commit().Error; err != nil {
+		co.SetError(http.StatusInternalServerError, err.Error())
 		return
 	}
 
 	co.SetBody(structs.SuccessResponse{
 		Success: true,
 	})
-
-	tx.Commit()
 	return
 }
 
+// formInt form-ын бүхэл тоо, хоосон бол 0
+func formInt(c *gin.Context, key string) (int, error) {
+	value := strings.TrimSpace(c.PostForm(key))
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(value)
+}
+
+// formFloat form-ын бутархай тоо, хоосон бол 0
+func formFloat(c *gin.Context, key string) (float64, error) {
+	value := strings.TrimSpace(c.PostForm(key))
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(value, 64)
+}
+
 // UpdateItemPrice customer
 // @Summary UpdateItemPrice customer
-// @Description UpdateItemPrice customer
+// @Description Draft a price-change document with customer prices. Prices take effect after approval.
 // @Tags Customer
 // @Accept json
 // @Produce json
 // @Param customer body form.CustomerUpdatePriceParams true "customer"
-// @Success 200 {object} structs.ResponseBody{body=structs.SuccessResponse}
+// @Success 200 {object} structs.ResponseBody{body=databases.MedPriceChange}
 // @Failure 400 {object} structs.ErrorResponse
 // @Failure 500 {object} structs.ErrorResponse
 // @Router /customer/item/price [post]
@@ -1773,69 +1261,14 @@ func (co CustomerController) UpdateItemPrice(c *gin.Context) {
 		return
 	}
 
-	tx := co.DB.Begin()
-
-	for _, customerID := range params.CustomerIDs {
-		var customer databases.MedCustomer
-		result := co.DB.First(&customer, customerID)
-		if result.Error != nil {
-			tx.Rollback()
-			co.SetError(http.StatusInternalServerError, result.Error.Error())
-			return
-		}
-
-		authUser := co.GetAuth(c)
-
-		for _, item := range params.Items {
-			discountPrice := item.DiscountPrice
-			discountPercent := item.DiscountPercent
-
-			var checkItem databases.MedPriceCustomer
-			result = co.DB.Where("customer_id = ?", customerID).Where("item_id = ?", item.ItemID).Where("start_date <= ?", time.Now()).
-				Where("end_date <= ?", time.Now()).Find(&checkItem)
-
-			if result.Error == nil {
-				tx.Rollback()
-				co.SetError(http.StatusInternalServerError, "("+customer.Name+") - "+"Энэхүү бараан дээр харицлагчийн үнэ байна. ")
-				return
-			}
-
-			if params.IsPercent {
-				discountPrice = 0
-			} else {
-				discountPercent = 0
-			}
-
-			salesPriceDtl := databases.MedPriceCustomer{
-				ItemID:      uint(item.ItemID),
-				CustomerID:  uint(customerID),
-				PriceTypeID: 3,
-				SalesPrice:  discountPrice,
-				IsPercent:   params.IsPercent,
-				Percent:     discountPercent,
-				StartDate:   params.StartDate,
-				EndDate:     params.EndDate,
-				IsActive:    true,
-				CreatedUser: &authUser,
-				Base: databases.Base{
-					CreatedDate: time.Now(),
-				},
-			}
-
-			result = tx.Create(&salesPriceDtl)
-			if result.Error != nil {
-				tx.Rollback()
-				co.SetError(http.StatusInternalServerError, "Үнэ оруулах үед алдаа гарлаа "+result.Error.Error())
-				return
-			}
-		}
+	authUser := co.GetAuth(c)
+	change, err := draftPriceChange(co.DB, "Харилцагчийн үнэ", time.Now(), customerPriceLines(params), authUser)
+	if err != nil {
+		setPriceChangeError(co.BaseController, err)
+		return
 	}
 
-	co.SetBody(structs.SuccessResponse{
-		Success: true,
-	})
-
-	tx.Commit()
+	co.SetBody(change)
 	return
 }
 
@@ -1881,40 +1314,52 @@ func (co CustomerController) Delete(c *gin.Context) {
 // @Tags OrderBook
 // @Accept json
 // @Produce json
+// @Param If-None-Match header string false "өмнөх ETag"
 // @Success 200 {object} structs.ResponseBody{body=AllRefs}
+// @Header 200 {string} ETag "лавлахын хувилбар"
+// @Success 304 "өөрчлөгдөөгүй"
 // @Failure 400 {object} structs.ErrorResponse
 // @Failure 500 {object} structs.ErrorResponse
 // @Router /customer/refs [get]
 func (co CustomerController) Refs(c *gin.Context) {
-	defer func() {
-		c.JSON(co.GetBody())
-	}()
+	tables := []string{"med_customer_classifications", "med_payment_types", "med_customer_types", "med_customers", "ref_cities"}
 
-	var response AllRefs
+	co.CachedJSON(c, "customer.refs", tables, func() (interface{}, error) {
+		var response AllRefs
 
-	var classification []databases.MedCustomerClassification
-	co.DB.Find(&classification)
+		var classification []databases.MedCustomerClassification
+		if err := co.DB.Find(&classification).Error; err != nil {
+			return nil, err
+		}
 
-	var paymentTypes []databases.MedPaymentType
-	co.DB.Where("is_active = ?", true).Find(&paymentTypes)
+		var paymentTypes []databases.MedPaymentType
+		if err := co.DB.Where("is_active = ?", true).Find(&paymentTypes).Error; err != nil {
+			return nil, err
+		}
 
-	var types []databases.MedCustomerType
-	co.DB.Where("is_active = ?", true).Find(&types)
+		var types []databases.MedCustomerType
+		if err := co.DB.Where("is_active = ?", true).Find(&types).Error; err != nil {
+			return nil, err
+		}
 
-	var customers []databases.MedCustomer
-	co.DB.Where("company_registry_number = ?", "").Where("is_active = ?", true).Find(&customers)
+		var customers []databases.MedCustomer
+		if err := co.DB.Where("company_registry_number = ?", "").Where("is_active = ?", true).Find(&customers).Error; err != nil {
+			return nil, err
+		}
 
-	var cities []databases.RefCity
-	co.DB.Where("country_id = ?", 67).Find(&cities)
+		var cities []databases.RefCity
+		if err := co.DB.Where("country_id = ?", services.MongoliaCountryID()).Find(&cities).Error; err != nil {
+			return nil, err
+		}
 
-	response.Classification = classification
-	response.Types = types
-	response.Parents = customers
-	response.Cities = cities
-	response.PaymentTypes = paymentTypes
+		response.Classification = classification
+		response.Types = types
+		response.Parents = customers
+		response.Cities = cities
+		response.PaymentTypes = paymentTypes
 
-	co.SetBody(response)
-	return
+		return response, nil
+	})
 }
 
 // WarehouseItemPriceList get customer reference
@@ -1959,7 +1404,7 @@ func (co CustomerController) WarehouseItemPriceList(c *gin.Context) {
 		Preload("WarehouseItem.Item.Country").
 		Preload("PriceType").
 		Where("is_active = ?", true).
-		Where("price_type_id = ?", 1).
+		Where("price_type_id = ?", services.DefaultPriceTypeID()).
 		Not("sales_price = ?", 0).
 		Where("item_id IN (?)", co.DB.Table("med_warehouse_items").Not("total_qty = ?", 0).Pluck("item_id", &itemIds)).
 		Find(&priceDtl)
@@ -0,0 +1,332 @@
+package user
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	gin "github.com/gin-gonic/gin"
+	"gitlab.com/fibocloud/medtech/gin/controllers/shared"
+	databases "gitlab.com/fibocloud/medtech/gin/databases"
+	form "gitlab.com/fibocloud/medtech/gin/form"
+	structs "gitlab.com/fibocloud/medtech/gin/structs"
+	gorm "gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CustomerAddressController struct
+type CustomerAddressController struct {
+	shared.BaseController
+}
+
+// Init Controller. /customer/:id/... хэлбэр customer-ийн get/:id зэрэг route-тэй
+// gin 1.6.3 дээр зөрчилддөг тул харилцагчаар шүүх route-ууд /customer/:customerId байна.
+func (co CustomerAddressController) Init(router *gin.RouterGroup) {
+	router.GET("/customer/:customerId", co.List)    // List
+	router.POST("/customer/:customerId", co.Create) // Create
+	router.GET("get/:id", co.Get)                   // Show
+	router.PUT("/:id", co.Update)                   // Update
+	router.DELETE("/:id", co.Delete)                // Delete
+	router.POST("/default/:id", co.MarkDefault)     // MarkDefault
+}
+
+// List customerAddress
+// @Summary List customerAddress
+// @Description Addresses of the customer, default addresses first
+// @Tags CustomerAddress
+// @Accept json
+// @Produce json
+// @Param customerId path uint true "customer ID"
+// @Success 200 {object} structs.ResponseBody{body=[]databases.MedCustomerAddress}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /customerAddress/customer/{customerId} [get]
+func (co CustomerAddressController) List(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	var addresses []databases.MedCustomerAddress
+	result := co.DB.
+		Where("customer_id = ?", c.Param("customerId")).
+		Preload("Country").
+		Preload("City").
+		Preload("District").
+		Preload("Street").
+		Preload("AddressType").
+		Order("address_type_id asc").
+		Order("is_default desc").
+		Order("id asc").
+		Find(&addresses)
+	if result.Error != nil {
+		co.SetError(http.StatusInternalServerError, result.Error.Error())
+		return
+	}
+
+	co.SetBody(addresses)
+	return
+}
+
+// Get customerAddress
+// @Summary Get customerAddress
+// @Description Show customerAddress
+// @Tags CustomerAddress
+// @Accept json
+// @Produce json
+// @Param id path uint true "address ID"
+// @Success 200 {object} structs.ResponseBody{body=databases.MedCustomerAddress}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /customerAddress/get/{id} [get]
+func (co CustomerAddressController) Get(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	var address databases.MedCustomerAddress
+	result := co.DB.
+		Preload("Country").
+		Preload("City").
+		Preload("District").
+		Preload("Street").
+		Preload("AddressType").
+		First(&address, c.Param("id"))
+	if result.Error != nil {
+		co.SetError(http.StatusNotFound, result.Error.Error())
+		return
+	}
+
+	co.SetBody(address)
+	return
+}
+
+// Create customerAddress
+// @Summary Create customerAddress
+// @Description Add an address to the customer
+// @Tags CustomerAddress
+// @Accept json
+// @Produce json
+// @Param customerId path uint true "customer ID"
+// @Param address body form.CustomerAddressParams true "address"
+// @Success 200 {object} structs.ResponseBody{body=databases.MedCustomerAddress}
+// @Failure 400 {object} structs.ValidationErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /customerAddress/customer/{customerId} [post]
+func (co CustomerAddressController) Create(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	var params form.CustomerAddressParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		co.SetValidationError(shared.FieldErrors(err))
+		return
+	}
+
+	var customer databases.MedCustomer
+	result := co.DB.First(&customer, c.Param("customerId"))
+	if result.Error != nil {
+		co.SetError(http.StatusNotFound, "Харилцагч олдсонгүй")
+		return
+	}
+
+	authUser := co.GetAuth(c)
+	address := databases.MedCustomerAddress{
+		CustomerID:  customer.Base.ID,
+		IsActive:    true,
+		CreatedUser: &authUser,
+		Base: databases.Base{
+			CreatedDate: time.Now(),
+		},
+	}
+
+	tx := co.DB.Begin()
+	err := saveCustomerAddress(tx, customer, &address, params, authUser)
+	if err != nil {
+		tx.Rollback()
+		co.SetError(http.StatusInternalServerError, "Хаяг байршил нэмэх алдаа гарлаа "+err.Error())
+		return
+	}
+
+	tx.Commit()
+	co.SetBody(address)
+	return
+}
+
+// Update customerAddress
+// @Summary Update customerAddress
+// @Description Edit one address without resending the others
+// @Tags CustomerAddress
+// @Accept json
+// @Produce json
+// @Param id path uint true "address ID"
+// @Param address body form.CustomerAddressParams true "address"
+// @Success 200 {object} structs.ResponseBody{body=databases.MedCustomerAddress}
+// @Failure 400 {object} structs.ValidationErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /customerAddress/{id} [put]
+func (co CustomerAddressController) Update(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	var params form.CustomerAddressParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		co.SetValidationError(shared.FieldErrors(err))
+		return
+	}
+
+	var address databases.MedCustomerAddress
+	result := co.DB.First(&address, c.Param("id"))
+	if result.Error != nil {
+		co.SetError(http.StatusNotFound, result.Error.Error())
+		return
+	}
+
+	var customer databases.MedCustomer
+	result = co.DB.First(&customer, address.CustomerID)
+	if result.Error != nil {
+		co.SetError(http.StatusNotFound, "Харилцагч олдсонгүй")
+		return
+	}
+
+	authUser := co.GetAuth(c)
+	tx := co.DB.Begin()
+	err := saveCustomerAddress(tx, customer, &address, params, authUser)
+	if err != nil {
+		tx.Rollback()
+		co.SetError(http.StatusInternalServerError, "Хаяг байршил засах алдаа гарлаа "+err.Error())
+		return
+	}
+
+	tx.Commit()
+	co.SetBody(address)
+	return
+}
+
+// Delete customerAddress
+// @Summary Delete customerAddress
+// @Description Remove customerAddress
+// @Tags CustomerAddress
+// @Accept json
+// @Produce json
+// @Param id path uint true "address ID"
+// @Success 200 {object} structs.ResponseBody{body=structs.SuccessResponse}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /customerAddress/{id} [delete]
+func (co CustomerAddressController) Delete(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	result := co.DB.Delete(&databases.MedCustomerAddress{}, c.Param("id"))
+	if result.Error != nil {
+		co.SetError(http.StatusInternalServerError, result.Error.Error())
+		return
+	}
+
+	co.SetBody(structs.SuccessResponse{
+		Success: true,
+	})
+	return
+}
+
+// MarkDefault customerAddress
+// @Summary MarkDefault customerAddress
+// @Description Make the address the default delivery address for its address type
+// @Tags CustomerAddress
+// @Accept json
+// @Produce json
+// @Param id path uint true "address ID"
+// @Success 200 {object} structs.ResponseBody{body=structs.SuccessResponse}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /customerAddress/default/{id} [post]
+func (co CustomerAddressController) MarkDefault(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	var address databases.MedCustomerAddress
+	result := co.DB.First(&address, c.Param("id"))
+	if result.Error != nil {
+		co.SetError(http.StatusNotFound, result.Error.Error())
+		return
+	}
+
+	authUser := co.GetAuth(c)
+	tx := co.DB.Begin()
+	err := markDefaultAddress(tx, address, authUser)
+	if err != nil {
+		tx.Rollback()
+		co.SetError(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	tx.Commit()
+	co.SetBody(structs.SuccessResponse{
+		Success: true,
+	})
+	return
+}
+
+// saveCustomerAddress params-аас утга оноож хадгална. Улс, аймаг, дүүрэг хоосон бол харилцагчийнхыг авна.
+func saveCustomerAddress(tx *gorm.DB, customer databases.MedCustomer, address *databases.MedCustomerAddress, params form.CustomerAddressParams, authUser databases.MedSystemUser) error {
+	address.CountryID = params.CountryID
+	if address.CountryID == 0 {
+		address.CountryID = customer.CountryID
+	}
+	address.CityID = params.CityID
+	if address.CityID == 0 {
+		address.CityID = customer.CityID
+	}
+	address.DistrictID = params.DistrictID
+	if address.DistrictID == 0 {
+		address.DistrictID = customer.DistrictID
+	}
+	address.StreetID = params.StreetID
+	address.AddressTypeID = params.AddressTypeID
+	address.Description = strings.TrimSpace(params.Description)
+	address.IsDefault = params.IsDefault
+	address.ModifiedUser = &authUser
+	address.Base.ModifiedDate = time.Now()
+
+	result := tx.Omit("Customer", "Country", "City", "District", "Street", "AddressType").Save(address)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if address.IsDefault {
+		return markDefaultAddress(tx, *address, authUser)
+	}
+	return nil
+}
+
+// markDefaultAddress хаягийн төрөл тус бүрт ганц үндсэн хаяг үлдээнэ
+func markDefaultAddress(tx *gorm.DB, address databases.MedCustomerAddress, authUser databases.MedSystemUser) error {
+	// зэрэг тохируулахад хоёр үндсэн болохоос сэргийлнэ
+	var customer databases.MedCustomer
+	result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&customer, address.CustomerID)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	result = tx.Model(&databases.MedCustomerAddress{}).
+		Where("customer_id = ?", address.CustomerID).
+		Where("address_type_id = ?", address.AddressTypeID).
+		Where("id <> ?", address.Base.ID).
+		Where("is_default = ?", true).
+		Updates(map[string]interface{}{
+			"is_default":       false,
+			"modified_date":    time.Now(),
+			"modified_user_id": authUser.Base.ID,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	return tx.Model(&databases.MedCustomerAddress{}).
+		Where("id = ?", address.Base.ID).
+		Update("is_default", true).Error
+}
@@ -9,6 +9,7 @@ import (
 	shared "gitlab.com/fibocloud/medtech/gin/controllers/shared"
 	databases "gitlab.com/fibocloud/medtech/gin/databases"
 	form "gitlab.com/fibocloud/medtech/gin/form"
+	services "gitlab.com/fibocloud/medtech/gin/services"
 	structs "gitlab.com/fibocloud/medtech/gin/structs"
 )
 
@@ -25,11 +26,14 @@ type ListCustomerClassification struct {
 
 // Init Controller
 func (co CustomerClassificationController) Init(router *gin.RouterGroup) {
-	router.POST("/list", co.List) // List
-	router.GET("get/:id", co.Get) // Show
-	router.POST("", co.Create)    // Create
-	router.PUT("/:id", co.Update) // Update
-	router.DELETE("", co.Delete)  // Delete
+	router.POST("/list", co.List)                 // List
+	router.GET("get/:id", co.Get)                 // Show
+	router.POST("", co.Create)                    // Create
+	router.PUT("/:id", co.Update)                 // Update
+	router.DELETE("", co.Delete)                  // Delete
+	router.GET("/score/preview", co.ScorePreview) // ScorePreview
+	router.POST("/score/run", co.ScoreRun)        // ScoreRun
+	router.GET("/score/history", co.ScoreHistory) // ScoreHistory
 }
 
 // List customerClassification
@@ -39,6 +43,7 @@ func (co CustomerClassificationController) Init(router *gin.RouterGroup) {
 // @Accept json
 // @Produce json
 // @Param filter body form.ClassFilter true "filter"
+// @Param lang query string false "locale, Accept-Language when empty"
 // @Success 200 {object} structs.ResponseBody{body=[]databases.MedCustomerClassification}
 // @Failure 400 {object} structs.ErrorResponse
 // @Failure 500 {object} structs.ErrorResponse
@@ -67,6 +72,7 @@ func (co CustomerClassificationController) List(c *gin.Context) {
 
 	var customerClassifications []databases.MedCustomerClassification
 	db.Find(&customerClassifications)
+	co.Localize(c, &customerClassifications)
 
 	co.DB.Table("med_customer_classifications").Count(&count)
 
@@ -84,6 +90,7 @@ func (co CustomerClassificationController) List(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path uint true "customerClassification ID"
+// @Param lang query string false "locale, Accept-Language when empty"
 // @Success 200 {object} structs.ResponseBody{body=databases.MedCustomerClassification}
 // @Failure 400 {object} structs.ErrorResponse
 // @Failure 500 {object} structs.ErrorResponse
@@ -100,6 +107,7 @@ func (co CustomerClassificationController) Get(c *gin.Context) {
 		return
 	}
 
+	co.Localize(c, &customerClassification)
 	co.SetBody(customerClassification)
 	return
 }
@@ -137,7 +145,7 @@ func (co CustomerClassificationController) GetCode(c *gin.Context) {
 // @Tags CustomerClassification
 // @Accept json
 // @Produce json
-// @Param customerClassification body form.SpecificationCreateParams true "customerClassification"
+// @Param customerClassification body form.ClassificationCreateParams true "customerClassification"
 // @Success 200 {object} structs.ResponseBody{body=structs.SuccessResponse}
 // @Failure 400 {object} structs.ErrorResponse
 // @Failure 500 {object} structs.ErrorResponse
@@ -147,20 +155,31 @@ func (co CustomerClassificationController) Create(c *gin.Context) {
 		c.JSON(co.GetBody())
 	}()
 
-	var params form.SpecificationCreateParams
+	var params form.ClassificationCreateParams
 	if err := c.ShouldBindJSON(&params); err != nil {
 		co.SetError(http.StatusBadRequest, err.Error())
 		return
 	}
 
+	if err := services.CheckRefCode(co.DB, services.TableClassifications, 0, params.Code); err != nil {
+		co.SetError(http.StatusBadRequest, err.Error())
+		return
+	}
+
 	authUser := co.GetAuth(c)
 
 	customerClassification := databases.MedCustomerClassification{
-		Name:         params.Name,
-		IsActive:     params.IsActive,
-		Description:  params.Description,
-		CreatedUser:  &authUser,
-		ModifiedUser: &authUser,
+		Name:          params.Name,
+		Code:          params.Code,
+		IsActive:      params.IsActive,
+		Description:   params.Description,
+		Rank:          params.Rank,
+		MinPurchase:   params.MinPurchase,
+		MinOrderCount: params.MinOrderCount,
+		MaxAvgPayDays: params.MaxAvgPayDays,
+		IsAutoAssign:  params.IsAutoAssign,
+		CreatedUser:   &authUser,
+		ModifiedUser:  &authUser,
 		Base: databases.Base{
 			CreatedDate: time.Now(),
 		},
@@ -185,7 +204,7 @@ func (co CustomerClassificationController) Create(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path uint true "customerClassification ID"
-// @Param customerClassification body form.SpecificationUpdateParams true "customerClassification"
+// @Param customerClassification body form.ClassificationUpdateParams true "customerClassification"
 // @Success 200 {object} structs.ResponseBody{body=structs.SuccessResponse}
 // @Failure 400 {object} structs.ErrorResponse
 // @Failure 500 {object} structs.ErrorResponse
@@ -195,7 +214,7 @@ func (co CustomerClassificationController) Update(c *gin.Context) {
 		c.JSON(co.GetBody())
 	}()
 
-	var params form.SpecificationUpdateParams
+	var params form.ClassificationUpdateParams
 	if err := c.ShouldBindJSON(&params); err != nil {
 		co.SetError(http.StatusBadRequest, err.Error())
 		return
@@ -208,11 +227,22 @@ func (co CustomerClassificationController) Update(c *gin.Context) {
 		return
 	}
 
+	if err := services.CheckRefCode(co.DB, services.TableClassifications, customerClassification.Base.ID, params.Code); err != nil {
+		co.SetError(http.StatusBadRequest, err.Error())
+		return
+	}
+
 	authUser := co.GetAuth(c)
 
 	customerClassification.Name = params.Name
+	customerClassification.Code = params.Code
 	customerClassification.IsActive = params.IsActive
 	customerClassification.Description = params.Description
+	customerClassification.Rank = params.Rank
+	customerClassification.MinPurchase = params.MinPurchase
+	customerClassification.MinOrderCount = params.MinOrderCount
+	customerClassification.MaxAvgPayDays = params.MaxAvgPayDays
+	customerClassification.IsAutoAssign = params.IsAutoAssign
 	customerClassification.Base.ModifiedDate = time.Now()
 	customerClassification.ModifiedUser = &authUser
 
@@ -250,6 +280,13 @@ func (co CustomerClassificationController) Delete(c *gin.Context) {
 		return
 	}
 
+	for _, v := range params.IDs {
+		if services.RegisteredRef(services.TableClassifications, v) {
+			co.SetError(http.StatusBadRequest, services.ErrRefCodeRequired.Error())
+			return
+		}
+	}
+
 	for _, v := range params.IDs {
 		result := co.DB.Delete(&databases.MedCustomerClassification{}, v)
 		if result.Error != nil {
@@ -264,3 +301,88 @@ func (co CustomerClassificationController) Delete(c *gin.Context) {
 
 	return
 }
+
+// ScorePreview customerClassification
+// @Summary ScorePreview customerClassification
+// @Description Customers that would move to another classification, including rules that are not active yet
+// @Tags CustomerClassification
+// @Accept json
+// @Produce json
+// @Success 200 {object} structs.ResponseBody{body=[]services.ClassificationChange}
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /customerClassification/score/preview [get]
+func (co CustomerClassificationController) ScorePreview(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	changes, err := services.ReclassifyCustomers(co.DB, true, co.GetAuth(c))
+	if err != nil {
+		co.SetError(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	co.SetBody(changes)
+	return
+}
+
+// ScoreRun customerClassification
+// @Summary ScoreRun customerClassification
+// @Description Reclassify customers now with the active rules, as the nightly job does
+// @Tags CustomerClassification
+// @Accept json
+// @Produce json
+// @Success 200 {object} structs.ResponseBody{body=[]services.ClassificationChange}
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /customerClassification/score/run [post]
+func (co CustomerClassificationController) ScoreRun(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	changes, err := services.ReclassifyCustomers(co.DB, false, co.GetAuth(c))
+	if err != nil {
+		co.SetError(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	co.SetBody(changes)
+	return
+}
+
+// ScoreHistory customerClassification
+// @Summary ScoreHistory customerClassification
+// @Description Automatic classification changes, newest first
+// @Tags CustomerClassification
+// @Accept json
+// @Produce json
+// @Param customer_id query int false "customer ID"
+// @Success 200 {object} structs.ResponseBody{body=[]databases.MedCustomerClassificationLog}
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /customerClassification/score/history [get]
+func (co CustomerClassificationController) ScoreHistory(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	db := co.DB
+	if customerID := c.Query("customer_id"); customerID != "" {
+		db = db.Where("customer_id = ?", customerID)
+	}
+
+	var logs []databases.MedCustomerClassificationLog
+	result := db.
+		Preload("Customer").
+		Preload("OldClassification").
+		Preload("NewClassification").
+		Preload("CreatedUser.Person").
+		Order("created_date desc").
+		Find(&logs)
+	if result.Error != nil {
+		co.SetError(http.StatusInternalServerError, result.Error.Error())
+		return
+	}
+
+	co.SetBody(logs)
+	return
+}
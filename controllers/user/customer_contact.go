@@ -0,0 +1,306 @@
+package user
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	gin "github.com/gin-gonic/gin"
+	"gitlab.com/fibocloud/medtech/gin/controllers/shared"
+	databases "gitlab.com/fibocloud/medtech/gin/databases"
+	form "gitlab.com/fibocloud/medtech/gin/form"
+	structs "gitlab.com/fibocloud/medtech/gin/structs"
+	gorm "gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CustomerContactController struct
+type CustomerContactController struct {
+	shared.BaseController
+}
+
+// Init Controller. /customer/:id/... хэлбэр customer-ийн get/:id зэрэг route-тэй
+// gin 1.6.3 дээр зөрчилддөг тул харилцагчаар шүүх route-ууд /customer/:customerId байна.
+func (co CustomerContactController) Init(router *gin.RouterGroup) {
+	router.GET("/customer/:customerId", co.List)    // List
+	router.POST("/customer/:customerId", co.Create) // Create
+	router.GET("get/:id", co.Get)                   // Show
+	router.PUT("/:id", co.Update)                   // Update
+	router.DELETE("/:id", co.Delete)                // Delete
+	router.POST("/primary/:id", co.MarkPrimary)     // MarkPrimary
+}
+
+// List customerContact
+// @Summary List customerContact
+// @Description Contacts of the customer, primary first
+// @Tags CustomerContact
+// @Accept json
+// @Produce json
+// @Param customerId path uint true "customer ID"
+// @Success 200 {object} structs.ResponseBody{body=[]databases.MedCustomerContacts}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /customerContact/customer/{customerId} [get]
+func (co CustomerContactController) List(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	var contacts []databases.MedCustomerContacts
+	result := co.DB.
+		Where("customer_id = ?", c.Param("customerId")).
+		Preload("Position").
+		Order("is_primary desc").
+		Order("id asc").
+		Find(&contacts)
+	if result.Error != nil {
+		co.SetError(http.StatusInternalServerError, result.Error.Error())
+		return
+	}
+
+	co.SetBody(contacts)
+	return
+}
+
+// Get customerContact
+// @Summary Get customerContact
+// @Description Show customerContact
+// @Tags CustomerContact
+// @Accept json
+// @Produce json
+// @Param id path uint true "contact ID"
+// @Success 200 {object} structs.ResponseBody{body=databases.MedCustomerContacts}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /customerContact/get/{id} [get]
+func (co CustomerContactController) Get(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	var contact databases.MedCustomerContacts
+	result := co.DB.Preload("Position").First(&contact, c.Param("id"))
+	if result.Error != nil {
+		co.SetError(http.StatusNotFound, result.Error.Error())
+		return
+	}
+
+	co.SetBody(contact)
+	return
+}
+
+// Create customerContact
+// @Summary Create customerContact
+// @Description Add a contact to the customer
+// @Tags CustomerContact
+// @Accept json
+// @Produce json
+// @Param customerId path uint true "customer ID"
+// @Param contact body form.CustomerContactParams true "contact"
+// @Success 200 {object} structs.ResponseBody{body=databases.MedCustomerContacts}
+// @Failure 400 {object} structs.ValidationErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /customerContact/customer/{customerId} [post]
+func (co CustomerContactController) Create(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	var params form.CustomerContactParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		co.SetValidationError(shared.FieldErrors(err))
+		return
+	}
+
+	var customer databases.MedCustomer
+	result := co.DB.First(&customer, c.Param("customerId"))
+	if result.Error != nil {
+		co.SetError(http.StatusNotFound, "Харилцагч олдсонгүй")
+		return
+	}
+
+	authUser := co.GetAuth(c)
+	contact := databases.MedCustomerContacts{
+		CustomerID:  customer.Base.ID,
+		IsActive:    true,
+		CreatedUser: &authUser,
+		Base: databases.Base{
+			CreatedDate: time.Now(),
+		},
+	}
+
+	tx := co.DB.Begin()
+	err := saveCustomerContact(tx, &contact, params, authUser)
+	if err != nil {
+		tx.Rollback()
+		co.SetError(http.StatusInternalServerError, "Холбоо барих алдаа гарлаа "+err.Error())
+		return
+	}
+
+	tx.Commit()
+	co.SetBody(contact)
+	return
+}
+
+// Update customerContact
+// @Summary Update customerContact
+// @Description Edit one contact without resending the others
+// @Tags CustomerContact
+// @Accept json
+// @Produce json
+// @Param id path uint true "contact ID"
+// @Param contact body form.CustomerContactParams true "contact"
+// @Success 200 {object} structs.ResponseBody{body=databases.MedCustomerContacts}
+// @Failure 400 {object} structs.ValidationErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /customerContact/{id} [put]
+func (co CustomerContactController) Update(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	var params form.CustomerContactParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		co.SetValidationError(shared.FieldErrors(err))
+		return
+	}
+
+	var contact databases.MedCustomerContacts
+	result := co.DB.First(&contact, c.Param("id"))
+	if result.Error != nil {
+		co.SetError(http.StatusNotFound, result.Error.Error())
+		return
+	}
+
+	authUser := co.GetAuth(c)
+	tx := co.DB.Begin()
+	err := saveCustomerContact(tx, &contact, params, authUser)
+	if err != nil {
+		tx.Rollback()
+		co.SetError(http.StatusInternalServerError, "Холбоо барих алдаа гарлаа "+err.Error())
+		return
+	}
+
+	tx.Commit()
+	co.SetBody(contact)
+	return
+}
+
+// Delete customerContact
+// @Summary Delete customerContact
+// @Description Remove customerContact
+// @Tags CustomerContact
+// @Accept json
+// @Produce json
+// @Param id path uint true "contact ID"
+// @Success 200 {object} structs.ResponseBody{body=structs.SuccessResponse}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /customerContact/{id} [delete]
+func (co CustomerContactController) Delete(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	result := co.DB.Delete(&databases.MedCustomerContacts{}, c.Param("id"))
+	if result.Error != nil {
+		co.SetError(http.StatusInternalServerError, result.Error.Error())
+		return
+	}
+
+	co.SetBody(structs.SuccessResponse{
+		Success: true,
+	})
+	return
+}
+
+// MarkPrimary customerContact
+// @Summary MarkPrimary customerContact
+// @Description Make the contact the customer's primary contact
+// @Tags CustomerContact
+// @Accept json
+// @Produce json
+// @Param id path uint true "contact ID"
+// @Success 200 {object} structs.ResponseBody{body=structs.SuccessResponse}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /customerContact/primary/{id} [post]
+func (co CustomerContactController) MarkPrimary(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	var contact databases.MedCustomerContacts
+	result := co.DB.First(&contact, c.Param("id"))
+	if result.Error != nil {
+		co.SetError(http.StatusNotFound, result.Error.Error())
+		return
+	}
+
+	authUser := co.GetAuth(c)
+	tx := co.DB.Begin()
+	err := markPrimaryContact(tx, contact, authUser)
+	if err != nil {
+		tx.Rollback()
+		co.SetError(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	tx.Commit()
+	co.SetBody(structs.SuccessResponse{
+		Success: true,
+	})
+	return
+}
+
+// saveCustomerContact params-аас утга оноож хадгална, үндсэн бол бусдыг нь болиулна
+func saveCustomerContact(tx *gorm.DB, contact *databases.MedCustomerContacts, params form.CustomerContactParams, authUser databases.MedSystemUser) error {
+	contact.LastName = strings.TrimSpace(params.LastName)
+	contact.FirstName = strings.TrimSpace(params.FirstName)
+	contact.RegisterNumber = strings.TrimSpace(params.RegisterNumber)
+	contact.PositionID = params.PositionID
+	contact.PhoneNumber1 = strings.TrimSpace(params.PhoneNumber1)
+	contact.PhoneNumber2 = strings.TrimSpace(params.PhoneNumber2)
+	contact.Email1 = strings.TrimSpace(params.Email1)
+	contact.Email2 = strings.TrimSpace(params.Email2)
+	contact.IsPrimary = params.IsPrimary
+	contact.ModifiedUser = &authUser
+	contact.Base.ModifiedDate = time.Now()
+
+	result := tx.Omit("Customer", "Position").Save(contact)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if contact.IsPrimary {
+		return markPrimaryContact(tx, *contact, authUser)
+	}
+	return nil
+}
+
+// markPrimaryContact харилцагчийн ганц үндсэн холбоо барих хүнийг тохируулна
+func markPrimaryContact(tx *gorm.DB, contact databases.MedCustomerContacts, authUser databases.MedSystemUser) error {
+	// зэрэг тохируулахад хоёр үндсэн болохоос сэргийлнэ
+	var customer databases.MedCustomer
+	result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&customer, contact.CustomerID)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	result = tx.Model(&databases.MedCustomerContacts{}).
+		Where("customer_id = ?", contact.CustomerID).
+		Where("id <> ?", contact.Base.ID).
+		Where("is_primary = ?", true).
+		Updates(map[string]interface{}{
+			"is_primary":       false,
+			"modified_date":    time.Now(),
+			"modified_user_id": authUser.Base.ID,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	return tx.Model(&databases.MedCustomerContacts{}).
+		Where("id = ?", contact.Base.ID).
+		Update("is_primary", true).Error
+}
@@ -0,0 +1,247 @@
+package user
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"time"
+
+	gin "github.com/gin-gonic/gin"
+	"gitlab.com/fibocloud/medtech/gin/controllers/shared"
+	databases "gitlab.com/fibocloud/medtech/gin/databases"
+	form "gitlab.com/fibocloud/medtech/gin/form"
+	services "gitlab.com/fibocloud/medtech/gin/services"
+	gorm "gorm.io/gorm"
+)
+
+// ListCustomerPayments ...
+type ListCustomerPayments struct {
+	Total int64                          `json:"total"`
+	List  []databases.MedCustomerPayment `json:"list"`
+}
+
+// CreditCheck customer
+// @Summary CreditCheck customer
+// @Description Check an order amount against the customer's one-time, receivables and purchase limits.
+// @Description Preview only; orders and outcomes are enforced when their workflow is started.
+// @Tags Customer
+// @Accept json
+// @Produce json
+// @Param check body form.CustomerCreditCheckParams true "check"
+// @Success 200 {object} structs.ResponseBody{body=services.CreditCheck}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /customer/credit/check [post]
+func (co CustomerController) CreditCheck(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	var params form.CustomerCreditCheckParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		co.SetError(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	check, err := services.CheckCredit(co.DB, params.CustomerID, params.Amount.Decimal)
+	if err != nil {
+		co.setCreditError(err)
+		return
+	}
+
+	co.SetBody(check)
+	return
+}
+
+// CreditOverride customer
+// @Summary CreditOverride customer
+// @Description Manager approval for a saved order over the customer's credit limit, recorded in the status log.
+// @Description Used for orders accepted in flag mode; in reject mode the override is passed when the order's workflow starts.
+// @Description Customer and amount including VAT are read from the order.
+// @Tags Customer
+// @Accept json
+// @Produce json
+// @Param override body form.CustomerCreditOverrideParams true "override"
+// @Success 200 {object} structs.ResponseBody{body=services.CreditCheck}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 403 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /customer/credit/override [post]
+func (co CustomerController) CreditOverride(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	var params form.CustomerCreditOverrideParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		co.SetError(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	authUser := co.GetAuth(c)
+	override := services.CreditOverride{Reason: params.Reason}
+
+	tx := co.DB.Begin()
+	customerID, amount, err := services.DocumentCreditAmount(tx, services.DocumentOrderBook, params.OrderBookID)
+	if err != nil {
+		tx.Rollback()
+		co.setCreditError(err)
+		return
+	}
+
+	check, err := services.EnforceCredit(tx, customerID, amount, &override, authUser)
+	if err != nil {
+		tx.Rollback()
+		co.setCreditError(err)
+		return
+	}
+
+	if check.Exceeded {
+		err = services.RecordCreditOverride(tx, services.DocumentOrderBook, params.OrderBookID, check, override, authUser)
+		if err != nil {
+			tx.Rollback()
+			co.setCreditError(err)
+			return
+		}
+	}
+
+	tx.Commit()
+	co.SetBody(check)
+	return
+}
+
+// ListPayments customer
+// @Summary ListPayments customer
+// @Description Payments received from customers
+// @Tags Customer
+// @Accept json
+// @Produce json
+// @Param filter body form.CustomerPaymentFilter true "filter"
+// @Success 200 {object} structs.ResponseBody{body=ListCustomerPayments}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /customer/payment/list [post]
+func (co CustomerController) ListPayments(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	var count int64
+	var params form.CustomerPaymentFilter
+	if err := c.ShouldBindJSON(&params); err != nil {
+		co.SetError(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	db := co.DB
+
+	// filter hiij bgaa heseg
+	v := reflect.ValueOf(params.Filter)
+
+	db = db.Scopes(shared.TableSearch(v, params.Sort))
+	db = db.Scopes(shared.Paginate(params.Page, params.Size))
+
+	var listRepsonse ListCustomerPayments
+
+	var payments []databases.MedCustomerPayment
+	db.Preload("Customer").Preload("PaymentMethod").Find(&payments)
+
+	db.Table("med_customer_payments").Count(&count)
+
+	listRepsonse.List = payments
+	listRepsonse.Total = count
+
+	co.SetBody(listRepsonse)
+	return
+}
+
+// CreatePayment customer
+// @Summary CreatePayment customer
+// @Description Record a payment received from a customer
+// @Tags Customer
+// @Accept json
+// @Produce json
+// @Param payment body form.CustomerPaymentParams true "payment"
+// @Success 200 {object} structs.ResponseBody{body=databases.MedCustomerPayment}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /customer/payment [post]
+func (co CustomerController) CreatePayment(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	var params form.CustomerPaymentParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		co.SetError(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var customer databases.MedCustomer
+	result := co.DB.First(&customer, params.CustomerID)
+	if result.Error != nil {
+		co.SetError(http.StatusNotFound, "Харилцагч олдсонгүй")
+		return
+	}
+
+	currency, err := services.ResolveDocumentCurrency(co.DB, params.ValuteID, params.PaymentDate, params.Rate.Decimal)
+	if err != nil {
+		co.setCurrencyError(err)
+		return
+	}
+	transactionAmount, baseAmount := services.DocumentAmounts(currency, params.Amount.Decimal)
+
+	authUser := co.GetAuth(c)
+	payment := databases.MedCustomerPayment{
+		CustomerID:        params.CustomerID,
+		OutcomeID:         params.OutcomeID,
+		PaymentMethodID:   params.PaymentMethodID,
+		DocumentCurrency:  currency,
+		TransactionAmount: transactionAmount.Amount,
+		Amount:            baseAmount.Amount,
+		PaymentDate:       params.PaymentDate,
+		Description:       params.Description,
+		CreatedUser:       &authUser,
+		ModifiedUser:      &authUser,
+		Base: databases.Base{
+			CreatedDate: time.Now(),
+		},
+	}
+
+	result = co.DB.Create(&payment)
+	if result.Error != nil {
+		co.SetError(http.StatusInternalServerError, result.Error.Error())
+		return
+	}
+
+	co.SetBody(payment)
+	return
+}
+
+// setCurrencyError валют, ханшийн алдааг http код руу хөрвүүлнэ
+func (co CustomerController) setCurrencyError(err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		co.SetError(http.StatusNotFound, "Валют олдсонгүй")
+	case errors.Is(err, services.ErrRateNotFound),
+		errors.Is(err, services.ErrRateMismatch):
+		co.SetError(http.StatusBadRequest, err.Error())
+	default:
+		co.SetError(http.StatusInternalServerError, err.Error())
+	}
+}
+
+func (co CustomerController) setCreditError(err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		co.SetError(http.StatusNotFound, "Захиалга, харилцагч олдсонгүй")
+	case errors.Is(err, services.ErrCreditOverrideRole):
+		co.SetError(http.StatusForbidden, err.Error())
+	case errors.Is(err, services.ErrCreditExceeded),
+		errors.Is(err, services.ErrCreditOverrideReason),
+		errors.Is(err, services.ErrUnknownDocument):
+		co.SetError(http.StatusBadRequest, err.Error())
+	default:
+		co.SetError(http.StatusInternalServerError, err.Error())
+	}
+}
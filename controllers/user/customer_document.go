@@ -0,0 +1,284 @@
+package user
+
+import (
+	"context"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	gin "github.com/gin-gonic/gin"
+	"github.com/minio/minio-go/v7"
+	"gitlab.com/fibocloud/medtech/gin/constracts"
+	databases "gitlab.com/fibocloud/medtech/gin/databases"
+	services "gitlab.com/fibocloud/medtech/gin/services"
+	gorm "gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RequiredDocuments харилцагчаас заавал авах бичиг баримтын төрлүүд
+func RequiredDocuments() []uint {
+	return []uint{
+		services.ContentTypeID(constracts.ContentLicence),
+		services.ContentTypeID(constracts.ContentCertification),
+		services.ContentTypeID(constracts.ContentDirectorCards),
+	}
+}
+
+// UploadDocument customer
+// @Summary UploadDocument customer
+// @Description Upload a new version of a customer document, superseding the current one
+// @Tags Customer
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path uint true "customer ID"
+// @Param content_type_id formData int true "content type ID"
+// @Param description formData string false "description"
+// @Param files formData file true "files"
+// @Success 200 {object} structs.ResponseBody{body=[]databases.MedCustomerDocument}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /customer/document/upload/{id} [post]
+func (co CustomerController) UploadDocument(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	contentTypeID, err := strconv.Atoi(c.PostForm("content_type_id"))
+	if err != nil || contentTypeID == 0 {
+		co.SetError(http.StatusBadRequest, "Баримтын төрөл сонгоно уу")
+		return
+	}
+
+	formdata, err := c.MultipartForm()
+	if err != nil || len(formdata.File["files"]) == 0 {
+		co.SetError(http.StatusBadRequest, "Файл оруулна уу")
+		return
+	}
+
+	var customer databases.MedCustomer
+	result := co.DB.First(&customer, c.Param("id"))
+	if result.Error != nil {
+		co.SetError(http.StatusNotFound, result.Error.Error())
+		return
+	}
+
+	var contentType databases.MedContentType
+	result = co.DB.First(&contentType, contentTypeID)
+	if result.Error != nil {
+		co.SetError(http.StatusBadRequest, "Баримтын төрөл олдсонгүй")
+		return
+	}
+
+	authUser := co.GetAuth(c)
+	bucketName := co.documentBucket(customer)
+	tx := co.DB.Begin()
+
+	var contents []*databases.MedContent
+	for _, fileHeader := range formdata.File["files"] {
+		content, errUpload := co.uploadContent(bucketName, customer.Name+"/", fileHeader, uint(contentTypeID), authUser)
+		if errUpload != nil {
+			tx.Rollback()
+			co.SetError(http.StatusInternalServerError, "Файлын санруу хуулах үед алдаа гарлаа "+errUpload.Error())
+			return
+		}
+
+		result = tx.Create(content)
+		if result.Error != nil {
+			tx.Rollback()
+			co.SetError(http.StatusInternalServerError, "Файл датабайзруу хуулах үед алдаа гарлаа "+result.Error.Error())
+			return
+		}
+
+		contents = append(contents, content)
+	}
+
+	documents, err := co.saveDocumentVersion(tx, customer.Base.ID, uint(contentTypeID), contents, c.PostForm("description"), authUser)
+	if err != nil {
+		tx.Rollback()
+		co.SetError(http.StatusInternalServerError, "Баримтын хувилбар хадгалах үед алдаа гарлаа "+err.Error())
+		return
+	}
+
+	err = tx.Model(&customer).Association("Files").Append(contents)
+	if err != nil {
+		tx.Rollback()
+		co.SetError(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	tx.Commit()
+	co.SetBody(documents)
+	return
+}
+
+// DocumentHistory customer
+// @Summary DocumentHistory customer
+// @Description List every version of the customer documents, newest first
+// @Tags Customer
+// @Accept json
+// @Produce json
+// @Param id path uint true "customer ID"
+// @Param content_type_id query int false "content type ID"
+// @Success 200 {object} structs.ResponseBody{body=[]databases.MedCustomerDocument}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /customer/document/history/{id} [get]
+func (co CustomerController) DocumentHistory(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	db := co.DB.Where("customer_id = ?", c.Param("id"))
+	if contentTypeID := c.Query("content_type_id"); contentTypeID != "" {
+		db = db.Where("content_type_id = ?", contentTypeID)
+	}
+
+	var documents []databases.MedCustomerDocument
+	result := db.
+		Preload("Content").
+		Preload("ContentType").
+		Preload("CreatedUser.Person").
+		Order("content_type_id asc").
+		Order("version desc").
+		Find(&documents)
+	if result.Error != nil {
+		co.SetError(http.StatusInternalServerError, result.Error.Error())
+		return
+	}
+
+	co.SetBody(documents)
+	return
+}
+
+// documentBucket харилцагчийн файл хадгалах bucket
+func (co CustomerController) documentBucket(customer databases.MedCustomer) string {
+	if customer.CompanyRegistryNumber != "" {
+		return customer.CompanyRegistryNumber
+	}
+
+	if customer.ParentID != 0 {
+		var parent databases.MedCustomer
+		result := co.DB.First(&parent, customer.ParentID)
+		if result.Error == nil && parent.CompanyRegistryNumber != "" {
+			return parent.CompanyRegistryNumber
+		}
+	}
+
+	return customer.Code
+}
+
+// uploadContent файлыг minio руу хуулаад MedContent бэлдэнэ
+func (co CustomerController) uploadContent(bucketName, prefix string, fileHeader *multipart.FileHeader, contentTypeID uint, authUser databases.MedSystemUser) (*databases.MedContent, error) {
+	minioClient := co.MinioClinet()
+
+	exists, err := minioClient.BucketExists(context.Background(), bucketName)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		err = minioClient.MakeBucket(context.Background(), bucketName, minio.MakeBucketOptions{})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	openFile, err := fileHeader.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer openFile.Close()
+
+	fileName := prefix + time.Now().Format("20060102150405") + " - " + fileHeader.Filename
+	uploadInfo, err := minioClient.PutObject(context.Background(), bucketName, fileName, openFile, fileHeader.Size, minio.PutObjectOptions{ContentType: fileHeader.Header.Get("Content-Type")})
+	if err != nil {
+		return nil, err
+	}
+
+	return &databases.MedContent{
+		FileName:      fileName,
+		PhysicalPath:  uploadInfo.Bucket + "/" + uploadInfo.Key,
+		ContentTypeID: contentTypeID,
+		FileSize:      float64(fileHeader.Size),
+		Extention:     filepath.Ext(fileHeader.Filename),
+		CreatedUser:   &authUser,
+		Base: databases.Base{
+			CreatedDate: time.Now(),
+		},
+	}, nil
+}
+
+// saveDocumentVersion тухайн төрлийн хүчинтэй баримтыг орлуулж шинэ хувилбар бүртгэнэ.
+// Нэг удаа хуулсан файлууд (үнэмлэхний 2 тал гэх мэт) нэг хувилбарт багтана.
+func (co CustomerController) saveDocumentVersion(tx *gorm.DB, customerID, contentTypeID uint, contents []*databases.MedContent, description string, authUser databases.MedSystemUser) ([]*databases.MedCustomerDocument, error) {
+	if len(contents) == 0 {
+		return nil, nil
+	}
+
+	// нэг харилцагч дээр зэрэг хуулахад хувилбарын дугаар давхцахгүй байх
+	var customer databases.MedCustomer
+	result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&customer, customerID)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	var current databases.MedCustomerDocument
+	result = tx.
+		Where("customer_id = ?", customerID).
+		Where("content_type_id = ?", contentTypeID).
+		Order("version desc").
+		Limit(1).
+		Find(&current)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	version := current.Version + 1
+
+	var documents []*databases.MedCustomerDocument
+	var documentIDs []uint
+	for _, content := range contents {
+		document := databases.MedCustomerDocument{
+			CustomerID:    customerID,
+			ContentTypeID: contentTypeID,
+			ContentID:     content.Base.ID,
+			Content:       content,
+			Version:       version,
+			IsCurrent:     true,
+			Description:   description,
+			CreatedUser:   &authUser,
+			ModifiedUser:  &authUser,
+			Base: databases.Base{
+				CreatedDate: time.Now(),
+			},
+		}
+
+		result = tx.Omit("Content").Create(&document)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+
+		documents = append(documents, &document)
+		documentIDs = append(documentIDs, document.Base.ID)
+	}
+
+	now := time.Now()
+	result = tx.Model(&databases.MedCustomerDocument{}).
+		Where("customer_id = ?", customerID).
+		Where("content_type_id = ?", contentTypeID).
+		Where("is_current = ?", true).
+		Where("version < ?", version).
+		Updates(map[string]interface{}{
+			"is_current":       false,
+			"superseded_by_id": documentIDs[0],
+			"superseded_date":  now,
+			"modified_date":    now,
+			"modified_user_id": authUser.Base.ID,
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return documents, nil
+}
@@ -0,0 +1,265 @@
+package user
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	gin "github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	shared "gitlab.com/fibocloud/medtech/gin/controllers/shared"
+	form "gitlab.com/fibocloud/medtech/gin/form"
+	services "gitlab.com/fibocloud/medtech/gin/services"
+	structs "gitlab.com/fibocloud/medtech/gin/structs"
+	utils "gitlab.com/fibocloud/medtech/gin/utils"
+)
+
+// CustomerImportRowError импортын мөрийн алдаа
+type CustomerImportRowError struct {
+	Row int `json:"row"` // Файл дахь мөрийн дугаар, толгойг оруулаад
+	structs.FieldError
+}
+
+// CustomerImportRow бүртгэгдсэн (dry-run үед бүртгэгдэх байсан) харилцагч
+type CustomerImportRow struct {
+	Row  int    `json:"row"`
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+// CustomerImportReport импортын үр дүн
+type CustomerImportReport struct {
+	DryRun    bool                     `json:"dry_run"`
+	Committed bool                     `json:"committed"` // Бүх мөр амжилттай, хадгалагдсан эсэх
+	Total     int                      `json:"total"`
+	Customers []CustomerImportRow      `json:"customers"`
+	Errors    []CustomerImportRowError `json:"errors"`
+}
+
+// Import customer
+// @Summary Import customer
+// @Description Bulk import customers with a contact and an address per row from a csv or xlsx file.
+// @Description Columns: name, description, country, company_rd, company_name, city, district, address, address_type_id,
+// @Description payment_type_id, classification_id, customer_types (comma separated), maximum_purchase, maximum_receivables,
+// @Description one_time_purchase_limit, contact_last_name, contact_first_name, contact_phone, contact_phone2, contact_email, contact_position_id.
+// @Description Nothing is saved unless every row is valid; dry_run validates without saving.
+// @Tags Customer
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "csv, xlsx"
+// @Param dry_run formData bool false "validate only"
+// @Success 200 {object} structs.ResponseBody{body=CustomerImportReport}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /customer/import [post]
+func (co CustomerController) Import(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		co.SetError(http.StatusBadRequest, "Файл оруулна уу")
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		co.SetError(http.StatusBadRequest, err.Error())
+		return
+	}
+	defer file.Close()
+
+	rows, err := utils.ReadSpreadsheet(file, fileHeader.Filename)
+	if err != nil {
+		co.SetError(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	lookup, err := services.NewRefLookup(co.DB)
+	if err != nil {
+		co.SetError(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	report := CustomerImportReport{
+		DryRun:    c.PostForm("dry_run") == "true",
+		Customers: []CustomerImportRow{},
+		Errors:    []CustomerImportRowError{},
+	}
+	rowError := func(row int, fields ...structs.FieldError) {
+		for _, field := range fields {
+			report.Errors = append(report.Errors, CustomerImportRowError{Row: row, FieldError: field})
+		}
+	}
+
+	// шалгалт, регистрийн лавлагааг transaction-аас гадуур хийнэ
+	type importRow struct {
+		number  int
+		params  form.CustomerCreateParams
+		company *services.CompanyInfo
+	}
+	var prepared []importRow
+	companies := map[string]*services.CompanyInfo{}
+	for i, row := range rows {
+		// толгой 1-р мөр
+		rowNumber := i + 2
+		if row == nil {
+			continue
+		}
+		report.Total++
+
+		params, fields := customerImportParams(row, lookup)
+		if len(fields) > 0 {
+			rowError(rowNumber, fields...)
+			continue
+		}
+
+		if err := binding.Validator.ValidateStruct(params); err != nil {
+			rowError(rowNumber, shared.FieldErrors(err)...)
+			continue
+		}
+
+		if fields := validateCustomerParams(&params); len(fields) > 0 {
+			rowError(rowNumber, fields...)
+			continue
+		}
+
+		company, ok := companies[params.CompanyRD]
+		if !ok {
+			company = co.lookupParentCompany(c, params)
+			companies[params.CompanyRD] = company
+		}
+		prepared = append(prepared, importRow{number: rowNumber, params: params, company: company})
+	}
+
+	authUser := co.GetAuth(c)
+	tx := co.DB.Begin()
+
+	var statusChanges []*services.StatusChange
+	for _, row := range prepared {
+		// алдаатай мөр бусад мөрийн транзакцийг эвдэхгүй
+		tx.SavePoint("import_row")
+		customer, changes, err := co.createCustomer(tx, row.params, row.company, authUser)
+		if err != nil {
+			tx.RollbackTo("import_row")
+			rowError(row.number, structs.FieldError{Rule: "save", Message: err.Error()})
+			continue
+		}
+
+		statusChanges = append(statusChanges, changes...)
+		report.Customers = append(report.Customers, CustomerImportRow{
+			Row:  row.number,
+			Code: customer.Code,
+			Name: customer.Name,
+		})
+	}
+
+	if report.DryRun || len(report.Errors) > 0 || report.Total == 0 {
+		tx.Rollback()
+		co.SetBody(report)
+		return
+	}
+
+	result := tx.Commit()
+	if result.Error != nil {
+		co.SetError(http.StatusInternalServerError, result.Error.Error())
+		return
+	}
+
+	report.Committed = true
+	services.PublishStatusChange(statusChanges...)
+	co.SetBody(report)
+	return
+}
+
+// customerImportParams мөрийг CustomerCreateParams болгож, нэрээр өгсөн лавлахыг ID болгоно
+func customerImportParams(row map[string]string, lookup *services.RefLookup) (form.CustomerCreateParams, []structs.FieldError) {
+	var fields []structs.FieldError
+	fieldError := func(field, rule, message string) {
+		fields = append(fields, structs.FieldError{Field: field, Rule: rule, Message: message})
+	}
+	number := func(field string) float64 {
+		if row[field] == "" {
+			return 0
+		}
+		value, err := strconv.ParseFloat(strings.ReplaceAll(row[field], ",", ""), 64)
+		if err != nil {
+			fieldError(field, "numeric", "Зөвхөн тоо оруулна уу")
+		}
+		return value
+	}
+
+	params := form.CustomerCreateParams{
+		Name:                 row["name"],
+		Description:          row["description"],
+		CompanyRD:            row["company_rd"],
+		CompanyName:          row["company_name"],
+		AddressDescription:   row["address"],
+		PaymentTypeID:        int(number("payment_type_id")),
+		ClassificationID:     int(number("classification_id")),
+		MaximumPurchase:      number("maximum_purchase"),
+		MaximumReceivables:   number("maximum_receivables"),
+		OneTimePurchaseLimit: number("one_time_purchase_limit"),
+	}
+
+	countryID, ok := lookup.Country(row["country"])
+	if !ok {
+		fieldError("country", "exists", "Улс олдсонгүй")
+	}
+	params.CountryID = int(countryID)
+
+	if row["city"] != "" {
+		cityID, ok := lookup.City(countryID, row["city"])
+		if !ok {
+			fieldError("city", "exists", "Аймаг, хот олдсонгүй")
+		}
+		params.CityID = int(cityID)
+
+		if row["district"] != "" {
+			districtID, ok := lookup.District(cityID, row["district"])
+			if !ok {
+				fieldError("district", "exists", "Дүүрэг, сум олдсонгүй")
+			}
+			params.DistrictID = int(districtID)
+		}
+	}
+
+	for _, name := range strings.Split(row["customer_types"], ",") {
+		if strings.TrimSpace(name) == "" {
+			continue
+		}
+		customerType, ok := lookup.CustomerType(name)
+		if !ok {
+			fieldError("customer_types", "exists", "Харилцагчийн төрөл олдсонгүй: "+strings.TrimSpace(name))
+			continue
+		}
+		params.Types = append(params.Types, customerType)
+	}
+
+	if row["address"] != "" {
+		addressTypeID := uint(number("address_type_id"))
+		if addressTypeID == 0 {
+			addressTypeID = services.DefaultAddressTypeID()
+		}
+		params.Addresses = []form.CustomerAddressParams{{
+			AddressTypeID: addressTypeID,
+			Description:   row["address"],
+			IsDefault:     true,
+		}}
+	}
+
+	if row["contact_last_name"] != "" || row["contact_first_name"] != "" || row["contact_phone"] != "" {
+		params.Contacts = []form.CustomerContactParams{{
+			LastName:     row["contact_last_name"],
+			FirstName:    row["contact_first_name"],
+			PositionID:   int(number("contact_position_id")),
+			PhoneNumber1: row["contact_phone"],
+			PhoneNumber2: row["contact_phone2"],
+			Email1:       row["contact_email"],
+			IsPrimary:    true,
+		}}
+	}
+
+	return params, fields
+}
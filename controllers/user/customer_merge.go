@@ -0,0 +1,135 @@
+package user
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	gin "github.com/gin-gonic/gin"
+	databases "gitlab.com/fibocloud/medtech/gin/databases"
+	form "gitlab.com/fibocloud/medtech/gin/form"
+	services "gitlab.com/fibocloud/medtech/gin/services"
+	gorm "gorm.io/gorm"
+)
+
+// DuplicateList customer
+// @Summary DuplicateList customer
+// @Description Duplicate candidates by registry number, phone, email and name similarity
+// @Tags Customer
+// @Accept json
+// @Produce json
+// @Param customer_id query uint false "only pairs with this customer"
+// @Param threshold query number false "name similarity 0-1"
+// @Success 200 {object} structs.ResponseBody{body=[]services.DuplicateCandidate}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /customer/duplicate/list [get]
+func (co CustomerController) DuplicateList(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	var customerID uint64
+	if value := c.Query("customer_id"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			co.SetError(http.StatusBadRequest, err.Error())
+			return
+		}
+		customerID = id
+	}
+
+	var threshold float64
+	if value := c.Query("threshold"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || parsed <= 0 || parsed > 1 {
+			co.SetError(http.StatusBadRequest, "threshold 0-1 хооронд байна")
+			return
+		}
+		threshold = parsed
+	}
+
+	candidates, err := services.FindDuplicateCustomers(co.DB, uint(customerID), threshold)
+	if err != nil {
+		co.SetError(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	co.SetBody(candidates)
+	return
+}
+
+// DuplicateMerge customer
+// @Summary DuplicateMerge customer
+// @Description Move contacts, addresses, files, prices, orders and status logs to the surviving customer and delete the duplicate
+// @Description The survivor keeps its primary contact, default addresses, current files and customer prices; moved rows that conflict lose the flag or are deactivated.
+// @Tags Customer
+// @Accept json
+// @Produce json
+// @Param merge body form.CustomerMergeParams true "merge"
+// @Success 200 {object} structs.ResponseBody{body=databases.MedCustomerMerge}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /customer/duplicate/merge [post]
+func (co CustomerController) DuplicateMerge(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	var params form.CustomerMergeParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		co.SetError(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	tx := co.DB.Begin()
+	merge, err := services.MergeCustomers(tx, params.SurvivorID, params.MergedID, params.Description, co.GetAuth(c))
+	if err != nil {
+		tx.Rollback()
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			co.SetError(http.StatusNotFound, "Харилцагч олдсонгүй")
+		case errors.Is(err, services.ErrMergeSame), errors.Is(err, services.ErrCustomerCycle):
+			co.SetError(http.StatusBadRequest, err.Error())
+		default:
+			co.SetError(http.StatusInternalServerError, "Харилцагч нэгтгэх үед алдаа гарлаа "+err.Error())
+		}
+		return
+	}
+
+	tx.Commit()
+	co.SetBody(merge)
+	return
+}
+
+// DuplicateHistory customer
+// @Summary DuplicateHistory customer
+// @Description Merge audit records, newest first
+// @Tags Customer
+// @Accept json
+// @Produce json
+// @Param survivor_id query uint false "surviving customer"
+// @Success 200 {object} structs.ResponseBody{body=[]databases.MedCustomerMerge}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /customer/duplicate/history [get]
+func (co CustomerController) DuplicateHistory(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	db := co.DB
+	if survivorID := c.Query("survivor_id"); survivorID != "" {
+		db = db.Where("survivor_id = ?", survivorID)
+	}
+
+	var merges []databases.MedCustomerMerge
+	result := db.Preload("Survivor").Preload("CreatedUser.Person").Order("created_date desc").Find(&merges)
+	if result.Error != nil {
+		co.SetError(http.StatusInternalServerError, result.Error.Error())
+		return
+	}
+
+	co.SetBody(merges)
+	return
+}
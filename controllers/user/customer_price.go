@@ -0,0 +1,66 @@
+package user
+
+import (
+	"errors"
+	"net/http"
+
+	gin "github.com/gin-gonic/gin"
+	shared "gitlab.com/fibocloud/medtech/gin/controllers/shared"
+	form "gitlab.com/fibocloud/medtech/gin/form"
+	services "gitlab.com/fibocloud/medtech/gin/services"
+	gorm "gorm.io/gorm"
+)
+
+// PriceExplain customer
+// @Summary PriceExplain customer
+// @Description Resolve the effective sales price for a customer, item, date and quantity and list every rule that was considered
+// @Tags Customer
+// @Accept json
+// @Produce json
+// @Param query body form.PriceExplainParams true "query"
+// @Success 200 {object} structs.ResponseBody{body=services.PriceResolution}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 404 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /customer/price/explain [post]
+func (co CustomerController) PriceExplain(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	var params form.PriceExplainParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		co.SetValidationError(shared.FieldErrors(err))
+		return
+	}
+
+	resolution, err := services.ResolvePrice(co.DB, services.PriceQuery{
+		CustomerID:  params.CustomerID,
+		ItemID:      params.ItemID,
+		PriceTypeID: params.PriceTypeID,
+		Date:        params.Date,
+		Quantity:    params.Quantity,
+	})
+	if err != nil {
+		setPriceError(co.BaseController, err)
+		return
+	}
+
+	co.SetBody(resolution)
+	return
+}
+
+// setPriceError үнийн алдааг http код руу хөрвүүлнэ
+func setPriceError(co shared.BaseController, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		co.SetError(http.StatusNotFound, "Харилцагч олдсонгүй")
+	case errors.Is(err, services.ErrPriceNotFound):
+		co.SetError(http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrPriceDateRange),
+		errors.Is(err, services.ErrPriceOverlap):
+		co.SetError(http.StatusBadRequest, err.Error())
+	default:
+		co.SetError(http.StatusInternalServerError, err.Error())
+	}
+}
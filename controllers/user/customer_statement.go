@@ -0,0 +1,178 @@
+package user
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	gin "github.com/gin-gonic/gin"
+	form "gitlab.com/fibocloud/medtech/gin/form"
+	services "gitlab.com/fibocloud/medtech/gin/services"
+	gorm "gorm.io/gorm"
+)
+
+// Statement customer
+// @Summary Statement customer
+// @Description Account statement with opening balance, outcomes, payments, returns and running balance
+// @Tags Customer
+// @Accept json
+// @Produce json
+// @Param id path uint true "customer ID"
+// @Param start_date query string false "start date (2006-01-02), first day of the month by default"
+// @Param end_date query string false "end date (2006-01-02), today by default"
+// @Success 200 {object} structs.ResponseBody{body=services.Statement}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 404 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /customer/statement/{id} [get]
+func (co CustomerController) Statement(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	customerID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		co.SetError(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	now := time.Now()
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	end := now
+	if startDate := c.Query("start_date"); startDate != "" {
+		start, err = time.ParseInLocation("2006-01-02", startDate, now.Location())
+		if err != nil {
+			co.SetError(http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	if endDate := c.Query("end_date"); endDate != "" {
+		end, err = time.ParseInLocation("2006-01-02", endDate, now.Location())
+		if err != nil {
+			co.SetError(http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	if end.Before(start) {
+		co.SetError(http.StatusBadRequest, "Эхлэх огноо дуусах огнооноос хойш байна")
+		return
+	}
+
+	statement, err := services.CustomerStatement(co.DB, uint(customerID), start, end)
+	if err != nil {
+		co.setStatementError(err)
+		return
+	}
+
+	co.SetBody(statement)
+	return
+}
+
+// AgingReport customer
+// @Summary AgingReport customer
+// @Description Open receivables per customer in 0-30, 31-60, 61-90 and 90+ day buckets
+// @Description With by_due_date scheduled outcomes age from each installment's due date and parts not yet due go to not_due
+// @Tags Customer
+// @Accept json
+// @Produce json
+// @Param filter body form.CustomerAgingParams true "filter"
+// @Success 200 {object} structs.ResponseBody{body=services.AgingReport}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /customer/aging/report [post]
+func (co CustomerController) AgingReport(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	var params form.CustomerAgingParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		co.SetError(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	report, err := services.ReceivablesAging(co.DB, agingFilter(params))
+	if err != nil {
+		co.setStatementError(err)
+		return
+	}
+
+	co.SetBody(report)
+	return
+}
+
+// AgingExport customer
+// @Summary AgingExport customer
+// @Description Aging report as a CSV file
+// @Tags Customer
+// @Accept json
+// @Produce text/csv
+// @Param filter body form.CustomerAgingParams true "filter"
+// @Success 200 {file} file
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /customer/aging/export [post]
+func (co CustomerController) AgingExport(c *gin.Context) {
+	var params form.CustomerAgingParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		co.SetError(http.StatusBadRequest, err.Error())
+		c.JSON(co.GetBody())
+		return
+	}
+
+	report, err := services.ReceivablesAging(co.DB, agingFilter(params))
+	if err != nil {
+		co.setStatementError(err)
+		c.JSON(co.GetBody())
+		return
+	}
+
+	var buffer bytes.Buffer
+	// Excel кирилл үсгийг зөв уншихын тулд BOM
+	buffer.WriteString("\xEF\xBB\xBF")
+	writer := csv.NewWriter(&buffer)
+	writer.Write([]string{"Код", "Нэр", "Хугацаа болоогүй", "0-30", "31-60", "61-90", "90+", "Нийт"})
+	for _, row := range report.Rows {
+		writer.Write(agingRecord(row.Code, row.Name, row))
+	}
+	writer.Write(agingRecord("", "Нийт", report.Total))
+	writer.Flush()
+
+	fileName := "aging-" + report.AsOf.Format("20060102") + ".csv"
+	c.Header("Content-Disposition", "attachment; filename="+fileName)
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", buffer.Bytes())
+}
+
+func agingFilter(params form.CustomerAgingParams) services.AgingFilter {
+	return services.AgingFilter{
+		AsOf:             params.AsOf,
+		ClassificationID: params.ClassificationID,
+		DistrictID:       params.DistrictID,
+		ParentID:         params.ParentID,
+		ByDueDate:        params.ByDueDate,
+	}
+}
+
+func agingRecord(code, name string, row services.AgingRow) []string {
+	return []string{
+		code,
+		name,
+		row.NotDue.String(),
+		row.Days0To30.String(),
+		row.Days31To60.String(),
+		row.Days61To90.String(),
+		row.Over90.String(),
+		row.Total.String(),
+	}
+}
+
+func (co CustomerController) setStatementError(err error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		co.SetError(http.StatusNotFound, "Харилцагч олдсонгүй")
+		return
+	}
+	co.SetError(http.StatusInternalServerError, err.Error())
+}
@@ -0,0 +1,87 @@
+package user
+
+import (
+	"net/http"
+	"time"
+
+	gin "github.com/gin-gonic/gin"
+	databases "gitlab.com/fibocloud/medtech/gin/databases"
+	services "gitlab.com/fibocloud/medtech/gin/services"
+)
+
+// TaxRefresh customer
+// @Summary TaxRefresh customer
+// @Description Re-check VAT payer status of every customer with a registry number
+// @Tags Customer
+// @Accept json
+// @Produce json
+// @Success 200 {object} structs.ResponseBody{body=services.TaxRefreshResult}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /customer/tax/refresh [post]
+func (co CustomerController) TaxRefresh(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	authUser := co.GetAuth(c)
+	result, err := services.RefreshTaxStatus(c.Request.Context(), co.DB, services.NewCompanyRegistry(co.DB), authUser.Base.ID)
+	if err != nil {
+		co.SetError(http.StatusServiceUnavailable, err.Error())
+		return
+	}
+
+	co.SetBody(result)
+	return
+}
+
+// TaxChanges customer
+// @Summary TaxChanges customer
+// @Description Customers whose VAT or city tax payer status changed in the period
+// @Tags Customer
+// @Accept json
+// @Produce json
+// @Param start_date query string false "start date (2006-01-02)"
+// @Param end_date query string false "end date (2006-01-02)"
+// @Success 200 {object} structs.ResponseBody{body=[]databases.MedCustomerTaxHistory}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /customer/tax/changes [get]
+func (co CustomerController) TaxChanges(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	db := co.DB
+	if startDate := c.Query("start_date"); startDate != "" {
+		start, err := time.Parse("2006-01-02", startDate)
+		if err != nil {
+			co.SetError(http.StatusBadRequest, err.Error())
+			return
+		}
+		db = db.Where("created_date >= ?", start)
+	}
+
+	if endDate := c.Query("end_date"); endDate != "" {
+		end, err := time.Parse("2006-01-02", endDate)
+		if err != nil {
+			co.SetError(http.StatusBadRequest, err.Error())
+			return
+		}
+		db = db.Where("created_date < ?", end.AddDate(0, 0, 1))
+	}
+
+	var histories []databases.MedCustomerTaxHistory
+	result := db.
+		Preload("Customer").
+		Preload("CreatedUser.Person").
+		Order("created_date desc").
+		Find(&histories)
+	if result.Error != nil {
+		co.SetError(http.StatusInternalServerError, result.Error.Error())
+		return
+	}
+
+	co.SetBody(histories)
+	return
+}
@@ -0,0 +1,154 @@
+package user
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	gin "github.com/gin-gonic/gin"
+	form "gitlab.com/fibocloud/medtech/gin/form"
+	services "gitlab.com/fibocloud/medtech/gin/services"
+	gorm "gorm.io/gorm"
+)
+
+// TreeDescendants customer
+// @Summary TreeDescendants customer
+// @Description Every branch under the customer with its depth
+// @Tags Customer
+// @Accept json
+// @Produce json
+// @Param id path uint true "customer ID"
+// @Success 200 {object} structs.ResponseBody{body=[]services.CustomerNode}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /customer/tree/descendants/{id} [get]
+func (co CustomerController) TreeDescendants(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	customerID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		co.SetError(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	nodes, err := services.CustomerDescendants(co.DB, uint(customerID))
+	if err != nil {
+		co.setTreeError(err)
+		return
+	}
+
+	co.SetBody(nodes)
+	return
+}
+
+// TreeAncestors customer
+// @Summary TreeAncestors customer
+// @Description Parents of the customer up to the head company, nearest first
+// @Tags Customer
+// @Accept json
+// @Produce json
+// @Param id path uint true "customer ID"
+// @Success 200 {object} structs.ResponseBody{body=[]services.CustomerNode}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /customer/tree/ancestors/{id} [get]
+func (co CustomerController) TreeAncestors(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	customerID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		co.SetError(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	nodes, err := services.CustomerAncestors(co.DB, uint(customerID))
+	if err != nil {
+		co.setTreeError(err)
+		return
+	}
+
+	co.SetBody(nodes)
+	return
+}
+
+// TreeMove customer
+// @Summary TreeMove customer
+// @Description Move a branch under another parent company
+// @Tags Customer
+// @Accept json
+// @Produce json
+// @Param move body form.CustomerMoveParams true "move"
+// @Success 200 {object} structs.ResponseBody{body=databases.MedCustomer}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /customer/tree/move [post]
+func (co CustomerController) TreeMove(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	var params form.CustomerMoveParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		co.SetError(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	tx := co.DB.Begin()
+	customer, err := services.MoveCustomer(tx, params.CustomerID, params.ParentID, co.GetAuth(c))
+	if err != nil {
+		tx.Rollback()
+		co.setTreeError(err)
+		return
+	}
+
+	tx.Commit()
+	co.SetBody(customer)
+	return
+}
+
+// TreeSummary customer
+// @Summary TreeSummary customer
+// @Description Limits, orders and receivables of the customer and all its branches
+// @Tags Customer
+// @Accept json
+// @Produce json
+// @Param id path uint true "customer ID"
+// @Success 200 {object} structs.ResponseBody{body=services.CustomerGroupSummary}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /customer/tree/summary/{id} [get]
+func (co CustomerController) TreeSummary(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	customerID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		co.SetError(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	summary, err := services.CustomerGroupTotals(co.DB, uint(customerID))
+	if err != nil {
+		co.setTreeError(err)
+		return
+	}
+
+	co.SetBody(summary)
+	return
+}
+
+func (co CustomerController) setTreeError(err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		co.SetError(http.StatusNotFound, "Харилцагч олдсонгүй")
+	case errors.Is(err, services.ErrCustomerCycle), errors.Is(err, services.ErrCustomerDepth):
+		co.SetError(http.StatusBadRequest, err.Error())
+	default:
+		co.SetError(http.StatusInternalServerError, err.Error())
+	}
+}
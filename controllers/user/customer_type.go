@@ -40,6 +40,7 @@ func (co CustomerTypeController) Init(router *gin.RouterGroup) {
 // @Accept json
 // @Produce json
 // @Param filter body form.CustomerTypeFilter true "filter"
+// @Param lang query string false "locale, Accept-Language when empty"
 // @Success 200 {object} structs.ResponseBody{body=[]databases.MedCustomerType}
 // @Failure 400 {object} structs.ErrorResponse
 // @Failure 500 {object} structs.ErrorResponse
@@ -69,6 +70,7 @@ func (co CustomerTypeController) List(c *gin.Context) {
 
 	var customerTypes []databases.MedCustomerType
 	db.Find(&customerTypes)
+	co.Localize(c, &customerTypes)
 	db.Table("med_customer_types").Count(&count)
 
 	listRepsonse.List = customerTypes
@@ -84,20 +86,23 @@ func (co CustomerTypeController) List(c *gin.Context) {
 // @Tags CustomerType
 // @Accept json
 // @Produce json
+// @Param lang query string false "locale, Accept-Language when empty"
+// @Param If-None-Match header string false "өмнөх ETag"
 // @Success 200 {object} structs.ResponseBody{body=[]databases.MedCustomerType}
+// @Header 200 {string} ETag "лавлахын хувилбар"
+// @Success 304 "өөрчлөгдөөгүй"
 // @Failure 400 {object} structs.ErrorResponse
 // @Failure 500 {object} structs.ErrorResponse
 // @Router /customerType/list/active [get]
 func (co CustomerTypeController) ListActive(c *gin.Context) {
-	defer func() {
-		c.JSON(co.GetBody())
-	}()
-
-	var customerTypes []databases.MedCustomerType
-	co.DB.Where("is_active = ?", true).Find(&customerTypes)
-
-	co.SetBody(customerTypes)
-	return
+	co.CachedJSON(c, "customerType.active", []string{"med_customer_types", "med_translations"}, func() (interface{}, error) {
+		var customerTypes []databases.MedCustomerType
+		if err := co.DB.Where("is_active = ?", true).Find(&customerTypes).Error; err != nil {
+			return nil, err
+		}
+		co.Localize(c, &customerTypes)
+		return customerTypes, nil
+	})
 }
 
 // Get customerType
@@ -107,6 +112,7 @@ func (co CustomerTypeController) ListActive(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path uint true "customerType ID"
+// @Param lang query string false "locale, Accept-Language when empty"
 // @Success 200 {object} structs.ResponseBody{body=databases.MedCustomerType}
 // @Failure 400 {object} structs.ErrorResponse
 // @Failure 500 {object} structs.ErrorResponse
@@ -123,6 +129,7 @@ func (co CustomerTypeController) Get(c *gin.Context) {
 		return
 	}
 
+	co.Localize(c, &customerType)
 	co.SetBody(customerType)
 	return
 }
@@ -0,0 +1,353 @@
+package user
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	gin "github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	shared "gitlab.com/fibocloud/medtech/gin/controllers/shared"
+	databases "gitlab.com/fibocloud/medtech/gin/databases"
+	form "gitlab.com/fibocloud/medtech/gin/form"
+	services "gitlab.com/fibocloud/medtech/gin/services"
+	gorm "gorm.io/gorm"
+)
+
+// PriceChangeController struct
+type PriceChangeController struct {
+	shared.BaseController
+}
+
+// ListPriceChange ...
+type ListPriceChange struct {
+	Total int64                      `json:"total"`
+	List  []databases.MedPriceChange `json:"list"`
+}
+
+// Init Controller
+func (co PriceChangeController) Init(router *gin.RouterGroup) {
+	router.POST("/list", co.List)           // List
+	router.GET("get/:id", co.Get)           // Show
+	router.POST("", co.Create)              // Create
+	router.POST("/approve/:id", co.Approve) // Approve
+	router.POST("/reject/:id", co.Reject)   // Reject
+	router.POST("/history", co.History)     // History
+}
+
+// List priceChange
+// @Summary List priceChange
+// @Description Price-change documents
+// @Tags PriceChange
+// @Accept json
+// @Produce json
+// @Param filter body form.PriceChangeFilter true "filter"
+// @Success 200 {object} structs.ResponseBody{body=ListPriceChange}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /priceChange/list [post]
+func (co PriceChangeController) List(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	var count int64
+	var params form.PriceChangeFilter
+	if err := c.ShouldBindJSON(&params); err != nil {
+		co.SetError(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	db := co.DB
+
+	// filter hiij bgaa heseg
+	v := reflect.ValueOf(params.Filter)
+
+	db = db.Scopes(shared.TableSearch(v, params.Sort))
+	db = db.Scopes(shared.Paginate(params.Page, params.Size))
+
+	var listRepsonse ListPriceChange
+
+	var changes []databases.MedPriceChange
+	db.
+		Preload("CreatedUser.Person").
+		Preload("ApprovedUser.Person").
+		Find(&changes)
+
+	co.DB.Table("med_price_changes").Count(&count)
+
+	listRepsonse.List = changes
+	listRepsonse.Total = count
+
+	co.SetBody(listRepsonse)
+	return
+}
+
+// Get priceChange
+// @Summary Get priceChange
+// @Description Price-change document with old and new values per line
+// @Tags PriceChange
+// @Accept json
+// @Produce json
+// @Param id path uint true "priceChange ID"
+// @Success 200 {object} structs.ResponseBody{body=databases.MedPriceChange}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /priceChange/get/{id} [get]
+func (co PriceChangeController) Get(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	var change databases.MedPriceChange
+	result := co.DB.
+		Preload("Lines.Customer").
+		Preload("Lines.Item").
+		Preload("Lines.WarehouseItem").
+		Preload("Lines.PriceType").
+		Preload("CreatedUser.Person").
+		Preload("ApprovedUser.Person").
+		First(&change, c.Param("id"))
+	if result.Error != nil {
+		co.SetError(http.StatusNotFound, result.Error.Error())
+		return
+	}
+
+	co.SetBody(change)
+	return
+}
+
+// Create priceChange
+// @Summary Create priceChange
+// @Description Draft a price-change document. Prices change only after approval, at the effective date.
+// @Tags PriceChange
+// @Accept json
+// @Produce json
+// @Param priceChange body form.PriceChangeParams true "priceChange"
+// @Success 200 {object} structs.ResponseBody{body=databases.MedPriceChange}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /priceChange [post]
+func (co PriceChangeController) Create(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	var params form.PriceChangeParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		co.SetValidationError(shared.FieldErrors(err))
+		return
+	}
+
+	var lines []*databases.MedPriceChangeDtl
+	for _, item := range params.WarehouseItems {
+		lines = append(lines, &databases.MedPriceChangeDtl{
+			Kind:            services.PriceChangeWarehouse,
+			WarehouseItemID: uint(item.WarehouseItemID),
+			PriceTypeID:     uint(item.NewPriceType),
+			NewPrice:        databases.NewDecimal(decimal.NewFromFloat(item.NewPrice)),
+		})
+	}
+	if params.Customer != nil {
+		lines = append(lines, customerPriceLines(*params.Customer)...)
+	}
+
+	authUser := co.GetAuth(c)
+	change, err := draftPriceChange(co.DB, params.Description, params.EffectiveDate, lines, authUser)
+	if err != nil {
+		setPriceChangeError(co.BaseController, err)
+		return
+	}
+
+	co.SetBody(change)
+	return
+}
+
+// Approve priceChange
+// @Summary Approve priceChange
+// @Description Approve a draft price-change document. Requires the pricing approval permission and cannot be done by its creator.
+// @Tags PriceChange
+// @Accept json
+// @Produce json
+// @Param id path uint true "priceChange ID"
+// @Success 200 {object} structs.ResponseBody{body=databases.MedPriceChange}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 403 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /priceChange/approve/{id} [post]
+func (co PriceChangeController) Approve(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	changeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		co.SetError(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	tx := co.DB.Begin()
+	change, err := services.ApprovePriceChange(tx, uint(changeID), co.GetAuth(c))
+	if err != nil {
+		tx.Rollback()
+		setPriceChangeError(co.BaseController, err)
+		return
+	}
+
+	tx.Commit()
+	co.SetBody(change)
+	return
+}
+
+// Reject priceChange
+// @Summary Reject priceChange
+// @Description Reject a draft price-change document
+// @Tags PriceChange
+// @Accept json
+// @Produce json
+// @Param id path uint true "priceChange ID"
+// @Param reject body form.PriceChangeRejectParams true "reject"
+// @Success 200 {object} structs.ResponseBody{body=databases.MedPriceChange}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 403 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /priceChange/reject/{id} [post]
+func (co PriceChangeController) Reject(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	var params form.PriceChangeRejectParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		co.SetValidationError(shared.FieldErrors(err))
+		return
+	}
+
+	changeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		co.SetError(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	tx := co.DB.Begin()
+	change, err := services.RejectPriceChange(tx, uint(changeID), params.Reason, co.GetAuth(c))
+	if err != nil {
+		tx.Rollback()
+		setPriceChangeError(co.BaseController, err)
+		return
+	}
+
+	tx.Commit()
+	co.SetBody(change)
+	return
+}
+
+// History priceChange
+// @Summary History priceChange
+// @Description Applied price changes for a warehouse item, item or customer and price type, newest first
+// @Tags PriceChange
+// @Accept json
+// @Produce json
+// @Param filter body form.WarehousePriceHistory true "filter"
+// @Success 200 {object} structs.ResponseBody{body=[]databases.MedPriceChangeDtl}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /priceChange/history [post]
+func (co PriceChangeController) History(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	var params form.WarehousePriceHistory
+	if err := c.ShouldBindJSON(&params); err != nil {
+		co.SetError(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if params.WarehouseItemID == 0 && params.ItemID == 0 && params.CustomerID == 0 {
+		co.SetError(http.StatusBadRequest, "Бараа эсвэл харилцагч сонгоно уу")
+		return
+	}
+
+	lines, err := services.PriceHistory(co.DB, services.PriceHistoryQuery{
+		WarehouseItemID: uint(params.WarehouseItemID),
+		ItemID:          uint(params.ItemID),
+		CustomerID:      uint(params.CustomerID),
+		PriceTypeID:     uint(params.PriceTypeID),
+	})
+	if err != nil {
+		co.SetError(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	co.SetBody(lines)
+	return
+}
+
+// draftPriceChange ноорог баримт үүсгэнэ
+func draftPriceChange(db *gorm.DB, description string, effectiveDate time.Time, lines []*databases.MedPriceChangeDtl, authUser databases.MedSystemUser) (*databases.MedPriceChange, error) {
+	change := databases.MedPriceChange{
+		Description:    strings.TrimSpace(description),
+		EffectiveDate:  effectiveDate,
+		Lines:          lines,
+		CreatedUserID:  authUser.Base.ID,
+		ModifiedUserID: authUser.Base.ID,
+		Base: databases.Base{
+			CreatedDate:  time.Now(),
+			ModifiedDate: time.Now(),
+		},
+	}
+
+	tx := db.Begin()
+	err := services.CreatePriceChange(tx, &change)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	tx.Commit()
+	return &change, nil
+}
+
+// customerPriceLines харилцагч бүрийн бараа бүрт мөр үүсгэнэ
+func customerPriceLines(params form.CustomerUpdatePriceParams) []*databases.MedPriceChangeDtl {
+	var lines []*databases.MedPriceChangeDtl
+	for _, customerID := range params.CustomerIDs {
+		for _, item := range params.Items {
+			startDate := params.StartDate
+			endDate := params.EndDate
+			lines = append(lines, &databases.MedPriceChangeDtl{
+				Kind:       services.PriceChangeCustomer,
+				CustomerID: uint(customerID),
+				ItemID:     uint(item.ItemID),
+				IsPercent:  params.IsPercent,
+				NewPrice:   databases.NewDecimal(decimal.NewFromFloat(item.DiscountPrice)),
+				NewPercent: item.DiscountPercent,
+				StartDate:  &startDate,
+				EndDate:    &endDate,
+			})
+		}
+	}
+	return lines
+}
+
+// setPriceChangeError үнийн өөрчлөлтийн алдааг http код руу хөрвүүлнэ
+func setPriceChangeError(co shared.BaseController, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		co.SetError(http.StatusNotFound, "Баримт олдсонгүй")
+	case errors.Is(err, services.ErrPriceApprovalRight),
+		errors.Is(err, services.ErrPriceSelfApproval):
+		co.SetError(http.StatusForbidden, err.Error())
+	case errors.Is(err, services.ErrPriceChangeEmpty),
+		errors.Is(err, services.ErrPriceChangeLine),
+		errors.Is(err, services.ErrPriceChangeState),
+		errors.Is(err, services.ErrPriceRejectReason):
+		co.SetError(http.StatusBadRequest, err.Error())
+	default:
+		setPriceError(co, err)
+	}
+}
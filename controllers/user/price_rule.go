@@ -0,0 +1,263 @@
+package user
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	gin "github.com/gin-gonic/gin"
+	shared "gitlab.com/fibocloud/medtech/gin/controllers/shared"
+	databases "gitlab.com/fibocloud/medtech/gin/databases"
+	form "gitlab.com/fibocloud/medtech/gin/form"
+	services "gitlab.com/fibocloud/medtech/gin/services"
+	structs "gitlab.com/fibocloud/medtech/gin/structs"
+)
+
+// PriceRuleController struct
+type PriceRuleController struct {
+	shared.BaseController
+}
+
+// ListPriceRule ...
+type ListPriceRule struct {
+	Total int64                    `json:"total"`
+	List  []databases.MedPriceRule `json:"list"`
+}
+
+// Init Controller
+func (co PriceRuleController) Init(router *gin.RouterGroup) {
+	router.POST("/list", co.List) // List
+	router.GET("get/:id", co.Get) // Show
+	router.POST("", co.Create)    // Create
+	router.PUT("/:id", co.Update) // Update
+	router.DELETE("", co.Delete)  // Delete
+}
+
+// List priceRule
+// @Summary List priceRule
+// @Description Price rules by customer type, classification and item
+// @Tags PriceRule
+// @Accept json
+// @Produce json
+// @Param filter body form.PriceRuleFilter true "filter"
+// @Success 200 {object} structs.ResponseBody{body=ListPriceRule}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /priceRule/list [post]
+func (co PriceRuleController) List(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	var count int64
+	var params form.PriceRuleFilter
+	if err := c.ShouldBindJSON(&params); err != nil {
+		co.SetError(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	db := co.DB
+
+	// filter hiij bgaa heseg
+	v := reflect.ValueOf(params.Filter)
+
+	db = db.Scopes(shared.TableSearch(v, params.Sort))
+	db = db.Scopes(shared.Paginate(params.Page, params.Size))
+
+	var listRepsonse ListPriceRule
+
+	var rules []databases.MedPriceRule
+	db.
+		Preload("CustomerType").
+		Preload("Classification").
+		Preload("Item").
+		Preload("PriceType").
+		Find(&rules)
+
+	co.DB.Table("med_price_rules").Count(&count)
+
+	listRepsonse.List = rules
+	listRepsonse.Total = count
+
+	co.SetBody(listRepsonse)
+	return
+}
+
+// Get priceRule
+// @Summary Get priceRule
+// @Description Show priceRule
+// @Tags PriceRule
+// @Accept json
+// @Produce json
+// @Param id path uint true "priceRule ID"
+// @Success 200 {object} structs.ResponseBody{body=databases.MedPriceRule}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /priceRule/get/{id} [get]
+func (co PriceRuleController) Get(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	var rule databases.MedPriceRule
+	result := co.DB.
+		Preload("CustomerType").
+		Preload("Classification").
+		Preload("Item").
+		Preload("PriceType").
+		First(&rule, c.Param("id"))
+	if result.Error != nil {
+		co.SetError(http.StatusNotFound, result.Error.Error())
+		return
+	}
+
+	co.SetBody(rule)
+	return
+}
+
+// Create priceRule
+// @Summary Create priceRule
+// @Description Add a price rule, rejected when it overlaps an active rule with the same conditions
+// @Tags PriceRule
+// @Accept json
+// @Produce json
+// @Param priceRule body form.PriceRuleParams true "priceRule"
+// @Success 200 {object} structs.ResponseBody{body=databases.MedPriceRule}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /priceRule [post]
+func (co PriceRuleController) Create(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	var params form.PriceRuleParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		co.SetValidationError(shared.FieldErrors(err))
+		return
+	}
+
+	authUser := co.GetAuth(c)
+	rule := databases.MedPriceRule{
+		CreatedUser: &authUser,
+		Base: databases.Base{
+			CreatedDate: time.Now(),
+		},
+	}
+	co.savePriceRule(&rule, params, authUser)
+	return
+}
+
+// Update priceRule
+// @Summary Update priceRule
+// @Description Edit priceRule
+// @Tags PriceRule
+// @Accept json
+// @Produce json
+// @Param id path uint true "priceRule ID"
+// @Param priceRule body form.PriceRuleParams true "priceRule"
+// @Success 200 {object} structs.ResponseBody{body=databases.MedPriceRule}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /priceRule/{id} [put]
+func (co PriceRuleController) Update(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	var params form.PriceRuleParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		co.SetValidationError(shared.FieldErrors(err))
+		return
+	}
+
+	var rule databases.MedPriceRule
+	result := co.DB.First(&rule, c.Param("id"))
+	if result.Error != nil {
+		co.SetError(http.StatusNotFound, result.Error.Error())
+		return
+	}
+
+	co.savePriceRule(&rule, params, co.GetAuth(c))
+	return
+}
+
+// Delete priceRule
+// @Summary Delete priceRule
+// @Description Remove priceRule
+// @Tags PriceRule
+// @Accept json
+// @Produce json
+// @Param priceRule body form.DeleteParams true "priceRule"
+// @Success 200 {object} structs.ResponseBody{body=structs.SuccessResponse}
+// @Failure 400 {object} structs.ErrorResponse
+// @Failure 500 {object} structs.ErrorResponse
+// @Router /priceRule [delete]
+func (co PriceRuleController) Delete(c *gin.Context) {
+	defer func() {
+		c.JSON(co.GetBody())
+	}()
+
+	var params form.DeleteParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		co.SetError(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	for _, v := range params.IDs {
+		result := co.DB.Delete(&databases.MedPriceRule{}, v)
+		if result.Error != nil {
+			co.SetError(http.StatusInternalServerError, result.Error.Error())
+			return
+		}
+	}
+
+	co.SetBody(structs.SuccessResponse{
+		Success: true,
+	})
+	return
+}
+
+// savePriceRule params-аас утга оноож, давхцал шалгаад хадгална
+func (co PriceRuleController) savePriceRule(rule *databases.MedPriceRule, params form.PriceRuleParams, authUser databases.MedSystemUser) {
+	rule.Name = strings.TrimSpace(params.Name)
+	rule.CustomerTypeID = params.CustomerTypeID
+	rule.ClassificationID = params.ClassificationID
+	rule.ItemID = params.ItemID
+	rule.PriceTypeID = params.PriceTypeID
+	rule.MinQuantity = params.MinQuantity
+	rule.IsPercent = params.IsPercent
+	rule.Percent = params.Percent
+	rule.SalesPrice = services.RoundBase(params.SalesPrice.Decimal)
+	rule.Priority = params.Priority
+	rule.StartDate = params.StartDate
+	rule.EndDate = params.EndDate
+	rule.IsActive = params.IsActive
+	rule.ModifiedUser = &authUser
+	rule.Base.ModifiedDate = time.Now()
+
+	if rule.IsPercent {
+		rule.SalesPrice = databases.Decimal{}
+	} else {
+		rule.Percent = 0
+	}
+
+	tx := co.DB.Begin()
+	err := services.CheckPriceRuleOverlap(tx, *rule)
+	if err != nil {
+		tx.Rollback()
+		setPriceError(co.BaseController, err)
+		return
+	}
+
+	result := tx.Omit("CustomerType", "Classification", "Item", "PriceType").Save(rule)
+	if result.Error != nil {
+		tx.Rollback()
+		co.SetError(http.StatusInternalServerError, result.Error.Error())
+		return
+	}
+
+	tx.Commit()
+	co.SetBody(rule)
+}
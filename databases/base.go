@@ -21,6 +21,7 @@ type (
 	RefCountry struct {
 		Base
 		Name           string         `gorm:"column:name;not null" json:"name"`                //
+		Code           string         `gorm:"column:code;size:64;index" json:"code"`           // Системд ашиглах код
 		CreatedUserID  uint           `gorm:"column:created_user_id" json:"created_user_id"`   //
 		ModifiedUserID uint           `gorm:"column:modified_user_id" json:"modified_user_id"` //
 		CreatedUser    *MedSystemUser `gorm:"foreignKey:CreatedUserID" json:"created_user"`    // Үүсгэсэн хэрэглэгч
@@ -38,6 +39,8 @@ type (
 		ModifiedUser   *MedSystemUser `gorm:"foreignKey:ModifiedUserID" json:"modified_user"`  // Өөрчилсөн хэрэглэгч
 		Country        *RefCountry    `gorm:"foreignKey:CountryID" json:"country"`             // Улс
 		CountryID      uint           `gorm:"column:country_id" json:"country_id"`             //
+		ExternalID     uint           `gorm:"column:external_id;index" json:"external_id"`     // region.json-ийн ID
+		SortOrder      int            `gorm:"column:sort_order;default:0" json:"sort_order"`   // Эрэмбэ
 	}
 
 	// RefDistrict struct
@@ -50,6 +53,8 @@ type (
 		ModifiedUser   *MedSystemUser `gorm:"foreignKey:ModifiedUserID" json:"modified_user"`  // Өөрчилсөн хэрэглэгч
 		City           *RefCity       `gorm:"foreignKey:CityID" json:"city"`                   // Хот
 		CityID         uint           `gorm:"column:city_id" json:"city_id"`                   //
+		ExternalID     uint           `gorm:"column:external_id;index" json:"external_id"`     // region.json-ийн ID
+		SortOrder      int            `gorm:"column:sort_order;default:0" json:"sort_order"`   // Эрэмбэ
 	}
 
 	// RefStreet struct
@@ -62,6 +67,8 @@ type (
 		ModifiedUser   *MedSystemUser `gorm:"foreignKey:ModifiedUserID" json:"modified_user"`  // Өөрчилсөн хэрэглэгч
 		District       *RefDistrict   `gorm:"foreignKey:DistrictID" json:"district"`           // Дүүрэг
 		DistrictID     uint           `gorm:"column:district_id" json:"district_id"`           //
+		ExternalID     uint           `gorm:"column:external_id;index" json:"external_id"`     // region.json-ийн ID
+		SortOrder      int            `gorm:"column:sort_order;default:0" json:"sort_order"`   // Эрэмбэ
 	}
 )
 
@@ -118,9 +125,34 @@ func InitDB() *gorm.DB {
 		&MedCustomerContacts{},
 		&MedContent{},
 		&MedContentType{},
+		&MedCustomerDocument{},
+		&MedCompanyRegistryCache{},
+		&MedCustomerTaxHistory{},
+		&MedNumberFormat{},
+		&MedNumberSequence{},
+		&MedStatusTransition{},
+		&MedStatusNode{},
+		&MedCustomerPayment{},
+		&MedCustomerMerge{},
+		&MedPriceRule{},
+		&MedPriceChange{},
+		&MedPriceChangeDtl{},
+		&MedCustomerClassificationLog{},
+		&MedTranslation{},
+		&MedExchangeRate{},
+		&MedCacheEntry{},
+		&MedPaymentTerm{},
+		&MedPaymentTermInstallment{},
+		&MedOutcomeInstallment{},
 		&RefCountry{},
 		&RefCity{},
+		&RefDistrict{},
+		&RefStreet{},
 	)
+
+	// Харилцагчийн эрх үүсгэх үед med_customer гэж буруу бичигдэж байсан
+	db.Model(&MedStatusLog{}).Where("hdr_table_name = ?", "med_customer").Update("hdr_table_name", "med_customers")
+
 	return db
 }
 
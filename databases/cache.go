@@ -0,0 +1,13 @@
+package databases
+
+import "time"
+
+type (
+	// MedCacheEntry [ Олон instance-д хуваалцах лавлахын кэш ]
+	MedCacheEntry struct {
+		Base
+		Key        string     `gorm:"column:key;unique;not null" json:"key"` // Кэшийн түлхүүр
+		Value      []byte     `gorm:"column:value;type:bytea" json:"-"`      // JSON утга
+		ExpireDate *time.Time `gorm:"column:expire_date" json:"expire_date"` // Хоосон бол хугацаагүй
+	}
+)
@@ -0,0 +1,19 @@
+package databases
+
+type (
+	// MedCustomerClassificationLog [ Харилцагчийн ангилал автоматаар өөрчлөгдсөн түүх ]
+	MedCustomerClassificationLog struct {
+		Base
+		CustomerID          uint                       `gorm:"column:customer_id;not null;index" json:"customer_id"`      //
+		Customer            *MedCustomer               `gorm:"foreignKey:CustomerID" json:"customer"`                     //
+		OldClassificationID uint                       `gorm:"column:old_classification_id" json:"old_classification_id"` // Өмнөх ангилал
+		OldClassification   *MedCustomerClassification `gorm:"foreignKey:OldClassificationID" json:"old_classification"`  //
+		NewClassificationID uint                       `gorm:"column:new_classification_id" json:"new_classification_id"` // Шинэ ангилал
+		NewClassification   *MedCustomerClassification `gorm:"foreignKey:NewClassificationID" json:"new_classification"`  //
+		Purchase            Decimal                    `gorm:"column:purchase;type:numeric(20,4)" json:"purchase"`        // Хугацаанд худалдан авсан дүн
+		OrderCount          int                        `gorm:"column:order_count" json:"order_count"`                     // Хугацаанд өгсөн захиалга
+		AvgPayDays          float64                    `gorm:"column:avg_pay_days" json:"avg_pay_days"`                   // Төлбөр төлсөн дундаж хоног
+		CreatedUserID       uint                       `gorm:"column:created_user_id" json:"created_user_id"`             // 0 бол шөнийн ажил
+		CreatedUser         *MedSystemUser             `gorm:"foreignKey:CreatedUserID" json:"created_user"`              // Үүсгэсэн хэрэглэгч
+	}
+)
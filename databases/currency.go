@@ -0,0 +1,40 @@
+package databases
+
+import "github.com/shopspring/decimal"
+
+type (
+	// DocumentCurrency [ Баримтын валют ба ашигласан ханш ]. Мөнгөн дүнтэй баримтад
+	// embed хийж дүнг гүйлгээний болон үндсэн валютаар зэрэг хадгална.
+	DocumentCurrency struct {
+		ValuteID     uint       `gorm:"column:valute_id" json:"valute_id"`                    // 0 бол үндсэн валют
+		Valute       *RefValute `gorm:"foreignKey:ValuteID" json:"valute"`                    //
+		CurrencyCode string     `gorm:"column:currency_code;size:3" json:"currency_code"`     // MNT, USD
+		Rate         Decimal    `gorm:"column:rate;type:numeric(18,6);default:1" json:"rate"` // 1 нэгж валют үндсэн валютаар
+	}
+
+	// Decimal мөнгөн дүн. json-д бусад float талбартай адил тоогоор гарч, тоо болон
+	// тэмдэгт мөрийн аль алиныг уншина. decimal.MarshalJSONWithoutQuotes-ийг бүх
+	// процесст өөрчлөхгүйн тулд тусдаа төрөл.
+	Decimal struct {
+		decimal.Decimal
+	}
+)
+
+// NewDecimal decimal.Decimal-аас үүсгэнэ
+func NewDecimal(value decimal.Decimal) Decimal {
+	return Decimal{Decimal: value}
+}
+
+// MarshalJSON хашилтгүй тоо
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.Decimal.String()), nil
+}
+
+// UnmarshalJSON 12.5 болон "12.5" хэлбэрийг уншина
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		d.Decimal = decimal.Zero
+		return nil
+	}
+	return d.Decimal.UnmarshalJSON(data)
+}
@@ -0,0 +1,16 @@
+package databases
+
+import "time"
+
+type (
+	// MedExchangeRate [ Валютын өдрийн ханш ]
+	MedExchangeRate struct {
+		Base
+		ValuteID uint       `gorm:"column:valute_id;not null;uniqueIndex:idx_exchange_rate_day" json:"valute_id"`           // Валют
+		Valute   *RefValute `gorm:"foreignKey:ValuteID" json:"valute"`                                                      //
+		Code     string     `gorm:"column:code;not null" json:"code"`                                                       // USD, EUR
+		RateDate time.Time  `gorm:"column:rate_date;type:date;not null;uniqueIndex:idx_exchange_rate_day" json:"rate_date"` // Ханшийн огноо
+		Rate     Decimal    `gorm:"column:rate;type:numeric(18,6);not null" json:"rate"`                                    // 1 нэгж валют төгрөгөөр
+		Source   string     `gorm:"column:source;not null" json:"source"`                                                   // mongolbank, file
+	}
+)
@@ -0,0 +1,19 @@
+package databases
+
+type (
+	// MedCustomerMerge [ Давхардсан харилцагч нэгтгэсэн түүх ]
+	MedCustomerMerge struct {
+		Base
+		SurvivorID           uint           `gorm:"column:survivor_id;not null;index" json:"survivor_id"`        // Үлдсэн харилцагч
+		Survivor             *MedCustomer   `gorm:"foreignKey:SurvivorID" json:"survivor"`                       //
+		MergedID             uint           `gorm:"column:merged_id;not null" json:"merged_id"`                  // Устгагдсан харилцагчийн ID
+		MergedCode           string         `gorm:"column:merged_code" json:"merged_code"`                       //
+		MergedName           string         `gorm:"column:merged_name" json:"merged_name"`                       //
+		MergedRegistryNumber string         `gorm:"column:merged_registry_number" json:"merged_registry_number"` //
+		Snapshot             string         `gorm:"column:snapshot;type:text" json:"snapshot"`                   // Устгагдсан харилцагчийн JSON
+		MovedRows            string         `gorm:"column:moved_rows;type:text" json:"moved_rows"`               // Хүснэгт тус бүрт шилжүүлсэн мөрийн тоо, JSON
+		Description          string         `gorm:"column:description" json:"description"`                       // Тайлбар
+		CreatedUserID        uint           `gorm:"column:created_user_id" json:"created_user_id"`               //
+		CreatedUser          *MedSystemUser `gorm:"foreignKey:CreatedUserID" json:"created_user"`                // Нэгтгэсэн хэрэглэгч
+	}
+)
@@ -0,0 +1,28 @@
+package databases
+
+type (
+	// MedNumberFormat [ Баримтын дугаарлалтын тохиргоо ]
+	MedNumberFormat struct {
+		Base
+		Code           string         `gorm:"column:code;unique;not null" json:"code"`          // customer, order_book, income, outcome, invoice
+		Name           string         `gorm:"column:name;not null" json:"name"`                 //
+		Prefix         string         `gorm:"column:prefix" json:"prefix"`                      // Угтвар
+		DatePattern    string         `gorm:"column:date_pattern" json:"date_pattern"`          // YYYY, YY, MM, DD
+		Separator      string         `gorm:"column:separator" json:"separator"`                // Огноо, дугаар хооронд
+		Padding        int            `gorm:"column:padding;not null" json:"padding"`           // Дугаарын оронгийн тоо
+		ResetPeriod    string         `gorm:"column:reset_period;not null" json:"reset_period"` // never, yearly, monthly, daily
+		IsActive       bool           `gorm:"column:is_active;default:false" json:"is_active"`  //
+		CreatedUserID  uint           `gorm:"column:created_user_id" json:"created_user_id"`    //
+		ModifiedUserID uint           `gorm:"column:modified_user_id" json:"modified_user_id"`  //
+		CreatedUser    *MedSystemUser `gorm:"foreignKey:CreatedUserID" json:"created_user"`     // Үүсгэсэн хэрэглэгч
+		ModifiedUser   *MedSystemUser `gorm:"foreignKey:ModifiedUserID" json:"modified_user"`   // Өөрчилсөн хэрэглэгч
+	}
+
+	// MedNumberSequence [ Дугаарлалтын үе тус бүрийн сүүлийн дугаар ]
+	MedNumberSequence struct {
+		Base
+		FormatCode string `gorm:"column:format_code;not null;uniqueIndex:idx_number_sequence" json:"format_code"` //
+		PeriodKey  string `gorm:"column:period_key;not null;uniqueIndex:idx_number_sequence" json:"period_key"`   // 2021, 202101, 20210102
+		LastValue  int    `gorm:"column:last_value;not null" json:"last_value"`                                   // Сүүлд олгосон дугаар
+	}
+)
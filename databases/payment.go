@@ -0,0 +1,24 @@
+package databases
+
+import "time"
+
+type (
+	// MedCustomerPayment [ Харилцагчаас хүлээн авсан төлбөр ]
+	MedCustomerPayment struct {
+		Base
+		DocumentCurrency
+		CustomerID        uint              `gorm:"column:customer_id;not null;index" json:"customer_id"`                   //
+		Customer          *MedCustomer      `gorm:"foreignKey:CustomerID" json:"customer"`                                  //
+		OutcomeID         uint              `gorm:"column:outcome_id;index" json:"outcome_id"`                              // Төлсөн зарлага, 0 бол урьдчилгаа
+		PaymentMethodID   uint              `gorm:"column:payment_method_id" json:"payment_method_id"`                      //
+		PaymentMethod     *MedPaymentMethod `gorm:"foreignKey:PaymentMethodID" json:"payment_method"`                       // Төлбөрийн хэлбэр
+		TransactionAmount Decimal           `gorm:"column:transaction_amount;type:numeric(20,4)" json:"transaction_amount"` // Төлсөн дүн, баримтын валютаар
+		Amount            Decimal           `gorm:"column:amount;type:numeric(20,4);not null" json:"amount"`                // Төлсөн дүн, үндсэн валютаар
+		PaymentDate       time.Time         `gorm:"column:payment_date;not null" json:"payment_date"`                       // Төлсөн огноо
+		Description       string            `gorm:"column:description" json:"description"`                                  // Тайлбар
+		CreatedUserID     uint              `gorm:"column:created_user_id" json:"created_user_id"`                          //
+		ModifiedUserID    uint              `gorm:"column:modified_user_id" json:"modified_user_id"`                        //
+		CreatedUser       *MedSystemUser    `gorm:"foreignKey:CreatedUserID" json:"created_user"`                           // Үүсгэсэн хэрэглэгч
+		ModifiedUser      *MedSystemUser    `gorm:"foreignKey:ModifiedUserID" json:"modified_user"`                         // Өөрчилсөн хэрэглэгч
+	}
+)
@@ -0,0 +1,49 @@
+package databases
+
+import "time"
+
+type (
+	// MedPaymentTerm [ Төлбөрийн төрлийн нөхцөл: төлөх хоног, хуваан төлөлт, эрт төлөлтийн хөнгөлөлт ]
+	MedPaymentTerm struct {
+		Base
+		PaymentTypeID   uint                         `gorm:"column:payment_type_id;not null;uniqueIndex" json:"payment_type_id"` // Төлбөрийн төрөл
+		PaymentType     *MedPaymentType              `gorm:"foreignKey:PaymentTypeID" json:"payment_type"`                       //
+		NetDays         int                          `gorm:"column:net_days;not null;default:0" json:"net_days"`                 // Зарлагаас хойш төлөх хоног
+		DiscountPercent float64                      `gorm:"column:discount_percent;default:0" json:"discount_percent"`          // Эрт төлбөл хөнгөлөх хувь
+		DiscountDays    int                          `gorm:"column:discount_days;default:0" json:"discount_days"`                // Хөнгөлөлт эдлэх хоног
+		Description     string                       `gorm:"column:description" json:"description"`                              // Тайлбар
+		IsActive        bool                         `gorm:"column:is_active;default:true" json:"is_active"`                     //
+		Installments    []*MedPaymentTermInstallment `gorm:"foreignKey:PaymentTermID" json:"installments"`                       // Хоосон бол NetDays-д бүтэн дүнгээр
+		CreatedUserID   uint                         `gorm:"column:created_user_id" json:"created_user_id"`                      //
+		ModifiedUserID  uint                         `gorm:"column:modified_user_id" json:"modified_user_id"`                    //
+		CreatedUser     *MedSystemUser               `gorm:"foreignKey:CreatedUserID" json:"created_user"`                       // Үүсгэсэн хэрэглэгч
+		ModifiedUser    *MedSystemUser               `gorm:"foreignKey:ModifiedUserID" json:"modified_user"`                     // Өөрчилсөн хэрэглэгч
+	}
+
+	// MedPaymentTermInstallment [ Хуваан төлөлтийн нэг хэсэг ]
+	MedPaymentTermInstallment struct {
+		Base
+		PaymentTermID uint    `gorm:"column:payment_term_id;not null;index" json:"payment_term_id"` //
+		Seq           int     `gorm:"column:seq;not null" json:"seq"`                               // Дараалал
+		Percent       float64 `gorm:"column:percent;not null" json:"percent"`                       // Нийт дүнгийн хувь
+		DueDays       int     `gorm:"column:due_days;not null" json:"due_days"`                     // Зарлагаас хойш төлөх хоног
+	}
+
+	// MedOutcomeInstallment [ Зарлагын төлбөрийн хуваарь ]
+	MedOutcomeInstallment struct {
+		Base
+		OutcomeID      uint         `gorm:"column:outcome_id;not null;uniqueIndex:idx_outcome_installment" json:"outcome_id"` // Зарлага
+		CustomerID     uint         `gorm:"column:customer_id;not null;index" json:"customer_id"`                             //
+		Customer       *MedCustomer `gorm:"foreignKey:CustomerID" json:"customer"`                                            //
+		PaymentTypeID  uint         `gorm:"column:payment_type_id" json:"payment_type_id"`                                    // Хуваарь гаргасан төлбөрийн төрөл
+		Seq            int          `gorm:"column:seq;not null;uniqueIndex:idx_outcome_installment" json:"seq"`               // Дараалал
+		DueDate        time.Time    `gorm:"column:due_date;type:date;not null;index" json:"due_date"`                         // Төлөх огноо
+		Amount         Decimal      `gorm:"column:amount;type:numeric(20,4);not null" json:"amount"`                          // Төлөх дүн, үндсэн валютаар
+		PaidAmount     Decimal      `gorm:"column:paid_amount;type:numeric(20,4);default:0" json:"paid_amount"`               // Төлсөн дүн
+		DiscountDate   *time.Time   `gorm:"column:discount_date;type:date" json:"discount_date"`                              // Энэ өдрийг хүртэл төлбөл хөнгөлөлттэй
+		DiscountAmount Decimal      `gorm:"column:discount_amount;type:numeric(20,4);default:0" json:"discount_amount"`       // Эрт төлөлтийн хөнгөлөлт
+		IsPaid         bool         `gorm:"column:is_paid;default:false" json:"is_paid"`                                      // Төлөгдсөн эсэх
+		PaidDate       *time.Time   `gorm:"column:paid_date" json:"paid_date"`                                                // Төлөгдсөн гэж тэмдэглэсэн огноо
+		NotifiedDate   *time.Time   `gorm:"column:notified_date" json:"notified_date"`                                        // Хугацаа хэтэрсэн тухай сүүлд мэдэгдсэн огноо
+	}
+)
@@ -0,0 +1,49 @@
+package databases
+
+import "time"
+
+type (
+	// MedPriceChange [ Үнийн өөрчлөлтийн баримт ]
+	MedPriceChange struct {
+		Base
+		Code           string               `gorm:"column:code;unique;not null" json:"code"`              // Баримтын дугаар
+		Description    string               `gorm:"column:description" json:"description"`                // Тайлбар
+		EffectiveDate  time.Time            `gorm:"column:effective_date;not null" json:"effective_date"` // Хэрэгжих огноо
+		State          string               `gorm:"column:state;not null;index" json:"state"`             // draft, approved, applied, rejected, failed
+		ApprovedUserID uint                 `gorm:"column:approved_user_id" json:"approved_user_id"`      //
+		ApprovedUser   *MedSystemUser       `gorm:"foreignKey:ApprovedUserID" json:"approved_user"`       // Баталсан хэрэглэгч
+		ApprovedDate   *time.Time           `gorm:"column:approved_date" json:"approved_date"`            // Баталсан огноо
+		AppliedDate    *time.Time           `gorm:"column:applied_date" json:"applied_date"`              // Хэрэгжсэн огноо
+		RejectReason   string               `gorm:"column:reject_reason" json:"reject_reason"`            // Татгалзсан шалтгаан
+		ApplyError     string               `gorm:"column:apply_error" json:"apply_error"`                // Хэрэгжүүлэх үеийн алдаа
+		Lines          []*MedPriceChangeDtl `gorm:"foreignKey:PriceChangeID" json:"lines"`                //
+		CreatedUserID  uint                 `gorm:"column:created_user_id" json:"created_user_id"`        //
+		ModifiedUserID uint                 `gorm:"column:modified_user_id" json:"modified_user_id"`      //
+		CreatedUser    *MedSystemUser       `gorm:"foreignKey:CreatedUserID" json:"created_user"`         // Үүсгэсэн хэрэглэгч
+		ModifiedUser   *MedSystemUser       `gorm:"foreignKey:ModifiedUserID" json:"modified_user"`       // Өөрчилсөн хэрэглэгч
+	}
+
+	// MedPriceChangeDtl [ Үнийн өөрчлөлтийн мөр, хуучин шинэ утга ]
+	MedPriceChangeDtl struct {
+		Base
+		PriceChangeID   uint              `gorm:"column:price_change_id;not null;index" json:"price_change_id"` //
+		PriceChange     *MedPriceChange   `gorm:"foreignKey:PriceChangeID" json:"price_change"`                 //
+		Kind            string            `gorm:"column:kind;not null" json:"kind"`                             // warehouse, customer
+		CustomerID      uint              `gorm:"column:customer_id;index" json:"customer_id"`                  // Харилцагчийн үнэ бол
+		Customer        *MedCustomer      `gorm:"foreignKey:CustomerID" json:"customer"`                        //
+		ItemID          uint              `gorm:"column:item_id;index" json:"item_id"`                          //
+		Item            *MedItem          `gorm:"foreignKey:ItemID" json:"item"`                                //
+		WarehouseItemID uint              `gorm:"column:warehouse_item_id;index" json:"warehouse_item_id"`      // Агуулахын үнэ бол
+		WarehouseItem   *MedWarehouseItem `gorm:"foreignKey:WarehouseItemID" json:"warehouse_item"`             //
+		PriceTypeID     uint              `gorm:"column:price_type_id" json:"price_type_id"`                    //
+		PriceType       *MedPriceType     `gorm:"foreignKey:PriceTypeID" json:"price_type"`                     //
+		IsPercent       bool              `gorm:"column:is_percent" json:"is_percent"`                          //
+		OldPrice        Decimal           `gorm:"column:old_price;type:numeric(20,4)" json:"old_price"`         // Хуучин үнэ
+		NewPrice        Decimal           `gorm:"column:new_price;type:numeric(20,4)" json:"new_price"`         // Шинэ үнэ
+		OldPercent      float64           `gorm:"column:old_percent" json:"old_percent"`                        // Хуучин хувь
+		NewPercent      float64           `gorm:"column:new_percent" json:"new_percent"`                        // Шинэ хувь
+		StartDate       *time.Time        `gorm:"column:start_date" json:"start_date"`                          // Харилцагчийн үнийн хүчинтэй хугацаа
+		EndDate         *time.Time        `gorm:"column:end_date" json:"end_date"`                              //
+		AppliedRecordID uint              `gorm:"column:applied_record_id" json:"applied_record_id"`            // Үүссэн үнийн мөр
+	}
+)
@@ -0,0 +1,31 @@
+package databases
+
+import "time"
+
+type (
+	// MedPriceRule [ Харилцагчийн төрөл, ангиллаар тогтоох үнийн дүрэм ]
+	MedPriceRule struct {
+		Base
+		Name             string                     `gorm:"column:name;not null" json:"name"`                         // Дүрмийн нэр
+		CustomerTypeID   uint                       `gorm:"column:customer_type_id;index" json:"customer_type_id"`    // 0 бол бүх төрөл
+		CustomerType     *MedCustomerType           `gorm:"foreignKey:CustomerTypeID" json:"customer_type"`           //
+		ClassificationID uint                       `gorm:"column:classification_id;index" json:"classification_id"`  // 0 бол бүх ангилал
+		Classification   *MedCustomerClassification `gorm:"foreignKey:ClassificationID" json:"classification"`        //
+		ItemID           uint                       `gorm:"column:item_id;index" json:"item_id"`                      // 0 бол бүх бараа
+		Item             *MedItem                   `gorm:"foreignKey:ItemID" json:"item"`                            //
+		PriceTypeID      uint                       `gorm:"column:price_type_id" json:"price_type_id"`                // Суурь үнийн төрөл
+		PriceType        *MedPriceType              `gorm:"foreignKey:PriceTypeID" json:"price_type"`                 //
+		MinQuantity      float64                    `gorm:"column:min_quantity" json:"min_quantity"`                  // Хамгийн бага тоо хэмжээ
+		IsPercent        bool                       `gorm:"column:is_percent" json:"is_percent"`                      // Хувиар эсэх
+		Percent          float64                    `gorm:"column:percent" json:"percent"`                            // Суурь үнэд нэмэх хувь, хөнгөлөлт бол хасах
+		SalesPrice       Decimal                    `gorm:"column:sales_price;type:numeric(20,4)" json:"sales_price"` // Тогтмол үнэ
+		Priority         int                        `gorm:"column:priority" json:"priority"`                          // Их нь түрүүлж хэрэгжинэ
+		StartDate        time.Time                  `gorm:"column:start_date;not null" json:"start_date"`             //
+		EndDate          time.Time                  `gorm:"column:end_date;not null" json:"end_date"`                 //
+		IsActive         bool                       `gorm:"column:is_active" json:"is_active"`                        // Идэвхитэй эсэх
+		CreatedUserID    uint                       `gorm:"column:created_user_id" json:"created_user_id"`            //
+		ModifiedUserID   uint                       `gorm:"column:modified_user_id" json:"modified_user_id"`          //
+		CreatedUser      *MedSystemUser             `gorm:"foreignKey:CreatedUserID" json:"created_user"`             // Үүсгэсэн хэрэглэгч
+		ModifiedUser     *MedSystemUser             `gorm:"foreignKey:ModifiedUserID" json:"modified_user"`           // Өөрчилсөн хэрэглэгч
+	}
+)
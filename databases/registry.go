@@ -0,0 +1,19 @@
+package databases
+
+import "time"
+
+type (
+	// MedCompanyRegistryCache [ Улсын бүртгэлээс татсан байгууллагын мэдээллийн кэш ]
+	MedCompanyRegistryCache struct {
+		Base
+		RegistryNumber         string    `gorm:"column:registry_number;unique;not null" json:"registry_number"`     // Байгууллагын РД
+		Provider               string    `gorm:"column:provider;not null" json:"provider"`                          // ebarimt, file
+		Found                  bool      `gorm:"column:found;default:false" json:"found"`                           // Бүртгэлтэй эсэх
+		Name                   string    `gorm:"column:name" json:"name"`                                           // Байгууллагын нэр
+		VatPayer               bool      `gorm:"column:vat_payer;default:false" json:"vat_payer"`                   // НӨАТ төлөгч эсэх
+		CityPayer              bool      `gorm:"column:city_payer;default:false" json:"city_payer"`                 // НХАТ төлөгч эсэх
+		VatPayerRegisteredDate string    `gorm:"column:vat_payer_registered_date" json:"vat_payer_registered_date"` // НӨАТ төлөгчөөр бүртгүүлсэн огноо
+		Payload                string    `gorm:"column:payload;type:text" json:"-"`                                 // Эх хариу
+		ExpireDate             time.Time `gorm:"column:expire_date;not null" json:"expire_date"`                    // Кэш хүчинтэй хугацаа
+	}
+)
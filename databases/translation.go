@@ -0,0 +1,16 @@
+package databases
+
+type (
+	// MedTranslation [ Лавлах мэдээллийн нэрийн орчуулга ]
+	MedTranslation struct {
+		Base
+		EntityType     string         `gorm:"column:entity_type;not null;uniqueIndex:idx_translation_entity" json:"entity_type"` // Хүснэгтийн нэр, ref_countries
+		EntityID       uint           `gorm:"column:entity_id;not null;uniqueIndex:idx_translation_entity" json:"entity_id"`     //
+		Locale         string         `gorm:"column:locale;not null;uniqueIndex:idx_translation_entity" json:"locale"`           // en, ru, zh-cn
+		Name           string         `gorm:"column:name;not null" json:"name"`                                                  // Орчуулсан нэр
+		CreatedUserID  uint           `gorm:"column:created_user_id" json:"created_user_id"`                                     //
+		ModifiedUserID uint           `gorm:"column:modified_user_id" json:"modified_user_id"`                                   //
+		CreatedUser    *MedSystemUser `gorm:"foreignKey:CreatedUserID" json:"created_user"`                                      // Үүсгэсэн хэрэглэгч
+		ModifiedUser   *MedSystemUser `gorm:"foreignKey:ModifiedUserID" json:"modified_user"`                                    // Өөрчилсөн хэрэглэгч
+	}
+)
@@ -147,6 +147,48 @@ type (
 		ModifiedUser          *MedSystemUser             `gorm:"foreignKey:ModifiedUserID" json:"modified_user"`                 // Өөрчилсөн хэрэглэгч
 		ParentID              uint                       `gorm:"column:parent_id" json:"parent_id"`                              //
 		Parent                *MedCustomer               `gorm:"foreignKey:ParentID" json:"parent"`                              // Харьяалагдах
+		Documents             []*MedCustomerDocument     `gorm:"foreignKey:CustomerID" json:"documents"`                         // Бичиг баримтын хувилбарууд
+		VatPayer              bool                       `gorm:"column:vat_payer;default:false" json:"vat_payer"`                // НӨАТ төлөгч эсэх
+		CityPayer             bool                       `gorm:"column:city_payer;default:false" json:"city_payer"`              // НХАТ төлөгч эсэх
+		VatRegisteredDate     string                     `gorm:"column:vat_registered_date" json:"vat_registered_date"`          // НӨАТ төлөгчөөр бүртгүүлсэн огноо
+		TaxCheckedDate        *time.Time                 `gorm:"column:tax_checked_date" json:"tax_checked_date"`                // Татварын мэдээлэл шалгасан огноо
+	}
+
+	// MedCustomerTaxHistory [ Харилцагчийн татвар төлөгчийн төлөв өөрчлөгдсөн түүх ]
+	MedCustomerTaxHistory struct {
+		Base
+		CustomerID        uint           `gorm:"column:customer_id;not null" json:"customer_id"`        //
+		Customer          *MedCustomer   `gorm:"foreignKey:CustomerID" json:"customer"`                 //
+		RegistryNumber    string         `gorm:"column:registry_number" json:"registry_number"`         // Байгууллагын РД
+		OldVatPayer       bool           `gorm:"column:old_vat_payer" json:"old_vat_payer"`             //
+		NewVatPayer       bool           `gorm:"column:new_vat_payer" json:"new_vat_payer"`             //
+		OldCityPayer      bool           `gorm:"column:old_city_payer" json:"old_city_payer"`           //
+		NewCityPayer      bool           `gorm:"column:new_city_payer" json:"new_city_payer"`           //
+		OldRegisteredDate string         `gorm:"column:old_registered_date" json:"old_registered_date"` // НӨАТ төлөгчөөр бүртгүүлсэн огноо
+		NewRegisteredDate string         `gorm:"column:new_registered_date" json:"new_registered_date"` //
+		Description       string         `gorm:"column:description;" json:"description"`                // Тайлбар
+		CreatedUserID     uint           `gorm:"column:created_user_id" json:"created_user_id"`         // Гараар шалгасан бол хэрэглэгч
+		CreatedUser       *MedSystemUser `gorm:"foreignKey:CreatedUserID" json:"created_user"`          // Үүсгэсэн хэрэглэгч
+	}
+
+	// MedCustomerDocument [ Харилцагчийн бичиг баримтын хувилбар ]
+	MedCustomerDocument struct {
+		Base
+		CustomerID     uint            `gorm:"column:customer_id;not null" json:"customer_id"`         //
+		Customer       *MedCustomer    `gorm:"foreignKey:CustomerID" json:"customer"`                  //
+		ContentTypeID  uint            `gorm:"column:content_type_id;not null" json:"content_type_id"` // Тусгай зөвшөөрөл, гэрчилгээ, үнэмлэх
+		ContentType    *MedContentType `gorm:"foreignKey:ContentTypeID" json:"content_type"`           //
+		ContentID      uint            `gorm:"column:content_id;not null" json:"content_id"`           // Файл
+		Content        *MedContent     `gorm:"foreignKey:ContentID" json:"content"`                    //
+		Version        uint            `gorm:"column:version;not null" json:"version"`                 // Хувилбарын дугаар
+		IsCurrent      bool            `gorm:"column:is_current;default:false" json:"is_current"`      // Хүчинтэй хувилбар эсэх
+		SupersededByID uint            `gorm:"column:superseded_by_id" json:"superseded_by_id"`        // Орлуулсан хувилбар
+		SupersededDate *time.Time      `gorm:"column:superseded_date" json:"superseded_date"`          // Орлуулагдсан огноо
+		Description    string          `gorm:"column:description;" json:"description"`                 // Тайлбар
+		CreatedUserID  uint            `gorm:"column:created_user_id" json:"created_user_id"`          //
+		ModifiedUserID uint            `gorm:"column:modified_user_id" json:"modified_user_id"`        //
+		CreatedUser    *MedSystemUser  `gorm:"foreignKey:CreatedUserID" json:"created_user"`           // Үүсгэсэн хэрэглэгч
+		ModifiedUser   *MedSystemUser  `gorm:"foreignKey:ModifiedUserID" json:"modified_user"`         // Өөрчилсөн хэрэглэгч
 	}
 
 	// MedCustomerAddress [  ]
@@ -166,6 +208,7 @@ type (
 		AddressType    *MedAddressType `gorm:"foreignKey:AddressTypeID" json:"address_type"`    //
 		Description    string          `gorm:"column:description;" json:"description"`          // Тайлбар
 		IsActive       bool            `gorm:"column:is_active;default:false" json:"is_active"` //
+		IsDefault      bool            `gorm:"column:is_default" json:"is_default"`             // Тухайн төрлийн үндсэн хаяг
 		CreatedUserID  uint            `gorm:"column:created_user_id" json:"created_user_id"`   //
 		ModifiedUserID uint            `gorm:"column:modified_user_id" json:"modified_user_id"` //
 		CreatedUser    *MedSystemUser  `gorm:"foreignKey:CreatedUserID" json:"created_user"`    // Үүсгэсэн хэрэглэгч
@@ -176,6 +219,7 @@ type (
 	MedAddressType struct {
 		Base
 		Name           string         `gorm:"column:name;not null" json:"name"`                //
+		Code           string         `gorm:"column:code;size:64;index" json:"code"`           // Системд ашиглах код
 		IsActive       bool           `gorm:"column:is_active;default:false" json:"is_active"` //
 		CreatedUserID  uint           `gorm:"column:created_user_id" json:"created_user_id"`   //
 		ModifiedUserID uint           `gorm:"column:modified_user_id" json:"modified_user_id"` //
@@ -200,14 +244,20 @@ type (
 	// MedCustomerClassification [ ]
 	MedCustomerClassification struct {
 		Base
-		Name           string         `gorm:"column:name;not null" json:"name"`                 //
-		IsActive       bool           `gorm:"column:is_active;default:false" json:"is_active"`  //
-		Description    string         `gorm:"column:description;" json:"description"`           // Тайлбар
-		CreatedUserID  uint           `gorm:"column:created_user_id" json:"created_user_id"`    //
-		ModifiedUserID uint           `gorm:"column:modified_user_id" json:"modified_user_id"`  //
-		CreatedUser    *MedSystemUser `gorm:"foreignKey:CreatedUserID" json:"created_user"`     // Үүсгэсэн хэрэглэгч
-		ModifiedUser   *MedSystemUser `gorm:"foreignKey:ModifiedUserID" json:"modified_user"`   // Өөрчилсөн хэрэглэгч
-		MedCustomers   []*MedCustomer `gorm:"foreignKey:ClassificationID" json:"med_customers"` //
+		Name           string         `gorm:"column:name;not null" json:"name"`                           //
+		Code           string         `gorm:"column:code;size:64;index" json:"code"`                      // Системд ашиглах код
+		IsActive       bool           `gorm:"column:is_active;default:false" json:"is_active"`            //
+		Description    string         `gorm:"column:description;" json:"description"`                     // Тайлбар
+		Rank           int            `gorm:"column:rank" json:"rank"`                                    // Их нь дээд ангилал, эхэлж шалгана
+		MinPurchase    Decimal        `gorm:"column:min_purchase;type:numeric(20,4)" json:"min_purchase"` // Хугацаанд хамгийн бага худалдан авалт
+		MinOrderCount  int            `gorm:"column:min_order_count" json:"min_order_count"`              // Хугацаанд хамгийн бага захиалгын тоо
+		MaxAvgPayDays  float64        `gorm:"column:max_avg_pay_days" json:"max_avg_pay_days"`            // Төлбөр төлөх дундаж хоног, 0 бол шалгахгүй
+		IsAutoAssign   bool           `gorm:"column:is_auto_assign" json:"is_auto_assign"`                // Автоматаар ангилах дүрэм идэвхитэй эсэх
+		CreatedUserID  uint           `gorm:"column:created_user_id" json:"created_user_id"`              //
+		ModifiedUserID uint           `gorm:"column:modified_user_id" json:"modified_user_id"`            //
+		CreatedUser    *MedSystemUser `gorm:"foreignKey:CreatedUserID" json:"created_user"`               // Үүсгэсэн хэрэглэгч
+		ModifiedUser   *MedSystemUser `gorm:"foreignKey:ModifiedUserID" json:"modified_user"`             // Өөрчилсөн хэрэглэгч
+		MedCustomers   []*MedCustomer `gorm:"foreignKey:ClassificationID" json:"med_customers"`           //
 	}
 
 	// MedCustomerContacts [ ]
@@ -224,6 +274,7 @@ type (
 		PhoneNumber2   string          `gorm:"column:phone_number2" json:"phone_number2"`       //
 		Email1         string          `gorm:"column:email1" json:"email1"`                     //
 		Email2         string          `gorm:"column:email2" json:"email2"`                     //
+		IsPrimary      bool            `gorm:"column:is_primary" json:"is_primary"`             // Үндсэн холбоо барих хүн
 		IsActive       bool            `gorm:"column:is_active;default:false" json:"is_active"` //
 		CreatedUserID  uint            `gorm:"column:created_user_id" json:"created_user_id"`   //
 		ModifiedUserID uint            `gorm:"column:modified_user_id" json:"modified_user_id"` //
@@ -252,6 +303,7 @@ type (
 	MedContentType struct {
 		Base
 		Name           string         `gorm:"column:name;not null" json:"name"`                //
+		Code           string         `gorm:"column:code;size:64;index" json:"code"`           // Системд ашиглах код
 		IsActive       bool           `gorm:"column:is_active;default:false" json:"is_active"` //
 		ParentID       uint           `gorm:"column:parent_id" json:"parent_id"`
 		CreatedUserID  uint           `gorm:"column:created_user_id" json:"created_user_id"`   //
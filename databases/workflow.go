@@ -0,0 +1,35 @@
+package databases
+
+type (
+	// MedStatusTransition [ Баримтын төрөл тус бүрийн зөвшөөрөгдсөн төлөвийн шилжилт ]
+	MedStatusTransition struct {
+		Base
+		DocumentType   string           `gorm:"column:document_type;not null;index" json:"document_type"`    // customer, order_book, income, outcome
+		Name           string           `gorm:"column:name" json:"name"`                                     // Үйлдлийн нэр
+		FromStatusID   uint             `gorm:"column:from_status_id;not null" json:"from_status_id"`        //
+		FromStatus     *MedStatus       `gorm:"foreignKey:FromStatusID" json:"from_status"`                  // Одоогийн төлөв
+		ToStatusID     uint             `gorm:"column:to_status_id;not null" json:"to_status_id"`            //
+		ToStatus       *MedStatus       `gorm:"foreignKey:ToStatusID" json:"to_status"`                      // Шилжих төлөв
+		RequireComment bool             `gorm:"column:require_comment;default:false" json:"require_comment"` // Тайлбар заавал эсэх
+		Roles          []*MedSystemRole `gorm:"many2many:map_transitions_roles;" json:"roles"`               // Шилжүүлэх эрхтэй дүрүүд, хоосон бол бүгд
+		IsActive       bool             `gorm:"column:is_active;default:false" json:"is_active"`             //
+		CreatedUserID  uint             `gorm:"column:created_user_id" json:"created_user_id"`               //
+		ModifiedUserID uint             `gorm:"column:modified_user_id" json:"modified_user_id"`             //
+		CreatedUser    *MedSystemUser   `gorm:"foreignKey:CreatedUserID" json:"created_user"`                // Үүсгэсэн хэрэглэгч
+		ModifiedUser   *MedSystemUser   `gorm:"foreignKey:ModifiedUserID" json:"modified_user"`              // Өөрчилсөн хэрэглэгч
+	}
+
+	// MedStatusNode [ Төлөвийн төрлийн шилжилтийн граф дахь төлөвийн дараалал ]
+	MedStatusNode struct {
+		Base
+		StatusTypeID   uint           `gorm:"column:status_type_id;not null;index" json:"status_type_id"` // Төлөвийн төрөл
+		StatusID       uint           `gorm:"column:status_id;not null;uniqueIndex" json:"status_id"`     //
+		Status         *MedStatus     `gorm:"foreignKey:StatusID" json:"status"`                          //
+		Seq            int            `gorm:"column:seq;not null;default:0" json:"seq"`                   // Харуулах дараалал
+		IsStart        bool           `gorm:"column:is_start;default:false" json:"is_start"`              // Анхны төлөв
+		CreatedUserID  uint           `gorm:"column:created_user_id" json:"created_user_id"`              //
+		ModifiedUserID uint           `gorm:"column:modified_user_id" json:"modified_user_id"`            //
+		CreatedUser    *MedSystemUser `gorm:"foreignKey:CreatedUserID" json:"created_user"`               // Үүсгэсэн хэрэглэгч
+		ModifiedUser   *MedSystemUser `gorm:"foreignKey:ModifiedUserID" json:"modified_user"`             // Өөрчилсөн хэрэглэгч
+	}
+)
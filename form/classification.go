@@ -1,17 +1,31 @@
 package form
 
+import "gitlab.com/fibocloud/medtech/gin/databases"
+
 // ClassificationCreateParams create body params
 type ClassificationCreateParams struct {
-	Name        string `json:"name" binding:"required"`
-	Description string `json:"description"`
-	IsActive    bool   `json:"is_active"`
+	Name          string            `json:"name" binding:"required"`
+	Code          string            `json:"code" binding:"max=64"` // Системд ашиглах код
+	Description   string            `json:"description"`
+	IsActive      bool              `json:"is_active"`
+	Rank          int               `json:"rank"`                             // Их нь дээд ангилал
+	MinPurchase   databases.Decimal `json:"min_purchase" binding:"gte=0"`     // Хамгийн бага худалдан авалт
+	MinOrderCount int               `json:"min_order_count" binding:"gte=0"`  // Хамгийн бага захиалгын тоо
+	MaxAvgPayDays float64           `json:"max_avg_pay_days" binding:"gte=0"` // Төлбөр төлөх дундаж хоног
+	IsAutoAssign  bool              `json:"is_auto_assign"`                   // Автоматаар ангилах эсэх
 }
 
 // ClassificationUpdateParams update body params
 type ClassificationUpdateParams struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	IsActive    bool   `json:"is_active"`
+	Name          string            `json:"name"`
+	Code          string            `json:"code" binding:"max=64"` // Системд ашиглах код
+	Description   string            `json:"description"`
+	IsActive      bool              `json:"is_active"`
+	Rank          int               `json:"rank"`                             // Их нь дээд ангилал
+	MinPurchase   databases.Decimal `json:"min_purchase" binding:"gte=0"`     // Хамгийн бага худалдан авалт
+	MinOrderCount int               `json:"min_order_count" binding:"gte=0"`  // Хамгийн бага захиалгын тоо
+	MaxAvgPayDays float64           `json:"max_avg_pay_days" binding:"gte=0"` // Төлбөр төлөх дундаж хоног
+	IsAutoAssign  bool              `json:"is_auto_assign"`                   // Автоматаар ангилах эсэх
 }
 
 // ClassFilterCols sort hiih bolomjtoi column
@@ -3,6 +3,7 @@ package form
 // CountryParams create body params
 type CountryParams struct {
 	Name string `json:"name" binding:"required"`
+	Code string `json:"code" binding:"max=64"` // Системд ашиглах код
 }
 
 // CountryFilterCols sort hiih bolomjtoi column
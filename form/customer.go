@@ -1,33 +1,100 @@
 package form
 
 import (
+	"strings"
 	"time"
 
 	"gitlab.com/fibocloud/medtech/gin/databases"
+	services "gitlab.com/fibocloud/medtech/gin/services"
+	structs "gitlab.com/fibocloud/medtech/gin/structs"
 )
 
 // CustomerCreateParams create body params
 type CustomerCreateParams struct {
-	Name                 string                           `form:"name" binding:"required"`
-	Description          string                           `form:"description"`
-	ParentID             int                              `form:"parent_id"`
-	CompanyRD            string                           `form:"company_rd"`
-	PaymentTypeID        int                              `form:"payment_type_id"`
-	MaximumReceivables   float64                          `form:"maximum_receivables"`
-	OneTimePurchaseLimit float64                          `form:"one_time_purchase_limit"`
-	Types                []*databases.MedCustomerType     `form:"customer_types" binding:"required"`
-	ClassificationID     int                              `form:"classification_id" binding:"required"`
-	CityID               int                              `form:"city_id" binding:"required"`
-	DistrictID           int                              `form:"district_id" binding:"required"`
-	Addresses            []*databases.MedCustomerAddress  `form:"addresses"`
-	Contacts             []*databases.MedCustomerContacts `form:"contacts"`
+	Name                 string                       `json:"name" binding:"required,max=255"`
+	Description          string                       `json:"description"`
+	CountryID            int                          `json:"country_id" binding:"required,gt=0"`
+	CompanyRD            string                       `json:"company_rd"`                                // Монгол харилцагчид заавал
+	CompanyName          string                       `json:"company_name"`                              // Монгол харилцагчид заавал
+	CityID               int                          `json:"city_id"`                                   // Монгол харилцагчид заавал
+	DistrictID           int                          `json:"district_id"`                               // Монгол харилцагчид заавал
+	AddressDescription   string                       `json:"address_description"`                       // Гадаад харилцагчид заавал
+	PaymentTypeID        int                          `json:"payment_type_id" binding:"required,gt=0"`   // Төлбөрийн нөхцөл
+	ClassificationID     int                          `json:"classification_id" binding:"required,gt=0"` // Ангилал
+	MaximumPurchase      float64                      `json:"maximum_purchase" binding:"gte=0"`          //
+	MaximumReceivables   float64                      `json:"maximum_receivables" binding:"gte=0"`       //
+	OneTimePurchaseLimit float64                      `json:"one_time_purchase_limit" binding:"gte=0"`   //
+	Types                []*databases.MedCustomerType `json:"customer_types" binding:"required,min=1"`   //
+	Addresses            []CustomerAddressParams      `json:"addresses" binding:"dive"`                  //
+	Contacts             []CustomerContactParams      `json:"contacts" binding:"dive"`                   //
+}
+
+// CustomerRelationParams харилцагч засахад multipart-аар json болгон илгээх хаяг, холбоо барих хүн
+type CustomerRelationParams struct {
+	Addresses []CustomerAddressParams `json:"addresses" binding:"dive"` // Илгээсэн бол бүгдийг солино
+	Contacts  []CustomerContactParams `json:"contacts" binding:"dive"`  // Илгээсэн бол бүгдийг солино
+}
+
+// CustomerAddressParams харилцагчийн хаяг
+type CustomerAddressParams struct {
+	AddressTypeID uint   `json:"address_type_id" binding:"required,gt=0"`
+	CountryID     uint   `json:"country_id"` // Хоосон бол харилцагчийн улс
+	CityID        uint   `json:"city_id"`    // Хоосон бол харилцагчийн аймаг, хот
+	DistrictID    uint   `json:"district_id"`
+	StreetID      uint   `json:"street_id"`
+	Description   string `json:"description" binding:"max=500"`
+	IsDefault     bool   `json:"is_default"` // Тухайн төрлийн үндсэн хаяг
+}
+
+// CustomerContactParams харилцагчийн холбоо барих хүн
+type CustomerContactParams struct {
+	LastName       string `json:"last_name" binding:"required,max=100"`
+	FirstName      string `json:"first_name" binding:"required,max=100"`
+	RegisterNumber string `json:"register_number" binding:"max=20"`
+	PositionID     int    `json:"position_id"`
+	PhoneNumber1   string `json:"phone_number1" binding:"required,phone"`
+	PhoneNumber2   string `json:"phone_number2" binding:"omitempty,phone"`
+	Email1         string `json:"email1" binding:"omitempty,email"`
+	Email2         string `json:"email2" binding:"omitempty,email"`
+	IsPrimary      bool   `json:"is_primary"` // Үндсэн холбоо барих хүн
+}
+
+// Validate binding tag-аар илэрхийлэх боломжгүй, талбар хоорондын шалгалт
+func (p CustomerCreateParams) Validate() []structs.FieldError {
+	var fields []structs.FieldError
+	required := func(field string) {
+		fields = append(fields, structs.FieldError{
+			Field:   field,
+			Rule:    "required",
+			Message: "Заавал оруулна уу",
+		})
+	}
+
+	if p.CountryID == int(services.MongoliaCountryID()) {
+		if strings.TrimSpace(p.CompanyRD) == "" {
+			required("company_rd")
+		}
+		if strings.TrimSpace(p.CompanyName) == "" {
+			required("company_name")
+		}
+		if p.CityID == 0 {
+			required("city_id")
+		}
+		if p.DistrictID == 0 {
+			required("district_id")
+		}
+	} else if strings.TrimSpace(p.AddressDescription) == "" {
+		required("address_description")
+	}
+
+	return fields
 }
 
 // UpdateCustomerStatus ...
 type UpdateCustomerStatus struct {
 	CustomerID  int    `json:"customer_id" binding:"required"` //
 	StatusID    int    `json:"status_id" binding:"required"`   //
-	Description string `json:"description" binding:"required"` //
+	Description string `json:"description"`                    // Шилжилтийн тохиргооноос хамаарч заавал
 }
 
 // GetPriceDetailParam ...
@@ -36,6 +103,31 @@ type GetPriceDetailParam struct {
 	ItemID     int `json:"item_id" binding:"required"`     //
 }
 
+// CustomerMoveParams салбарыг өөр толгой байгууллага дор шилжүүлэх
+type CustomerMoveParams struct {
+	CustomerID uint `json:"customer_id" binding:"required"` //
+	ParentID   uint `json:"parent_id"`                      // 0 бол толгой байгууллага болно
+}
+
+// CustomerCreditCheckParams захиалгын дүнг зээлийн хязгаартай тулгах
+type CustomerCreditCheckParams struct {
+	CustomerID uint              `json:"customer_id" binding:"required"` //
+	Amount     databases.Decimal `json:"amount" binding:"gte=0"`         // Захиалгын дүн, үндсэн валютаар
+}
+
+// CustomerCreditOverrideParams менежер хязгаар хэтрүүлэхийг зөвшөөрөх
+type CustomerCreditOverrideParams struct {
+	OrderBookID uint   `json:"order_book_id" binding:"required"` // Харилцагч, дүнг захиалгаас уншина
+	Reason      string `json:"reason" binding:"required"`        // Шалтгаан
+}
+
+// CustomerMergeParams давхардсан харилцагчийг нэгтгэх
+type CustomerMergeParams struct {
+	SurvivorID  uint   `json:"survivor_id" binding:"required"` // Үлдэх харилцагч
+	MergedID    uint   `json:"merged_id" binding:"required"`   // Устгагдах харилцагч
+	Description string `json:"description"`                    // Тайлбар
+}
+
 // CustomerLoginPermissionParam ...
 type CustomerLoginPermissionParam struct {
 	CustomerID int    `json:"customer_id" binding:"required"`
@@ -99,3 +191,12 @@ type CustomerFilter struct {
 	Sort   SortColumn         `json:"sort"`
 	Filter CustomerFilterCols `json:"filter"`
 }
+
+// CustomerAgingParams авлагын насжилтын тайлангийн шүүлт
+type CustomerAgingParams struct {
+	AsOf             time.Time `json:"as_of"`             // Өгөөгүй бол өнөөдөр
+	ClassificationID uint      `json:"classification_id"` // Ангилал
+	DistrictID       uint      `json:"district_id"`       // Дүүрэг
+	ParentID         uint      `json:"parent_id"`         // Толгой байгууллага, салбаруудын хамт
+	ByDueDate        bool      `json:"by_due_date"`       // Хуваарьтай зарлагыг төлөх огнооноос насжуулах
+}
@@ -1,17 +1,21 @@
 package form
 
-import "time"
+import (
+	"time"
+
+	"gitlab.com/fibocloud/medtech/gin/databases"
+)
 
 // IncomeParams update body params
 type IncomeParams struct {
 	OrderBookID        uint                   `json:"order_book_id" binding:"required"`   //
 	WareHouseID        uint                   `json:"warehouse_id" binding:"required"`    // Агуулах ID
 	PaymentTypeID      uint                   `json:"payment_type_id" binding:"required"` // Төлбөрийн төрлийн ID
-	CompostionCost     float64                `json:"compostion_cost"`                    // Бүрдүүлэлтийн зардал
-	TransportationCost float64                `json:"transportation_cost"`                // Тээвэрлэлтийн зардал
+	CompostionCost     databases.Decimal      `json:"compostion_cost"`                    // Бүрдүүлэлтийн зардал
+	TransportationCost databases.Decimal      `json:"transportation_cost"`                // Тээвэрлэлтийн зардал
 	CustomTaxPercent   float64                `json:"custom_tax_percent"`                 // Гааль 5%
 	Description        string                 `json:"description"`                        // Тайлбар
-	ValuteValue        float64                `json:"valute_value"`                       // Валют утга
+	ValuteValue        databases.Decimal      `json:"valute_value"`                       // Валютын ханш, хоосон бол баримтын өдрийн ханш
 	ValuteID           uint                   `json:"valute_id"`                          // Валют ID
 	IsVat              bool                   `json:"is_vat"`                             // Татвартай эсэх
 	IsDiscount         bool                   `json:"is_discount"`                        // Хямдарлтай эсэх
@@ -23,12 +27,12 @@ type IncomeParams struct {
 
 // DetailIncomeDtlParam ...
 type DetailIncomeDtlParam struct {
-	ID              uint      `json:"id"`                             // OrderBookDtlID
-	ItemID          uint      `json:"item_id" binding:"required"`     // Барааны ID
-	OrderQty        uint      `json:"order_qty" binding:"required"`   // орлого тоо
-	UnitPrice       float64   `json:"unit_price" binding:"required"`  // Нэгжийн үнэ
-	PercentDiscount uint      `json:"percent_discount"`               // Хөнгөлөлт/хувь/
-	ExpireDate      time.Time `json:"expire_date" binding:"required"` //
+	ID              uint              `json:"id"`                             // OrderBookDtlID
+	ItemID          uint              `json:"item_id" binding:"required"`     // Барааны ID
+	OrderQty        uint              `json:"order_qty" binding:"required"`   // орлого тоо
+	UnitPrice       databases.Decimal `json:"unit_price" binding:"required"`  // Нэгжийн үнэ, баримтын валютаар
+	PercentDiscount uint              `json:"percent_discount"`               // Хөнгөлөлт/хувь/
+	ExpireDate      time.Time         `json:"expire_date" binding:"required"` //
 	// PercentVat      float64   `json:"percent_vat" binding:"required"`   // НӨАТ-н хувь
 	SerialNumber string `json:"serial_number" binding:"required"` //
 }
@@ -50,11 +54,11 @@ type IncomeStatusChangeParams struct {
 
 // CheckDtlParam ...
 type CheckDtlParam struct {
-	InComeItemDtlID uint      `json:"in_come_item_dtl_id" `             //
-	ItemID          uint      `json:"item_id"`                          //
-	ExpireDate      time.Time `json:"expire_date" binding:"required"`   //
-	SerialNumber    string    `json:"serial_number" binding:"required"` //
-	Price           float64   `json:"price"`                            //
+	InComeItemDtlID uint              `json:"in_come_item_dtl_id" `             //
+	ItemID          uint              `json:"item_id"`                          //
+	ExpireDate      time.Time         `json:"expire_date" binding:"required"`   //
+	SerialNumber    string            `json:"serial_number" binding:"required"` //
+	Price           databases.Decimal `json:"price"`                            //
 }
 
 // IncomeFilterCols sort hiih bolomjtoi column
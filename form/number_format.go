@@ -0,0 +1,30 @@
+package form
+
+// NumberFormatParams create body params
+type NumberFormatParams struct {
+	Code        string `json:"code" binding:"required"`
+	Name        string `json:"name" binding:"required"`
+	Prefix      string `json:"prefix"`                          // Угтвар
+	DatePattern string `json:"date_pattern"`                    // YYYY, YY, MM, DD
+	Separator   string `json:"separator"`                       // Огноо, дугаар хооронд
+	Padding     int    `json:"padding" binding:"required"`      // Дугаарын оронгийн тоо
+	ResetPeriod string `json:"reset_period" binding:"required"` // never, yearly, monthly, daily
+	IsActive    bool   `json:"is_active"`
+}
+
+// NumberFormatFilterCols sort hiih bolomjtoi column
+type NumberFormatFilterCols struct {
+	Code        string `json:"code"`
+	Name        string `json:"name"`
+	Prefix      string `json:"prefix"`
+	ResetPeriod string `json:"reset_period"`
+	IsActive    string `json:"is_active"`
+}
+
+// NumberFormatFilter sort hiigdej boloh zuils
+type NumberFormatFilter struct {
+	Page   int                    `json:"page"`
+	Size   int                    `json:"size"`
+	Sort   SortColumn             `json:"sort"`
+	Filter NumberFormatFilterCols `json:"filter"`
+}
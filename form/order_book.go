@@ -14,7 +14,7 @@ type OrderBookInParams struct {
 	OrderSubTypeID    int                   `json:"order_sub_type_id" binding:"required"` // Захиалгын дэд төрөл / Дотоод, Импортын, Солилцооны /
 	PaymentTypeID     int                   `json:"payment_type_id" binding:"required"`   // Төлбөрийн төрлийн ID
 	DueDate           time.Time             `json:"due_date"`                             // Дуусах хугацаа
-	ValuteValue       float64               `json:"valute_value"`                         // Валют утга
+	ValuteValue       databases.Decimal     `json:"valute_value"`                         // Валютын ханш, хоосон бол баримтын өдрийн ханш
 	StatusDescription string                `json:"status_description"`                   // Төлөв шилжих үеийн тайлбар
 	ValuteID          int                   `json:"valute_id"`                            // Валют ID
 	IsVat             bool                  `json:"is_vat"`                               // Татвартай эсэх
@@ -41,11 +41,11 @@ type OrderBookOutParams struct {
 
 // DetailOrderBookOutParams ...
 type DetailOrderBookOutParams struct {
-	WarehouseItemID uint    `json:"warehouse_item_id"`              // WareHouseItemID
-	ItemID          uint    `json:"item_id"`                        // Барааны ID
-	PercentDiscount float64 `json:"percent_discount"`               // Хөнгөлөлт/хувь/
-	OutcomeQty      uint    `json:"outcome_qty" binding:"required"` // Зарлага тоо
-	Price           float64 `json:"price"`
+	WarehouseItemID uint              `json:"warehouse_item_id"`              // WareHouseItemID
+	ItemID          uint              `json:"item_id"`                        // Барааны ID
+	PercentDiscount float64           `json:"percent_discount"`               // Хөнгөлөлт/хувь/
+	OutcomeQty      uint              `json:"outcome_qty" binding:"required"` // Зарлага тоо
+	Price           databases.Decimal `json:"price"`
 }
 
 // RewardWarehouseItems ...
@@ -1,5 +1,7 @@
 package form
 
+import "gitlab.com/fibocloud/medtech/gin/databases"
+
 // OrderBookDtlParams update body params
 type OrderBookDtlParams struct {
 	OrderBookID uint                  `json:"order_book_id"` // Захиалгын ID
@@ -8,18 +10,18 @@ type OrderBookDtlParams struct {
 
 // DetailOrderDtlParam ...
 type DetailOrderDtlParam struct {
-	ID                uint    `json:"id"`                  //
-	ItemID            uint    `json:"item_id"`             // Барааны ID
-	OrderQty          uint    `json:"order_qty"`           // Захиалгын тоо
-	UnitPrice         float64 `json:"unit_price"`          // Нэгжийн үнэ
-	PercentDiscount   float64 `json:"percent_discount"`    // Хөнгөлөлт/хувь/
-	UnitDiscount      float64 `json:"unit_discount"`       // Нэгжийн хөнгөлсөн дүн
-	ItemTotalDiscount float64 `json:"item_total_discount"` // Тухайн барааны нийт хөнгөлсөн дүн
-	PercentVat        float64 `json:"percent_vat"`         // НӨАТ-н хувь
-	UnitVat           float64 `json:"unit_vat"`            // Нэгж НӨАТ
-	ItemTotalVat      float64 `json:"item_total_vat"`      // Тухайн барааны нийт НӨАТ
-	ItemTotalAmount   float64 `json:"item_total_amount"`   // Тухайн барааны нийт дүн
-	IsRemoved         bool    `json:"is_removed"`          // Буцаагдсан эсэх
+	ID                uint              `json:"id"`                  //
+	ItemID            uint              `json:"item_id"`             // Барааны ID
+	OrderQty          uint              `json:"order_qty"`           // Захиалгын тоо
+	UnitPrice         databases.Decimal `json:"unit_price"`          // Нэгжийн үнэ, баримтын валютаар
+	PercentDiscount   float64           `json:"percent_discount"`    // Хөнгөлөлт/хувь/
+	UnitDiscount      databases.Decimal `json:"unit_discount"`       // Нэгжийн хөнгөлсөн дүн
+	ItemTotalDiscount databases.Decimal `json:"item_total_discount"` // Тухайн барааны нийт хөнгөлсөн дүн
+	PercentVat        float64           `json:"percent_vat"`         // НӨАТ-н хувь
+	UnitVat           databases.Decimal `json:"unit_vat"`            // Нэгж НӨАТ
+	ItemTotalVat      databases.Decimal `json:"item_total_vat"`      // Тухайн барааны нийт НӨАТ
+	ItemTotalAmount   databases.Decimal `json:"item_total_amount"`   // Тухайн барааны нийт дүн, баримтын валютаар
+	IsRemoved         bool              `json:"is_removed"`          // Буцаагдсан эсэх
 }
 
 // OrderBookDtlFilterCols sort hiih bolomjtoi column
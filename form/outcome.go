@@ -1,6 +1,10 @@
 package form
 
-import "time"
+import (
+	"time"
+
+	"gitlab.com/fibocloud/medtech/gin/databases"
+)
 
 // OutcomeParams update body params
 type OutcomeParams struct {
@@ -9,7 +13,7 @@ type OutcomeParams struct {
 	WarehouseID   uint                    `json:"warehouse_id" binding:"required"`    // Агуулах ID
 	CustomerID    uint                    `json:"customer_id" binding:"required"`     // Харилцагчийн ID
 	PaymentTypeID uint                    `json:"payment_type_id" binding:"required"` // Төлбөрийн төрлийн ID
-	ValuteValue   float64                 `json:"valute_value"`                       // Валют утга
+	ValuteValue   databases.Decimal       `json:"valute_value"`                       // Валютын ханш, хоосон бол баримтын өдрийн ханш
 	ValuteID      uint                    `json:"valute_id"`                          // Валют ID
 	IsVat         bool                    `json:"is_vat"`                             // Татвартай эсэх
 	VatPercent    float64                 `json:"vat_percent"`                        // НӨАТ-н хувь
@@ -39,23 +43,23 @@ type OutcomeFindItem struct {
 
 // DetailOutcomeDtlParam ...
 type DetailOutcomeDtlParam struct {
-	ID                 uint      `json:"id"`                               // OrderBookDtlID
-	WareHouseID        uint      `json:"warehouse_id" binding:"required"`  // Агуулах ID
-	ItemID             uint      `json:"item_id" binding:"required"`       // Барааны ID
-	OutcomeQty         uint      `json:"income_qty" binding:"required"`    // орлого тоо
-	UnitPrice          float64   `json:"unit_price" binding:"required"`    // Нэгжийн үнэ
-	PercentDiscount    uint      `json:"percent_discount"`                 // Хөнгөлөлт/хувь/
-	UnitDiscount       uint      `json:"unit_discount"`                    // Нэгжийн хөнгөлсөн дүн
-	ItemTotalDiscount  uint      `json:"item_total_discount"`              // Тухайн барааны нийт хөнгөлсөн дүн
-	UnitVat            float64   `json:"unit_vat"`                         // Нэгж НӨАТ
-	ItemTotalVat       float64   `json:"item_total_vat"`                   // Тухайн барааны нийт НӨАТ
-	ItemTotalAmount    float64   `json:"item_total_amount"`                // Тухайн барааны нийт дүн
-	ExpireDate         time.Time `json:"expire_date" binding:"required"`   //
-	SerialNumber       string    `json:"serial_number" binding:"required"` //
-	TransportationCost float64   `json:"transportation_cost"`              //
-	CustomTax          float64   `json:"custom_tax"`                       //
-	CompostionCost     float64   `json:"compostion_cost"`                  //
-	Price              float64   `json:"price"`                            //
+	ID                 uint              `json:"id"`                               // OrderBookDtlID
+	WareHouseID        uint              `json:"warehouse_id" binding:"required"`  // Агуулах ID
+	ItemID             uint              `json:"item_id" binding:"required"`       // Барааны ID
+	OutcomeQty         uint              `json:"income_qty" binding:"required"`    // орлого тоо
+	UnitPrice          databases.Decimal `json:"unit_price" binding:"required"`    // Нэгжийн үнэ, баримтын валютаар
+	PercentDiscount    uint              `json:"percent_discount"`                 // Хөнгөлөлт/хувь/
+	UnitDiscount       databases.Decimal `json:"unit_discount"`                    // Нэгжийн хөнгөлсөн дүн
+	ItemTotalDiscount  databases.Decimal `json:"item_total_discount"`              // Тухайн барааны нийт хөнгөлсөн дүн
+	UnitVat            databases.Decimal `json:"unit_vat"`                         // Нэгж НӨАТ
+	ItemTotalVat       databases.Decimal `json:"item_total_vat"`                   // Тухайн барааны нийт НӨАТ
+	ItemTotalAmount    databases.Decimal `json:"item_total_amount"`                // Тухайн барааны нийт дүн, баримтын валютаар
+	ExpireDate         time.Time         `json:"expire_date" binding:"required"`   //
+	SerialNumber       string            `json:"serial_number" binding:"required"` //
+	TransportationCost databases.Decimal `json:"transportation_cost"`              //
+	CustomTax          databases.Decimal `json:"custom_tax"`                       //
+	CompostionCost     databases.Decimal `json:"compostion_cost"`                  //
+	Price              databases.Decimal `json:"price"`                            //
 }
 
 // OutcomeFilterCols sort hiih bolomjtoi column
@@ -0,0 +1,36 @@
+package form
+
+import (
+	"time"
+
+	"gitlab.com/fibocloud/medtech/gin/databases"
+)
+
+// CustomerPaymentParams create body params
+type CustomerPaymentParams struct {
+	CustomerID      uint              `json:"customer_id" binding:"required"`  //
+	OutcomeID       uint              `json:"outcome_id"`                      // Төлсөн зарлага
+	PaymentMethodID uint              `json:"payment_method_id"`               // Төлбөрийн хэлбэр
+	Amount          databases.Decimal `json:"amount" binding:"required,gt=0"`  // Төлсөн дүн, valute_id-ийн валютаар
+	ValuteID        uint              `json:"valute_id"`                       // Хоосон бол үндсэн валют
+	Rate            databases.Decimal `json:"rate" binding:"gte=0"`            // Хоосон бол төлсөн өдрийн ханш
+	PaymentDate     time.Time         `json:"payment_date" binding:"required"` // Төлсөн огноо
+	Description     string            `json:"description"`                     // Тайлбар
+}
+
+// CustomerPaymentFilterCols sort hiih bolomjtoi column
+type CustomerPaymentFilterCols struct {
+	CustomerID      int    `json:"customer_id"`
+	OutcomeID       int    `json:"outcome_id"`
+	PaymentMethodID int    `json:"payment_method_id"`
+	PaymentDate     string `json:"payment_date"`
+	Description     string `json:"description"`
+}
+
+// CustomerPaymentFilter sort hiigdej boloh zuils
+type CustomerPaymentFilter struct {
+	Page   int                       `json:"page"`
+	Size   int                       `json:"size"`
+	Sort   SortColumn                `json:"sort"`
+	Filter CustomerPaymentFilterCols `json:"filter"`
+}
@@ -0,0 +1,34 @@
+package form
+
+// PaymentTermParams create, update body params
+type PaymentTermParams struct {
+	PaymentTypeID   uint                           `json:"payment_type_id" binding:"required"`       // Төлбөрийн төрөл
+	NetDays         int                            `json:"net_days" binding:"min=0"`                 // Зарлагаас хойш төлөх хоног
+	DiscountPercent float64                        `json:"discount_percent" binding:"min=0,max=100"` // Эрт төлбөл хөнгөлөх хувь
+	DiscountDays    int                            `json:"discount_days" binding:"min=0"`            // Хөнгөлөлт эдлэх хоног
+	Description     string                         `json:"description"`                              // Тайлбар
+	IsActive        bool                           `json:"is_active"`                                //
+	Installments    []PaymentTermInstallmentParams `json:"installments" binding:"dive"`              // Хоосон бол net_days-д бүтэн дүнгээр
+}
+
+// PaymentTermInstallmentParams хуваан төлөлтийн нэг хэсэг
+type PaymentTermInstallmentParams struct {
+	Percent float64 `json:"percent" binding:"gt=0,max=100"` // Нийт дүнгийн хувь
+	DueDays int     `json:"due_days" binding:"min=0"`       // Зарлагаас хойш төлөх хоног
+}
+
+// PaymentTermFilterCols sort hiih bolomjtoi column
+type PaymentTermFilterCols struct {
+	PaymentTypeID int    `json:"payment_type_id"`
+	NetDays       int    `json:"net_days"`
+	Description   string `json:"description"`
+	IsActive      string `json:"is_active"`
+}
+
+// PaymentTermFilter sort hiigdej boloh zuils
+type PaymentTermFilter struct {
+	Page   int                   `json:"page"`
+	Size   int                   `json:"size"`
+	Sort   SortColumn            `json:"sort"`
+	Filter PaymentTermFilterCols `json:"filter"`
+}
@@ -0,0 +1,32 @@
+package form
+
+import "time"
+
+// PriceChangeParams үнийн өөрчлөлтийн баримт үүсгэх
+type PriceChangeParams struct {
+	Description    string                            `json:"description"`                       // Тайлбар
+	EffectiveDate  time.Time                         `json:"effective_date" binding:"required"` // Хэрэгжих огноо
+	WarehouseItems []*ChangePriceWareHouseItemParams `json:"warehouse_items"`                   // Агуулахын үнэ
+	Customer       *CustomerUpdatePriceParams        `json:"customer"`                          // Харилцагчийн тусгай үнэ
+}
+
+// PriceChangeRejectParams татгалзах шалтгаан
+type PriceChangeRejectParams struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// PriceChangeFilterCols sort hiih bolomjtoi column
+type PriceChangeFilterCols struct {
+	Code          string `json:"code"`
+	Description   string `json:"description"`
+	State         string `json:"state"`
+	EffectiveDate string `json:"effective_date"`
+}
+
+// PriceChangeFilter sort hiigdej boloh zuils
+type PriceChangeFilter struct {
+	Page   int                   `json:"page"`
+	Size   int                   `json:"size"`
+	Sort   SortColumn            `json:"sort"`
+	Filter PriceChangeFilterCols `json:"filter"`
+}
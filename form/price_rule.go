@@ -0,0 +1,51 @@
+package form
+
+import (
+	"time"
+
+	"gitlab.com/fibocloud/medtech/gin/databases"
+)
+
+// PriceRuleParams create, update body params
+type PriceRuleParams struct {
+	Name             string            `json:"name" binding:"required"`       // Дүрмийн нэр
+	CustomerTypeID   uint              `json:"customer_type_id"`              // 0 бол бүх төрөл
+	ClassificationID uint              `json:"classification_id"`             // 0 бол бүх ангилал
+	ItemID           uint              `json:"item_id"`                       // 0 бол бүх бараа
+	PriceTypeID      uint              `json:"price_type_id"`                 // Суурь үнийн төрөл
+	MinQuantity      float64           `json:"min_quantity" binding:"gte=0"`  // Хамгийн бага тоо хэмжээ
+	IsPercent        bool              `json:"is_percent"`                    // Хувиар эсэх
+	Percent          float64           `json:"percent"`                       // Суурь үнэд нэмэх хувь
+	SalesPrice       databases.Decimal `json:"sales_price" binding:"gte=0"`   // Тогтмол үнэ
+	Priority         int               `json:"priority"`                      // Их нь түрүүлж хэрэгжинэ
+	StartDate        time.Time         `json:"start_date" binding:"required"` //
+	EndDate          time.Time         `json:"end_date" binding:"required"`   //
+	IsActive         bool              `json:"is_active"`                     // Идэвхитэй эсэх
+}
+
+// PriceRuleFilterCols sort hiih bolomjtoi column
+type PriceRuleFilterCols struct {
+	Name             string `json:"name"`
+	CustomerTypeID   int    `json:"customer_type_id"`
+	ClassificationID int    `json:"classification_id"`
+	ItemID           int    `json:"item_id"`
+	PriceTypeID      int    `json:"price_type_id"`
+	IsActive         string `json:"is_active"`
+}
+
+// PriceRuleFilter sort hiigdej boloh zuils
+type PriceRuleFilter struct {
+	Page   int                 `json:"page"`
+	Size   int                 `json:"size"`
+	Sort   SortColumn          `json:"sort"`
+	Filter PriceRuleFilterCols `json:"filter"`
+}
+
+// PriceExplainParams харилцагчийн үнийг тооцоолох нөхцөл
+type PriceExplainParams struct {
+	CustomerID  uint      `json:"customer_id" binding:"required"` //
+	ItemID      uint      `json:"item_id" binding:"required"`     //
+	PriceTypeID uint      `json:"price_type_id"`                  // Өгөөгүй бол зарах үнэ
+	Date        time.Time `json:"date"`                           // Өгөөгүй бол өнөөдөр
+	Quantity    float64   `json:"quantity"`                       // Өгөөгүй бол 1
+}
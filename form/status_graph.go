@@ -0,0 +1,26 @@
+package form
+
+// StatusGraphParams төлөвийн төрлийн графыг бүхлээр нь солино
+type StatusGraphParams struct {
+	Nodes []StatusNodeParams `json:"nodes" binding:"required,min=1,dive"` // Төрлийн идэвхитэй бүх төлөв
+	Edges []StatusEdgeParams `json:"edges" binding:"dive"`                // Оруулаагүй шилжилт устна
+}
+
+// StatusNodeParams графын төлөв
+type StatusNodeParams struct {
+	StatusID  uint   `json:"status_id" binding:"required"`
+	Seq       int    `json:"seq"`        // Харуулах дараалал
+	IsStart   bool   `json:"is_start"`   // Анхны төлөв
+	ColorCode string `json:"color_code"` // Хоосон бол өөрчлөхгүй
+}
+
+// StatusEdgeParams графын шилжилт
+type StatusEdgeParams struct {
+	DocumentType   string `json:"document_type" binding:"required,oneof=customer order_book income outcome"`
+	Name           string `json:"name"`
+	FromStatusID   uint   `json:"from_status_id" binding:"required"`
+	ToStatusID     uint   `json:"to_status_id" binding:"required"`
+	RequireComment bool   `json:"require_comment"` // Тайлбар заавал эсэх
+	RoleIDs        []uint `json:"role_ids"`        // Хоосон бол бүх хэрэглэгч
+	IsActive       bool   `json:"is_active"`
+}
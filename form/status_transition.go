@@ -0,0 +1,37 @@
+package form
+
+// StatusTransitionParams create body params
+type StatusTransitionParams struct {
+	DocumentType   string `json:"document_type" binding:"required,oneof=customer order_book income outcome"`
+	Name           string `json:"name"`
+	FromStatusID   uint   `json:"from_status_id" binding:"required"`
+	ToStatusID     uint   `json:"to_status_id" binding:"required"`
+	RequireComment bool   `json:"require_comment"` // Тайлбар заавал эсэх
+	RoleIDs        []uint `json:"role_ids"`        // Хоосон бол бүх хэрэглэгч
+	IsActive       bool   `json:"is_active"`
+}
+
+// StatusChangeParams баримтын төлөв өөрчлөх
+type StatusChangeParams struct {
+	DocumentType string `json:"document_type" binding:"required,oneof=customer order_book income outcome"`
+	RecordID     uint   `json:"record_id" binding:"required"`
+	StatusID     uint   `json:"status_id" binding:"required"`
+	Description  string `json:"description"`
+}
+
+// StatusTransitionFilterCols sort hiih bolomjtoi column
+type StatusTransitionFilterCols struct {
+	DocumentType string `json:"document_type"`
+	Name         string `json:"name"`
+	FromStatusID int    `json:"from_status_id"`
+	ToStatusID   int    `json:"to_status_id"`
+	IsActive     string `json:"is_active"`
+}
+
+// StatusTransitionFilter sort hiigdej boloh zuils
+type StatusTransitionFilter struct {
+	Page   int                        `json:"page"`
+	Size   int                        `json:"size"`
+	Sort   SortColumn                 `json:"sort"`
+	Filter StatusTransitionFilterCols `json:"filter"`
+}
@@ -0,0 +1,13 @@
+package form
+
+// TranslationParams нэг лавлахын орчуулгыг бөөнөөр засах
+type TranslationParams struct {
+	Items []TranslationItemParams `json:"items" binding:"required,min=1,dive"`
+}
+
+// TranslationItemParams нэг бичлэгийн нэг хэлний нэр
+type TranslationItemParams struct {
+	EntityID uint   `json:"entity_id" binding:"required,gt=0"` //
+	Locale   string `json:"locale" binding:"required,max=10"`  // en, ru, zh-cn
+	Name     string `json:"name" binding:"max=255"`            // Хоосон бол орчуулгыг устгана
+}
@@ -48,6 +48,8 @@ type WarehouseItemFilter struct {
 type WarehousePriceHistory struct {
 	WarehouseItemID int `json:"warehouse_item_id"`
 	PriceTypeID     int `json:"price_type_id"`
+	ItemID          int `json:"item_id"`     // Бүх агуулахын түүх
+	CustomerID      int `json:"customer_id"` // Харилцагчийн үнийн түүх
 }
 
 // WarehoseItemFilterCols ...
@@ -29,11 +29,35 @@ import (
 // @name Authorization
 func main() {
 	environment := flag.String("e", "development", "")
+	bundleExport := flag.String("bundle-export", "", "")
+	bundleImport := flag.String("bundle-import", "", "")
+	dryRun := flag.Bool("dry-run", false, "")
+	seedRefCodes := flag.Bool("seed-ref-codes", false, "")
 	flag.Usage = func() {
 		fmt.Println("Usage: server -e {mode}")
+		fmt.Println("       server -e {mode} -bundle-export {file.json|file.yaml}")
+		fmt.Println("       server -e {mode} -bundle-import {file.json|file.yaml} [-dry-run]")
+		fmt.Println("       server -e {mode} -seed-ref-codes [-dry-run]")
 		os.Exit(1)
 	}
 	flag.Parse()
 	config.Init(*environment)
-	server.Start()
+
+	switch {
+	case *bundleExport != "":
+		exitOnError(refBundleExport(*bundleExport))
+	case *bundleImport != "":
+		exitOnError(refBundleImport(*bundleImport, *dryRun))
+	case *seedRefCodes:
+		exitOnError(refCodesSeed(*dryRun))
+	default:
+		server.Start()
+	}
+}
+
+func exitOnError(err error) {
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 }
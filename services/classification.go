@@ -0,0 +1,279 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+	viper "github.com/spf13/viper"
+	databases "gitlab.com/fibocloud/medtech/gin/databases"
+	gorm "gorm.io/gorm"
+)
+
+// CustomerScore харилцагчийн ангилал тогтоох үзүүлэлтүүд
+type CustomerScore struct {
+	CustomerID uint              `json:"customer_id"`
+	Purchase   databases.Decimal `json:"purchase"`     // Хугацаанд худалдан авсан дүн, буцаалтыг хассан
+	OrderCount int               `json:"order_count"`  // Хугацаанд өгсөн захиалга
+	AvgPayDays float64           `json:"avg_pay_days"` // Зарлагаас төлбөр хүртэлх дундаж хоног, дүнгээр жигнэсэн
+}
+
+// ClassificationChange ангилал өөрчлөгдөх харилцагч
+type ClassificationChange struct {
+	CustomerScore
+	Code                string `json:"code"`
+	Name                string `json:"name"`
+	OldClassificationID uint   `json:"old_classification_id"`
+	NewClassificationID uint   `json:"new_classification_id"`
+	NewClassification   string `json:"new_classification"`
+}
+
+// DefaultClassificationID шинэ толгой байгууллагад өгөх ангилал
+func DefaultClassificationID() uint {
+	return RefID(TableClassifications, DefaultClassificationCode())
+}
+
+// classificationPeriod үзүүлэлт тооцох хугацаа
+func classificationPeriod() int {
+	if months := viper.GetInt("classification.periodMonths"); months > 0 {
+		return months
+	}
+	return 12
+}
+
+// StartClassificationJob харилцагчдыг шөнө бүр дахин ангилна
+func StartClassificationJob(db *gorm.DB) {
+	ScheduleDaily("classification", viper.GetInt("classification.runHour"), func(ctx context.Context) error {
+		_, err := ReclassifyCustomers(db, false, databases.MedSystemUser{})
+		return err
+	})
+}
+
+// ReclassifyCustomers идэвхитэй харилцагч бүрийн үзүүлэлтийг тооцож, дүрэм таарсан
+// хамгийн дээд ангилалд шилжүүлнэ. preview үед идэвхжээгүй дүрмийг ч оруулж
+// хэн шилжихийг л буцаана, юу ч хадгалахгүй.
+func ReclassifyCustomers(db *gorm.DB, preview bool, user databases.MedSystemUser) ([]ClassificationChange, error) {
+	changes := []ClassificationChange{}
+
+	classDB := db.Where("is_active = ?", true)
+	if preview {
+		classDB = classDB.Where("is_auto_assign = ? OR min_purchase > 0 OR min_order_count > 0 OR max_avg_pay_days > 0", true)
+	} else {
+		classDB = classDB.Where("is_auto_assign = ?", true)
+	}
+
+	var classifications []databases.MedCustomerClassification
+	result := classDB.Order("rank desc").Order("id").Find(&classifications)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if len(classifications) == 0 {
+		return changes, nil
+	}
+
+	var customers []databases.MedCustomer
+	result = db.Where("is_active = ?", true).Order("id").Find(&customers)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	scores, err := CustomerScores(db, customers, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, customer := range customers {
+		score := scores[customer.Base.ID]
+		classification := matchClassification(classifications, score)
+		if classification == nil || classification.Base.ID == customer.ClassificationID {
+			continue
+		}
+
+		changes = append(changes, ClassificationChange{
+			CustomerScore:       score,
+			Code:                customer.Code,
+			Name:                customer.Name,
+			OldClassificationID: customer.ClassificationID,
+			NewClassificationID: classification.Base.ID,
+			NewClassification:   classification.Name,
+		})
+	}
+
+	if preview {
+		return changes, nil
+	}
+
+	for _, change := range changes {
+		err = db.Transaction(func(tx *gorm.DB) error {
+			return applyClassification(tx, change, user)
+		})
+		if err != nil {
+			return changes, err
+		}
+	}
+
+	return changes, nil
+}
+
+// matchClassification босгыг хангасан хамгийн дээд ангилал
+func matchClassification(classifications []databases.MedCustomerClassification, score CustomerScore) *databases.MedCustomerClassification {
+	for i, classification := range classifications {
+		if score.Purchase.LessThan(classification.MinPurchase.Decimal) {
+			continue
+		}
+		if score.OrderCount < classification.MinOrderCount {
+			continue
+		}
+		if classification.MaxAvgPayDays > 0 && score.AvgPayDays > classification.MaxAvgPayDays {
+			continue
+		}
+		return &classifications[i]
+	}
+	return nil
+}
+
+func applyClassification(tx *gorm.DB, change ClassificationChange, user databases.MedSystemUser) error {
+	now := time.Now()
+	result := tx.Model(&databases.MedCustomer{}).
+		Where("id = ?", change.CustomerID).
+		Updates(map[string]interface{}{
+			"classification_id": change.NewClassificationID,
+			"modified_date":     now,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	classificationLog := databases.MedCustomerClassificationLog{
+		CustomerID:          change.CustomerID,
+		OldClassificationID: change.OldClassificationID,
+		NewClassificationID: change.NewClassificationID,
+		Purchase:            change.Purchase,
+		OrderCount:          change.OrderCount,
+		AvgPayDays:          change.AvgPayDays,
+		CreatedUserID:       user.Base.ID,
+		Base: databases.Base{
+			CreatedDate:  now,
+			ModifiedDate: now,
+		},
+	}
+	return tx.Create(&classificationLog).Error
+}
+
+// CustomerScores сүүлийн classification.periodMonths сарын үзүүлэлтүүд
+func CustomerScores(db *gorm.DB, customers []databases.MedCustomer, asOf time.Time) (map[uint]CustomerScore, error) {
+	scores := map[uint]CustomerScore{}
+
+	var customerIDs []uint
+	for _, customer := range customers {
+		customerIDs = append(customerIDs, customer.Base.ID)
+		scores[customer.Base.ID] = CustomerScore{CustomerID: customer.Base.ID}
+	}
+	if len(customerIDs) == 0 {
+		return scores, nil
+	}
+
+	since := asOf.AddDate(0, -classificationPeriod(), 0)
+
+	var orders []struct {
+		CustomerID uint
+		Count      int
+	}
+	result := db.Table("med_order_books").
+		Select("customer_id, COUNT(*) AS count").
+		Where("customer_id IN ?", customerIDs).
+		Where("created_date >= ?", since).
+		Where("is_removed = ?", false).
+		Group("customer_id").
+		Scan(&orders)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	for _, order := range orders {
+		score := scores[order.CustomerID]
+		score.OrderCount = order.Count
+		scores[order.CustomerID] = score
+	}
+
+	entries, err := CustomerLedger(db, customerIDs, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	byCustomer := map[uint][]LedgerEntry{}
+	for _, entry := range entries {
+		byCustomer[entry.CustomerID] = append(byCustomer[entry.CustomerID], entry)
+	}
+
+	for customerID, ledger := range byCustomer {
+		score := scores[customerID]
+		for _, entry := range ledger {
+			if entry.Date.Before(since) {
+				continue
+			}
+			switch entry.Type {
+			case LedgerOutcome:
+				score.Purchase = databases.NewDecimal(score.Purchase.Add(entry.Debit.Decimal))
+			case LedgerReturn:
+				score.Purchase = databases.NewDecimal(score.Purchase.Sub(entry.Credit.Decimal))
+			}
+		}
+		score.AvgPayDays = averagePayDays(ledger, since, asOf)
+		scores[customerID] = score
+	}
+
+	return scores, nil
+}
+
+// averagePayDays төлбөр, буцаалтыг хамгийн хуучин зарлагаас эхлэн хааж since-ээс хойших
+// зарлага бүр хэдэн хоногт төлөгдсөнийг дүнгээр жигнэнэ. Төлөгдөөгүй хэсэг asOf хүртэл тоологдоно.
+func averagePayDays(ledger []LedgerEntry, since, asOf time.Time) float64 {
+	entries := append([]LedgerEntry{}, ledger...)
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Date.Before(entries[j].Date)
+	})
+
+	type openDebit struct {
+		date   time.Time
+		amount decimal.Decimal
+	}
+
+	var open []openDebit
+	weighted, total := decimal.Zero, decimal.Zero
+	settle := func(debit openDebit, amount decimal.Decimal, paid time.Time) {
+		if debit.date.Before(since) {
+			return
+		}
+		days := decimal.NewFromFloat(paid.Sub(debit.date).Hours() / 24)
+		weighted = weighted.Add(amount.Mul(days))
+		total = total.Add(amount)
+	}
+
+	for _, entry := range entries {
+		if entry.Debit.IsPositive() {
+			open = append(open, openDebit{date: entry.Date, amount: entry.Debit.Decimal})
+		}
+
+		credit := entry.Credit.Decimal
+		for credit.IsPositive() && len(open) > 0 {
+			amount := decimal.Min(open[0].amount, credit)
+			settle(open[0], amount, entry.Date)
+			open[0].amount = open[0].amount.Sub(amount)
+			credit = credit.Sub(amount)
+			if !open[0].amount.IsPositive() {
+				open = open[1:]
+			}
+		}
+	}
+
+	for _, debit := range open {
+		settle(debit, debit.amount, asOf)
+	}
+
+	if total.IsZero() {
+		return 0
+	}
+	days, _ := weighted.Div(total).Float64()
+	return days
+}
@@ -0,0 +1,262 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/spf13/viper"
+	databases "gitlab.com/fibocloud/medtech/gin/databases"
+	gorm "gorm.io/gorm"
+)
+
+// Зээлийн хязгаарын дүрмүүд
+const (
+	CreditOneTime     = "one_time"    // Нэг удаагийн худалдан авалт
+	CreditReceivables = "receivables" // Нийт авлага
+	CreditPurchase    = "purchase"    // Сарын нийт худалдан авалт
+)
+
+// Хязгаар хэтэрсэн үед
+const (
+	CreditModeReject = "reject" // Захиалгыг хүлээж авахгүй
+	CreditModeFlag   = "flag"   // Захиалгыг авч, тэмдэглэнэ
+)
+
+// Зээлийн хязгаарын алдаанууд
+var (
+	ErrCreditExceeded       = errors.New("Харилцагчийн зээлийн хязгаар хэтэрсэн байна")
+	ErrCreditOverrideRole   = errors.New("Зээлийн хязгаар хэтрүүлэх эрхгүй байна")
+	ErrCreditOverrideReason = errors.New("Зээлийн хязгаар хэтрүүлэх шалтгаан оруулна уу")
+)
+
+// CreditViolation хэтэрсэн хязгаар
+type CreditViolation struct {
+	Rule   string            `json:"rule"`   // one_time, receivables, purchase
+	Limit  databases.Decimal `json:"limit"`  // Хязгаар
+	Amount databases.Decimal `json:"amount"` // Захиалгын дараах дүн
+}
+
+// CreditCheck харилцагчийн зээлийн шалгалтын үр дүн
+type CreditCheck struct {
+	CustomerID      uint              `json:"customer_id"`
+	OrderAmount     databases.Decimal `json:"order_amount"`     // Шинэ захиалгын дүн
+	OpenReceivables databases.Decimal `json:"open_receivables"` // Төлөгдөөгүй авлага
+	MonthPurchase   databases.Decimal `json:"month_purchase"`   // Энэ сарын худалдан авалт
+	Mode            string            `json:"mode"`             // reject, flag
+	Exceeded        bool              `json:"exceeded"`         //
+	Violations      []CreditViolation `json:"violations"`       //
+}
+
+// CreditOverride менежерийн зөвшөөрөл
+type CreditOverride struct {
+	Reason string `json:"reason"`
+}
+
+// CreditMode хязгаар хэтэрсэн үеийн горим
+func CreditMode() string {
+	if viper.GetString("credit.mode") == CreditModeFlag {
+		return CreditModeFlag
+	}
+	return CreditModeReject
+}
+
+// OpenReceivables харилцагчийн зарлагын нийт дүнгээс төлбөр, буцаалтыг хасна
+func OpenReceivables(db *gorm.DB, customerID uint) (databases.Decimal, error) {
+	return ledgerBalance(db, []uint{customerID})
+}
+
+// ledgerBalance олон харилцагчийн авлагын дэвтрийн нийт үлдэгдэл
+func ledgerBalance(db *gorm.DB, customerIDs []uint) (databases.Decimal, error) {
+	var ledger struct {
+		Balance databases.Decimal
+	}
+	result := db.Raw(
+		"SELECT COALESCE(SUM(debit - credit), 0) AS balance FROM ("+customerLedgerSQL+") ledger",
+		customerLedgerArgs(customerIDs)...,
+	).Scan(&ledger)
+	if result.Error != nil {
+		return databases.Decimal{}, result.Error
+	}
+
+	return RoundBase(ledger.Balance.Decimal), nil
+}
+
+// CheckCredit үндсэн валютаарх amount дүнтэй захиалга харилцагчийн хязгаарт багтах эсэх.
+// 0 хязгаар шалгахгүй.
+func CheckCredit(db *gorm.DB, customerID uint, amount decimal.Decimal) (*CreditCheck, error) {
+	var customer databases.MedCustomer
+	result := db.First(&customer, customerID)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	receivables, err := OpenReceivables(db, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	var monthPurchase struct {
+		Total databases.Decimal
+	}
+	result = db.Table("med_outcomes").
+		Select("COALESCE(SUM("+outcomeBaseTotal+"), 0) AS total").
+		Where("customer_id = ?", customerID).
+		Where("created_date >= ?", monthStart).
+		Scan(&monthPurchase)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	check := CreditCheck{
+		CustomerID:      customerID,
+		OrderAmount:     RoundBase(amount),
+		OpenReceivables: receivables,
+		MonthPurchase:   RoundBase(monthPurchase.Total.Decimal),
+		Mode:            CreditMode(),
+		Violations:      []CreditViolation{},
+	}
+
+	check.limit(CreditOneTime, customer.OneTimePurchaseLimit, check.OrderAmount.Decimal)
+	check.limit(CreditReceivables, customer.MaximumReceivables, check.OpenReceivables.Add(check.OrderAmount.Decimal))
+	check.limit(CreditPurchase, customer.MaximumPurchase, check.MonthPurchase.Add(check.OrderAmount.Decimal))
+
+	check.Exceeded = len(check.Violations) > 0
+	return &check, nil
+}
+
+// limit amount нь 0-ээс их limit-ээс хэтэрвэл зөрчил нэмнэ
+func (check *CreditCheck) limit(rule string, limit float64, amount decimal.Decimal) {
+	if limit <= 0 {
+		return
+	}
+
+	value := RoundBase(decimal.NewFromFloat(limit))
+	if amount.GreaterThan(value.Decimal) {
+		check.Violations = append(check.Violations, CreditViolation{Rule: rule, Limit: value, Amount: databases.NewDecimal(amount)})
+	}
+}
+
+// EnforceCredit хязгаар хэтэрсэн бол reject горимд override-гүй үед ErrCreditExceeded
+// буцаана, override-ийн эрх, шалтгааныг шалгана. Шинэ захиалга, зарлагад Workflow.Start
+// EnforceDocumentCredit-ээр, хадгалсан захиалгад /customer/credit/override дуудна.
+func EnforceCredit(db *gorm.DB, customerID uint, amount decimal.Decimal, override *CreditOverride, user databases.MedSystemUser) (*CreditCheck, error) {
+	check, err := CheckCredit(db, customerID, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	if !check.Exceeded || (check.Mode == CreditModeFlag && override == nil) {
+		return check, nil
+	}
+
+	if override == nil {
+		return check, ErrCreditExceeded
+	}
+
+	if strings.TrimSpace(override.Reason) == "" {
+		return check, ErrCreditOverrideReason
+	}
+
+	allowed, err := UserHasRole(db, user, viper.GetStringSlice("credit.overrideRoles"))
+	if err != nil {
+		return check, err
+	}
+	if !allowed {
+		return check, ErrCreditOverrideRole
+	}
+	return check, nil
+}
+
+// creditDocuments зээлийн хязгаар шалгах баримтын төрлүүд
+var creditDocuments = map[string]bool{
+	DocumentOrderBook: true,
+	DocumentOutcome:   true,
+}
+
+// EnforceDocumentCredit хадгалсан захиалга, зарлагын дүнг харилцагчийн хязгаараар шалгана.
+// Баримтыг Workflow.Start-аар эхлүүлэхэд дуудагдах тул захиалга, зарлага үүсгэх бүх зам
+// дамжина. Хязгаар шалгадаггүй баримтад nil буцаана.
+func EnforceDocumentCredit(tx *gorm.DB, documentType string, recordID uint, override *CreditOverride, user databases.MedSystemUser) (*CreditCheck, error) {
+	if !creditDocuments[documentType] {
+		return nil, nil
+	}
+
+	customerID, amount, err := DocumentCreditAmount(tx, documentType, recordID)
+	if err != nil {
+		return nil, err
+	}
+	return EnforceCredit(tx, customerID, amount, override, user)
+}
+
+// DocumentCreditAmount хадгалагдсан захиалга, зарлагын харилцагч, НӨАТ орсон нийт дүнг
+// үндсэн валютаар уншина
+func DocumentCreditAmount(db *gorm.DB, documentType string, recordID uint) (uint, decimal.Decimal, error) {
+	table, ok := DocumentTables[documentType]
+	if !ok || !creditDocuments[documentType] {
+		return 0, decimal.Zero, ErrUnknownDocument
+	}
+
+	var document struct {
+		CustomerID uint
+		Total      databases.Decimal
+	}
+	result := db.Table(table).
+		Select("customer_id, "+outcomeBaseTotal+" AS total").
+		Where("id = ?", recordID).
+		Scan(&document)
+	if result.Error != nil {
+		return 0, decimal.Zero, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return 0, decimal.Zero, gorm.ErrRecordNotFound
+	}
+	return document.CustomerID, RoundBase(document.Total.Decimal).Decimal, nil
+}
+
+// creditNote хэтэрсэн хязгаарын тэмдэглэл, төлөвийн түүхэнд бичнэ
+func creditNote(check *CreditCheck, override *CreditOverride) string {
+	var rules []string
+	for _, violation := range check.Violations {
+		rules = append(rules, fmt.Sprintf("%s %s/%s", violation.Rule, violation.Amount.String(), violation.Limit.String()))
+	}
+
+	note := fmt.Sprintf("Зээлийн хязгаар хэтрүүлсэн (%s)", strings.Join(rules, ", "))
+	if override != nil {
+		note += ": " + override.Reason
+	}
+	return note
+}
+
+// RecordCreditOverride хязгаар хэтрүүлсэн шалтгааныг баримтын төлөвийн түүхэнд бичнэ
+func RecordCreditOverride(tx *gorm.DB, documentType string, recordID uint, check *CreditCheck, override CreditOverride, user databases.MedSystemUser) error {
+	table, ok := DocumentTables[documentType]
+	if !ok {
+		return ErrUnknownDocument
+	}
+
+	var statusID uint
+	result := tx.Table(table).Select("status_id").Where("id = ?", recordID).Scan(&statusID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	statusLog := databases.MedStatusLog{
+		CreatedUser:  &user,
+		RecordID:     recordID,
+		StatusID:     statusID,
+		Description:  creditNote(check, &override),
+		HdrTableName: table,
+		Base: databases.Base{
+			CreatedDate: time.Now(),
+		},
+	}
+	return tx.Create(&statusLog).Error
+}
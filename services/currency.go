@@ -0,0 +1,49 @@
+package services
+
+import (
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+	databases "gitlab.com/fibocloud/medtech/gin/databases"
+	utils "gitlab.com/fibocloud/medtech/gin/utils"
+	gorm "gorm.io/gorm"
+)
+
+// ResolveDocumentCurrency баримтын валют, ханшийг тогтооно. enteredRate хоосон бол
+// тухайн өдрийн ханш, оруулсан бол ResolveValuteValue-аар шалгана.
+// valuteID 0 бол үндсэн валют, ханш 1.
+func ResolveDocumentCurrency(db *gorm.DB, valuteID uint, date time.Time, enteredRate decimal.Decimal) (databases.DocumentCurrency, error) {
+	if valuteID == 0 {
+		return databases.DocumentCurrency{CurrencyCode: BaseCurrency(), Rate: databases.NewDecimal(decimal.NewFromInt(1))}, nil
+	}
+
+	var valute databases.RefValute
+	if err := db.First(&valute, valuteID).Error; err != nil {
+		return databases.DocumentCurrency{}, err
+	}
+
+	rate, err := ResolveValuteValue(db, valuteID, date, enteredRate)
+	if err != nil {
+		return databases.DocumentCurrency{}, err
+	}
+
+	return databases.DocumentCurrency{
+		ValuteID:     valuteID,
+		CurrencyCode: strings.ToUpper(strings.TrimSpace(valute.Name)),
+		Rate:         databases.NewDecimal(rate),
+	}, nil
+}
+
+// DocumentAmounts баримтын валютаар оруулсан дүнг бүхэлчилж үндсэн валют руу хөрвүүлнэ
+func DocumentAmounts(currency databases.DocumentCurrency, amount decimal.Decimal) (transaction, base utils.Money) {
+	transaction = utils.NewMoneyDecimal(amount, currency.CurrencyCode).Round()
+	base = transaction.Convert(currency.Rate.Decimal, BaseCurrency())
+	return transaction, base
+}
+
+// RoundBase үндсэн валютын дүнг тухайн валютын бүхэлчлэлээр бүхэлчилнэ
+func RoundBase(value decimal.Decimal) databases.Decimal {
+	places, mode := utils.CurrencyRounding(BaseCurrency())
+	return databases.NewDecimal(utils.RoundDecimal(value, places, mode))
+}
@@ -0,0 +1,610 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/spf13/viper"
+	databases "gitlab.com/fibocloud/medtech/gin/databases"
+	gorm "gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Давхардлын шалтгаанууд
+const (
+	DuplicateRegistry = "registry_number"
+	DuplicateName     = "name"
+	DuplicatePhone    = "phone"
+	DuplicateEmail    = "email"
+)
+
+// ErrMergeSame нэг харилцагчийг өөртэй нь нэгтгэх
+var ErrMergeSame = errors.New("Нэгтгэх хоёр харилцагч ижил байна")
+
+// customerLegalForms нэрийг харьцуулахдаа хасах хуулийн этгээдийн хэлбэр
+var customerLegalForms = map[string]bool{
+	"ххк": true, "хк": true, "тг": true, "тбб": true, "ххн": true, "нөхөрлөл": true,
+	"компани": true, "llc": true, "ltd": true, "co": true, "inc": true, "corp": true,
+}
+
+var phoneDigits = regexp.MustCompile(`\D`)
+
+// DuplicateCustomer давхардсан байж болох харилцагч
+type DuplicateCustomer struct {
+	ID                    uint   `json:"id"`
+	Code                  string `json:"code"`
+	Name                  string `json:"name"`
+	CompanyRegistryNumber string `json:"company_registry_number"`
+	ParentID              uint   `json:"parent_id"`
+}
+
+// DuplicateCandidate давхардсан байж болох хос
+type DuplicateCandidate struct {
+	Customer  DuplicateCustomer `json:"customer"`
+	Duplicate DuplicateCustomer `json:"duplicate"`
+	Score     float64           `json:"score"`   // Нэрийн төстэй байдал 0-1
+	Reasons   []string          `json:"reasons"` // registry_number, name, phone, email
+}
+
+// customerJoinTables many2many хүснэгтүүд, харилцагчийн багана болон нөгөө багана
+var customerJoinTables = []struct{ Table, Other string }{
+	{"med_content_map", "med_content_id"},
+	{"med_customer_type_dtl", "med_customer_type_id"},
+}
+
+// customerRefTables customer_id-аар холбогдсон хүснэгтүүд
+var customerRefTables = []string{
+	"med_customer_contacts",
+	"med_customer_addresses",
+	"med_customer_documents",
+	"med_customer_tax_histories",
+	"med_customer_payments",
+	"med_price_customers",
+	"med_order_books",
+	"med_outcomes",
+	"med_outcome_installments",
+	"med_customer_classification_logs",
+	"med_price_change_dtls",
+}
+
+// NormalizeCustomerName жижиг үсэг, тэмдэгт болон ХХК, ТГ гэх мэт хэлбэрийг хасна
+func NormalizeCustomerName(name string) string {
+	name = strings.ToLower(name)
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return ' '
+	}, name)
+
+	var words []string
+	for _, word := range strings.Fields(name) {
+		if !customerLegalForms[word] {
+			words = append(words, word)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// NameSimilarity Левенштейн зайд суурилсан төстэй байдал, 1 бол ижил
+func NameSimilarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 && len(rb) == 0 {
+		return 1
+	}
+
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	return 1 - float64(previous[len(rb)])/float64(longest)
+}
+
+func minInt(values ...int) int {
+	min := values[0]
+	for _, v := range values[1:] {
+		if v < min {
+			min = v
+		}
+	}
+	return min
+}
+
+func normalizePhone(phone string) string {
+	digits := phoneDigits.ReplaceAllString(phone, "")
+	if len(digits) > 8 {
+		digits = digits[len(digits)-8:]
+	}
+	return digits
+}
+
+// DuplicateThreshold нэрийн төстэй байдлын доод хязгаар
+func DuplicateThreshold() float64 {
+	if threshold := viper.GetFloat64("customer.duplicateThreshold"); threshold > 0 {
+		return threshold
+	}
+	return 0.85
+}
+
+// duplicateNamePrefix нэрээр бүлэглэх түлхүүрийн үсгийн тоо. Нэрийг зөвхөн ижил
+// угтвартай харилцагчдын дунд харьцуулна.
+const duplicateNamePrefix = 3
+
+// duplicateNameKey нэрийн бүлгийн түлхүүр, шалтгаан биш
+const duplicateNameKey = "name_prefix"
+
+// FindDuplicateCustomers давхардсан байж болох харилцагчдыг олно. customerID 0 бол бүх харилцагч.
+// Харилцагчдыг РД, утас, и-мэйл, нэрийн угтвараар бүлэглээд зөвхөн бүлэг доторх хосыг
+// харьцуулна. customerID өгсөн бол түүнтэй түлхүүр давхцах харилцагчдыг л уншина.
+func FindDuplicateCustomers(db *gorm.DB, customerID uint, threshold float64) ([]DuplicateCandidate, error) {
+	if threshold <= 0 {
+		threshold = DuplicateThreshold()
+	}
+
+	customers, contacts, err := duplicateSources(db, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[uint]map[string]string)
+	addKey := func(id uint, reason, value string) {
+		if value == "" {
+			return
+		}
+		if keys[id] == nil {
+			keys[id] = make(map[string]string)
+		}
+		keys[id][reason+":"+value] = reason
+	}
+	for _, contact := range contacts {
+		addKey(contact.CustomerID, DuplicatePhone, normalizePhone(contact.PhoneNumber1))
+		addKey(contact.CustomerID, DuplicatePhone, normalizePhone(contact.PhoneNumber2))
+		addKey(contact.CustomerID, DuplicateEmail, strings.ToLower(strings.TrimSpace(contact.Email1)))
+		addKey(contact.CustomerID, DuplicateEmail, strings.ToLower(strings.TrimSpace(contact.Email2)))
+	}
+
+	byID := make(map[uint]DuplicateCustomer, len(customers))
+	names := make(map[uint]string, len(customers))
+	for _, customer := range customers {
+		byID[customer.ID] = customer
+		names[customer.ID] = NormalizeCustomerName(customer.Name)
+		addKey(customer.ID, DuplicateRegistry, NormalizeRegistryNumber(customer.CompanyRegistryNumber))
+		addKey(customer.ID, duplicateNameKey, namePrefix(names[customer.ID]))
+	}
+
+	blocks := make(map[string][]uint)
+	for _, customer := range customers {
+		for key := range keys[customer.ID] {
+			blocks[key] = append(blocks[key], customer.ID)
+		}
+	}
+
+	compared := make(map[[2]uint]bool)
+	var candidates []DuplicateCandidate
+	for _, ids := range blocks {
+		for i := range ids {
+			for j := i + 1; j < len(ids); j++ {
+				a, b := byID[ids[i]], byID[ids[j]]
+				if customerID != 0 && a.ID != customerID && b.ID != customerID {
+					continue
+				}
+				if compared[[2]uint{a.ID, b.ID}] {
+					continue
+				}
+				compared[[2]uint{a.ID, b.ID}] = true
+
+				// толгой байгууллага, салбар нь ижил нэртэй байж болно
+				if a.ParentID == b.ID || b.ParentID == a.ID {
+					continue
+				}
+
+				var reasons []string
+				for key, reason := range keys[a.ID] {
+					if reason == duplicateNameKey || containsString(reasons, reason) {
+						continue
+					}
+					if _, ok := keys[b.ID][key]; ok {
+						reasons = append(reasons, reason)
+					}
+				}
+
+				score := NameSimilarity(names[a.ID], names[b.ID])
+				if score >= threshold {
+					reasons = append(reasons, DuplicateName)
+				}
+
+				if len(reasons) == 0 {
+					continue
+				}
+
+				sort.Strings(reasons)
+				if b.ID == customerID {
+					a, b = b, a
+				}
+				candidates = append(candidates, DuplicateCandidate{
+					Customer:  a,
+					Duplicate: b,
+					Score:     score,
+					Reasons:   reasons,
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if len(candidates[i].Reasons) != len(candidates[j].Reasons) {
+			return len(candidates[i].Reasons) > len(candidates[j].Reasons)
+		}
+		if candidates[i].Score != candidates[j].Score {
+			return candidates[i].Score > candidates[j].Score
+		}
+		if candidates[i].Customer.ID != candidates[j].Customer.ID {
+			return candidates[i].Customer.ID < candidates[j].Customer.ID
+		}
+		return candidates[i].Duplicate.ID < candidates[j].Duplicate.ID
+	})
+	return candidates, nil
+}
+
+func namePrefix(name string) string {
+	runes := []rune(name)
+	if len(runes) > duplicateNamePrefix {
+		runes = runes[:duplicateNamePrefix]
+	}
+	return string(runes)
+}
+
+// Давхардал хайхад унших баганууд
+const (
+	duplicateCustomerColumns = "id, code, name, company_registry_number, parent_id"
+	duplicateContactColumns  = "customer_id, phone_number1, phone_number2, email1, email2"
+)
+
+// duplicateSources харьцуулах харилцагчид, тэдгээрийн холбоо барих мэдээлэл. customerID
+// өгсөн бол РД, утас, и-мэйл, нэрийн угтвар нь тухайн харилцагчийнхтай таарах мөрүүдийг
+// өгөгдлийн сангаас шүүж уншина.
+func duplicateSources(db *gorm.DB, customerID uint) ([]DuplicateCustomer, []databases.MedCustomerContacts, error) {
+	var customers []DuplicateCustomer
+	var contacts []databases.MedCustomerContacts
+	customerQuery := db.Model(&databases.MedCustomer{}).
+		Select(duplicateCustomerColumns).
+		Order("id")
+	contactQuery := db.Select(duplicateContactColumns)
+
+	if customerID != 0 {
+		var target DuplicateCustomer
+		result := db.Model(&databases.MedCustomer{}).
+			Select(duplicateCustomerColumns).
+			Where("id = ?", customerID).
+			Limit(1).
+			Scan(&target)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return nil, nil, result.Error
+		}
+
+		var targetContacts []databases.MedCustomerContacts
+		result = db.Select(duplicateContactColumns).Where("customer_id = ?", customerID).Find(&targetContacts)
+		if result.Error != nil {
+			return nil, nil, result.Error
+		}
+
+		phones := []string{}
+		emails := []string{}
+		for _, contact := range targetContacts {
+			for _, phone := range []string{contact.PhoneNumber1, contact.PhoneNumber2} {
+				if phone = normalizePhone(phone); phone != "" {
+					phones = append(phones, phone)
+				}
+			}
+			for _, email := range []string{contact.Email1, contact.Email2} {
+				if email = strings.ToLower(strings.TrimSpace(email)); email != "" {
+					emails = append(emails, email)
+				}
+			}
+		}
+
+		matched := db.Model(&databases.MedCustomerContacts{}).
+			Select("customer_id").
+			Where("RIGHT(REGEXP_REPLACE(phone_number1, '\\D', '', 'g'), 8) IN ?", phones).
+			Or("RIGHT(REGEXP_REPLACE(phone_number2, '\\D', '', 'g'), 8) IN ?", phones).
+			Or("LOWER(TRIM(email1)) IN ?", emails).
+			Or("LOWER(TRIM(email2)) IN ?", emails)
+
+		filter := db.Where("id = ?", customerID).Or("id IN (?)", matched)
+		if registryNumber := NormalizeRegistryNumber(target.CompanyRegistryNumber); registryNumber != "" {
+			filter = filter.Or("UPPER(TRIM(company_registry_number)) = ?", registryNumber)
+		}
+		if prefix := namePrefix(NormalizeCustomerName(target.Name)); prefix != "" {
+			filter = filter.Or("LOWER(name) LIKE ?", prefix+"%")
+		}
+		customerQuery = customerQuery.Where(filter)
+	}
+
+	if err := customerQuery.Scan(&customers).Error; err != nil {
+		return nil, nil, err
+	}
+	if customerID != 0 {
+		ids := make([]uint, 0, len(customers))
+		for _, customer := range customers {
+			ids = append(ids, customer.ID)
+		}
+		contactQuery = contactQuery.Where("customer_id IN ?", ids)
+	}
+	if err := contactQuery.Find(&contacts).Error; err != nil {
+		return nil, nil, err
+	}
+	return customers, contacts, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// MergeCustomers mergedID харилцагчийн бүх холбоосыг survivorID руу шилжүүлээд устгана.
+// Дуудагчийн transaction дотор ажиллана.
+func MergeCustomers(tx *gorm.DB, survivorID, mergedID uint, description string, user databases.MedSystemUser) (*databases.MedCustomerMerge, error) {
+	if survivorID == mergedID {
+		return nil, ErrMergeSame
+	}
+
+	var customers []databases.MedCustomer
+	result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id IN ?", []uint{survivorID, mergedID}).
+		Find(&customers)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if len(customers) != 2 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	var survivor, merged databases.MedCustomer
+	for _, customer := range customers {
+		if customer.Base.ID == survivorID {
+			survivor = customer
+		} else {
+			merged = customer
+		}
+	}
+
+	// survivor нь merged-ийн салбарын салбар бол бүтэц давталттай болно
+	ancestors, err := CustomerAncestors(tx, survivorID)
+	if err != nil {
+		return nil, err
+	}
+	for _, ancestor := range ancestors {
+		if ancestor.Base.ID == mergedID && ancestor.Depth > 1 {
+			return nil, ErrCustomerCycle
+		}
+	}
+
+	snapshot, err := json.Marshal(merged)
+	if err != nil {
+		return nil, err
+	}
+
+	moved := make(map[string]int64)
+
+	for _, join := range customerJoinTables {
+		if !tx.Migrator().HasTable(join.Table) {
+			continue
+		}
+		result = tx.Exec(
+			"UPDATE "+join.Table+" SET med_customer_id = ? WHERE med_customer_id = ? AND "+join.Other+
+				" NOT IN (SELECT "+join.Other+" FROM "+join.Table+" WHERE med_customer_id = ?)",
+			survivorID, mergedID, survivorID,
+		)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		moved[join.Table] = result.RowsAffected
+
+		result = tx.Exec("DELETE FROM "+join.Table+" WHERE med_customer_id = ?", mergedID)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+	}
+
+	// хоёуланд нь хүчинтэй баримт байвал үлдэх харилцагчийнх хүчинтэй хэвээр
+	result = tx.Model(&databases.MedCustomerDocument{}).
+		Where("customer_id = ?", mergedID).
+		Where("is_current = ?", true).
+		Where("content_type_id IN (?)", tx.Model(&databases.MedCustomerDocument{}).
+			Select("content_type_id").
+			Where("customer_id = ?", survivorID).
+			Where("is_current = ?", true)).
+		Updates(map[string]interface{}{
+			"is_current":    false,
+			"modified_date": time.Now(),
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	if err := clearMergedDefaults(tx, survivorID, mergedID); err != nil {
+		return nil, err
+	}
+
+	deactivated, err := deactivateOverlappingPrices(tx, survivorID, mergedID)
+	if err != nil {
+		return nil, err
+	}
+	moved["med_price_customers_deactivated"] = deactivated
+
+	for _, table := range customerRefTables {
+		if !tx.Migrator().HasTable(table) {
+			continue
+		}
+		result = tx.Table(table).Where("customer_id = ?", mergedID).Update("customer_id", survivorID)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		moved[table] = result.RowsAffected
+	}
+
+	result = tx.Table("med_status_logs").
+		Where("hdr_table_name = ?", DocumentTables[DocumentCustomer]).
+		Where("record_id = ?", mergedID).
+		Update("record_id", survivorID)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	moved["med_status_logs"] = result.RowsAffected
+
+	// салбарууд үлдэх харилцагч руу шилжинэ
+	parentID := survivor.ParentID
+	if parentID == mergedID {
+		parentID = merged.ParentID
+	}
+	result = tx.Model(&databases.MedCustomer{}).
+		Where("parent_id = ?", mergedID).
+		Where("id <> ?", survivorID).
+		Updates(map[string]interface{}{
+			"parent_id":    survivorID,
+			"company_name": survivor.Name,
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	moved["med_customers"] = result.RowsAffected
+
+	registryNumber := survivor.CompanyRegistryNumber
+	if registryNumber == "" {
+		registryNumber = merged.CompanyRegistryNumber
+	}
+
+	result = tx.Delete(&merged)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	result = tx.Model(&survivor).Updates(map[string]interface{}{
+		"parent_id":               parentID,
+		"company_registry_number": registryNumber,
+		"modified_date":           time.Now(),
+		"modified_user_id":        user.Base.ID,
+	})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	movedRows, err := json.Marshal(moved)
+	if err != nil {
+		return nil, err
+	}
+
+	audit := databases.MedCustomerMerge{
+		SurvivorID:           survivorID,
+		MergedID:             mergedID,
+		MergedCode:           merged.Code,
+		MergedName:           merged.Name,
+		MergedRegistryNumber: merged.CompanyRegistryNumber,
+		Snapshot:             string(snapshot),
+		MovedRows:            string(movedRows),
+		Description:          description,
+		CreatedUser:          &user,
+		Base: databases.Base{
+			CreatedDate:  time.Now(),
+			ModifiedDate: time.Now(),
+		},
+	}
+
+	result = tx.Omit("Survivor").Create(&audit)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &audit, nil
+}
+
+// clearMergedDefaults үлдэх харилцагч үндсэн холбоо барих хүн, тухайн төрлийн үндсэн хаягтай
+// бол шилжих мөрүүдийн үндсэн тэмдгийг авна
+func clearMergedDefaults(tx *gorm.DB, survivorID, mergedID uint) error {
+	now := time.Now()
+	result := tx.Model(&databases.MedCustomerContacts{}).
+		Where("customer_id = ?", mergedID).
+		Where("is_primary = ?", true).
+		Where("EXISTS (?)", tx.Model(&databases.MedCustomerContacts{}).
+			Select("1").
+			Where("customer_id = ?", survivorID).
+			Where("is_primary = ?", true)).
+		Updates(map[string]interface{}{
+			"is_primary":    false,
+			"modified_date": now,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	result = tx.Model(&databases.MedCustomerAddress{}).
+		Where("customer_id = ?", mergedID).
+		Where("is_default = ?", true).
+		Where("address_type_id IN (?)", tx.Model(&databases.MedCustomerAddress{}).
+			Select("address_type_id").
+			Where("customer_id = ?", survivorID).
+			Where("is_default = ?", true)).
+		Updates(map[string]interface{}{
+			"is_default":    false,
+			"modified_date": now,
+		})
+	return result.Error
+}
+
+// deactivateOverlappingPrices үлдэх харилцагчийн тусгай үнэтэй хугацаа давхцах шилжих
+// үнийг идэвхгүй болгоно, үлдэх харилцагчийн үнэ хүчинтэй хэвээр
+func deactivateOverlappingPrices(tx *gorm.DB, survivorID, mergedID uint) (int64, error) {
+	var prices []databases.MedPriceCustomer
+	result := tx.Where("customer_id = ?", mergedID).Where("is_active = ?", true).Find(&prices)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	var overlapping []uint
+	for _, price := range prices {
+		err := CheckCustomerPriceOverlap(tx, survivorID, price.ItemID, price.StartDate, price.EndDate, 0)
+		if errors.Is(err, ErrPriceOverlap) {
+			overlapping = append(overlapping, price.Base.ID)
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+	if len(overlapping) == 0 {
+		return 0, nil
+	}
+
+	result = tx.Model(&databases.MedPriceCustomer{}).
+		Where("id IN ?", overlapping).
+		Updates(map[string]interface{}{
+			"is_active":     false,
+			"modified_date": time.Now(),
+		})
+	return result.RowsAffected, result.Error
+}
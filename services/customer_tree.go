@@ -0,0 +1,252 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/spf13/viper"
+	databases "gitlab.com/fibocloud/medtech/gin/databases"
+	gorm "gorm.io/gorm"
+)
+
+// Харилцагчийн бүтцийн алдаанууд
+var (
+	ErrCustomerCycle = errors.New("Харилцагчийг өөрийн салбар дор шилжүүлэх боломжгүй")
+	ErrCustomerDepth = errors.New("Харилцагчийн бүтцийн түвшин хэтэрсэн байна")
+)
+
+// CustomerNode бүтэц доторх харилцагч
+type CustomerNode struct {
+	databases.MedCustomer
+	Depth int `json:"depth"` // Эх харилцагчаас хэдэн түвшин зайтай
+}
+
+// CustomerGroupSummary толгой байгууллага болон бүх салбарын нэгтгэл
+type CustomerGroupSummary struct {
+	CustomerID           uint              `json:"customer_id"`
+	BranchCount          int               `json:"branch_count"`
+	MaximumPurchase      databases.Decimal `json:"maximum_purchase"`        // Салбаруудын худалдан авалтын дээд хязгаарын нийлбэр
+	MaximumReceivables   databases.Decimal `json:"maximum_receivables"`     // Салбаруудын авлагын дээд хязгаарын нийлбэр
+	OneTimePurchaseLimit databases.Decimal `json:"one_time_purchase_limit"` // Салбаруудын нэг удаагийн хязгаарын нийлбэр
+	OrderCount           int64             `json:"order_count"`             // Захиалгын тоо
+	OrderTotal           databases.Decimal `json:"order_total"`             // Захиалгын нийт дүн, үндсэн валютаар
+	Receivables          databases.Decimal `json:"receivables"`             // Зарлага, төлбөр, буцаалтын дэвтрийн үлдэгдэл
+}
+
+// MaxCustomerDepth толгой байгууллагаас доош зөвшөөрөгдөх түвшин
+func MaxCustomerDepth() int {
+	if depth := viper.GetInt("customer.maxDepth"); depth > 0 {
+		return depth
+	}
+	return 3
+}
+
+// CustomerDescendants харилцагчийн бүх салбарыг түвшингээр нь буцаана
+func CustomerDescendants(db *gorm.DB, customerID uint) ([]CustomerNode, error) {
+	var rows []struct {
+		ID    uint
+		Depth int
+	}
+
+	// depth хязгаар нь хуучин өгөгдөлд давталт байсан ч query-г зогсооно
+	result := db.Raw(`
+		WITH RECURSIVE tree AS (
+			SELECT id, 1 AS depth FROM med_customers WHERE parent_id = ? AND id <> ?
+			UNION ALL
+			SELECT c.id, t.depth + 1 FROM med_customers c JOIN tree t ON c.parent_id = t.id
+			WHERE t.depth < ?
+		)
+		SELECT id, MIN(depth) AS depth FROM tree GROUP BY id ORDER BY MIN(depth), id`,
+		customerID, customerID, MaxCustomerDepth()+1,
+	).Scan(&rows)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	if len(rows) == 0 {
+		return []CustomerNode{}, nil
+	}
+
+	var ids []uint
+	depths := make(map[uint]int)
+	for _, row := range rows {
+		ids = append(ids, row.ID)
+		depths[row.ID] = row.Depth
+	}
+
+	var customers []databases.MedCustomer
+	result = db.Where("id IN ?", ids).Order("id").Find(&customers)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	nodes := make([]CustomerNode, 0, len(customers))
+	for _, customer := range customers {
+		nodes = append(nodes, CustomerNode{MedCustomer: customer, Depth: depths[customer.Base.ID]})
+	}
+	return nodes, nil
+}
+
+// CustomerAncestors харилцагчаас дээш толгой байгууллага хүртэлх жагсаалт, ойрхоноос нь
+func CustomerAncestors(db *gorm.DB, customerID uint) ([]CustomerNode, error) {
+	var customer databases.MedCustomer
+	result := db.First(&customer, customerID)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	nodes := []CustomerNode{}
+	visited := map[uint]bool{customer.Base.ID: true}
+	parentID := customer.ParentID
+	for depth := 1; parentID != 0; depth++ {
+		if visited[parentID] {
+			return nodes, ErrCustomerCycle
+		}
+		visited[parentID] = true
+
+		var parent databases.MedCustomer
+		result = db.First(&parent, parentID)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+
+		nodes = append(nodes, CustomerNode{MedCustomer: parent, Depth: depth})
+		parentID = parent.ParentID
+	}
+	return nodes, nil
+}
+
+// ValidateCustomerParent customerID-г parentID дор шилжүүлж болох эсэхийг шалгана
+func ValidateCustomerParent(db *gorm.DB, customerID, parentID uint) error {
+	if parentID == 0 {
+		return nil
+	}
+	if parentID == customerID {
+		return ErrCustomerCycle
+	}
+
+	ancestors, err := CustomerAncestors(db, parentID)
+	if err != nil {
+		return err
+	}
+	for _, ancestor := range ancestors {
+		if ancestor.Base.ID == customerID {
+			return ErrCustomerCycle
+		}
+	}
+
+	descendants, err := CustomerDescendants(db, customerID)
+	if err != nil {
+		return err
+	}
+	height := 0
+	for _, descendant := range descendants {
+		if descendant.Base.ID == parentID {
+			return ErrCustomerCycle
+		}
+		if descendant.Depth > height {
+			height = descendant.Depth
+		}
+	}
+
+	// шинэ эх харилцагчийн түвшин + өөрөө + доорх салбарууд
+	if len(ancestors)+1+height > MaxCustomerDepth() {
+		return fmt.Errorf("%w: %d", ErrCustomerDepth, MaxCustomerDepth())
+	}
+	return nil
+}
+
+// MoveCustomer салбарыг өөр толгой байгууллага дор шилжүүлнэ. parentID 0 бол толгой болно.
+func MoveCustomer(tx *gorm.DB, customerID, parentID uint, user databases.MedSystemUser) (*databases.MedCustomer, error) {
+	var customer databases.MedCustomer
+	result := tx.First(&customer, customerID)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	if err := ValidateCustomerParent(tx, customerID, parentID); err != nil {
+		return nil, err
+	}
+
+	companyName := ""
+	if parentID != 0 {
+		var parent databases.MedCustomer
+		result = tx.First(&parent, parentID)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		companyName = parent.Name
+	}
+
+	result = tx.Model(&customer).Updates(map[string]interface{}{
+		"parent_id":        parentID,
+		"company_name":     companyName,
+		"modified_date":    time.Now(),
+		"modified_user_id": user.Base.ID,
+	})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	customer.ParentID = parentID
+	customer.CompanyName = companyName
+	return &customer, nil
+}
+
+// CustomerGroupTotals харилцагч болон бүх салбарын хязгаар, захиалга, авлагыг нэгтгэнэ
+func CustomerGroupTotals(db *gorm.DB, customerID uint) (*CustomerGroupSummary, error) {
+	var customer databases.MedCustomer
+	result := db.First(&customer, customerID)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	descendants, err := CustomerDescendants(db, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	maximumPurchase := decimal.NewFromFloat(customer.MaximumPurchase)
+	maximumReceivables := decimal.NewFromFloat(customer.MaximumReceivables)
+	oneTimePurchaseLimit := decimal.NewFromFloat(customer.OneTimePurchaseLimit)
+
+	ids := []uint{customer.Base.ID}
+	for _, descendant := range descendants {
+		ids = append(ids, descendant.Base.ID)
+		maximumPurchase = maximumPurchase.Add(decimal.NewFromFloat(descendant.MaximumPurchase))
+		maximumReceivables = maximumReceivables.Add(decimal.NewFromFloat(descendant.MaximumReceivables))
+		oneTimePurchaseLimit = oneTimePurchaseLimit.Add(decimal.NewFromFloat(descendant.OneTimePurchaseLimit))
+	}
+
+	var orders struct {
+		OrderCount int64
+		OrderTotal databases.Decimal
+	}
+	result = db.Table("med_order_books").
+		Select("COUNT(*) AS order_count, COALESCE(SUM("+outcomeBaseTotal+"), 0) AS order_total").
+		Where("customer_id IN ?", ids).
+		Where("is_removed = ?", false).
+		Scan(&orders)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	// авлагыг харилцагчийн хуулгатай ижил дэвтрээс тооцно
+	receivables, err := ledgerBalance(db, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CustomerGroupSummary{
+		CustomerID:           customer.Base.ID,
+		BranchCount:          len(descendants),
+		MaximumPurchase:      RoundBase(maximumPurchase),
+		MaximumReceivables:   RoundBase(maximumReceivables),
+		OneTimePurchaseLimit: RoundBase(oneTimePurchaseLimit),
+		OrderCount:           orders.OrderCount,
+		OrderTotal:           RoundBase(orders.OrderTotal.Decimal),
+		Receivables:          receivables,
+	}, nil
+}
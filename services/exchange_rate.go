@@ -0,0 +1,298 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+	viper "github.com/spf13/viper"
+	databases "gitlab.com/fibocloud/medtech/gin/databases"
+	gorm "gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Ханшийн алдаанууд
+var (
+	ErrRateUnavailable = errors.New("Ханшийн сервистэй холбогдож чадсангүй")
+	ErrRateNotFound    = errors.New("Тухайн өдрийн ханш олдсонгүй")
+	ErrRateMismatch    = errors.New("Валютын ханш тухайн өдрийн ханшаас хэт зөрүүтэй байна")
+)
+
+// RateProvider валютын ханш татах үйлчилгээ
+type RateProvider interface {
+	Name() string
+	// Rates кодоор ханш буцаана. Олдоогүй кодыг алгасна.
+	Rates(ctx context.Context, codes []string) (map[string]decimal.Decimal, error)
+}
+
+// BaseCurrency ханшийг илэрхийлэх үндсэн валют
+func BaseCurrency() string {
+	if code := viper.GetString("exchangeRate.baseCode"); code != "" {
+		return strings.ToUpper(code)
+	}
+	return "MNT"
+}
+
+// RateTolerance гараар оруулсан ханш өдрийн ханшаас зөрөх дээд хувь
+func RateTolerance() float64 {
+	if tolerance := viper.GetFloat64("exchangeRate.tolerance"); tolerance > 0 {
+		return tolerance
+	}
+	return 5
+}
+
+// NewRateProvider тохиргооноос хамааран үйлчилгээ үүсгэнэ
+func NewRateProvider() RateProvider {
+	switch viper.GetString("exchangeRate.provider") {
+	case "file":
+		return &FileRateProvider{Path: viper.GetString("exchangeRate.fixture")}
+	default:
+		return NewMongolBankProvider()
+	}
+}
+
+// MongolBankProvider Монголбанкны ханшийг monxansh.appspot.com-оос татна
+type MongolBankProvider struct {
+	URL    string
+	Client *http.Client
+}
+
+// NewMongolBankProvider тохиргооноос үйлчилгээ үүсгэнэ
+func NewMongolBankProvider() *MongolBankProvider {
+	rateURL := viper.GetString("exchangeRate.url")
+	if rateURL == "" {
+		rateURL = "http://monxansh.appspot.com/xansh.json"
+	}
+
+	timeout := time.Duration(viper.GetInt("exchangeRate.timeout")) * time.Second
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+
+	return &MongolBankProvider{URL: rateURL, Client: &http.Client{Timeout: timeout}}
+}
+
+// Name provider нэр
+func (p *MongolBankProvider) Name() string {
+	return "mongolbank"
+}
+
+// Rates Монголбанкны өнөөдрийн ханш
+func (p *MongolBankProvider) Rates(ctx context.Context, codes []string) (map[string]decimal.Decimal, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.URL+"?currency="+url.QueryEscape(strings.Join(codes, "|")), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := p.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrRateUnavailable, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %v", ErrRateUnavailable, res.Status)
+	}
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrRateUnavailable, err)
+	}
+
+	var payload []struct {
+		Code      string          `json:"code"`
+		RateFloat decimal.Decimal `json:"rate_float"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrRateUnavailable, err)
+	}
+
+	rates := map[string]decimal.Decimal{}
+	for _, rate := range payload {
+		if rate.RateFloat.IsPositive() {
+			rates[strings.ToUpper(rate.Code)] = rate.RateFloat
+		}
+	}
+	return rates, nil
+}
+
+// FileRateProvider {"USD": 2850.5} хэлбэрийн json файлаас уншдаг туршилтын үйлчилгээ
+type FileRateProvider struct {
+	Path string
+}
+
+// Name provider нэр
+func (p *FileRateProvider) Name() string {
+	return "file"
+}
+
+// Rates файл дахь ханш
+func (p *FileRateProvider) Rates(ctx context.Context, codes []string) (map[string]decimal.Decimal, error) {
+	file, err := ioutil.ReadFile(p.Path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrRateUnavailable, err)
+	}
+
+	var all map[string]decimal.Decimal
+	if err := json.Unmarshal(file, &all); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrRateUnavailable, err)
+	}
+
+	rates := map[string]decimal.Decimal{}
+	for _, code := range codes {
+		if rate, ok := all[strings.ToUpper(code)]; ok {
+			rates[strings.ToUpper(code)] = rate
+		}
+	}
+	return rates, nil
+}
+
+// StartExchangeRateJob идэвхитэй валют бүрийн ханшийг өдөр бүр хадгална
+func StartExchangeRateJob(db *gorm.DB) {
+	hour := viper.GetInt("exchangeRate.runHour")
+	if hour <= 0 {
+		hour = 10
+	}
+
+	ScheduleDaily("exchange_rate", hour, func(ctx context.Context) error {
+		_, err := IngestRates(ctx, db, NewRateProvider(), time.Now())
+		return err
+	})
+}
+
+// IngestRates идэвхитэй валютуудын ханшийг provider-оос татаж date өдрийн ханш болгон
+// хадгална. Тухайн өдөр хадгалсан бол дарж бичнэ.
+func IngestRates(ctx context.Context, db *gorm.DB, provider RateProvider, date time.Time) ([]databases.MedExchangeRate, error) {
+	var valutes []databases.RefValute
+	result := db.Where("is_active = ?", true).Find(&valutes)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	byCode := map[string]databases.RefValute{}
+	var codes []string
+	for _, valute := range valutes {
+		code := strings.ToUpper(strings.TrimSpace(valute.Name))
+		if code == "" || code == BaseCurrency() {
+			continue
+		}
+		byCode[code] = valute
+		codes = append(codes, code)
+	}
+
+	stored := []databases.MedExchangeRate{}
+	if len(codes) == 0 {
+		return stored, nil
+	}
+
+	rates, err := provider.Rates(ctx, codes)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for _, code := range codes {
+		rate, ok := rates[code]
+		if !ok {
+			continue
+		}
+
+		exchangeRate := databases.MedExchangeRate{
+			ValuteID: byCode[code].Base.ID,
+			Code:     code,
+			RateDate: dayStart(date),
+			Rate:     databases.NewDecimal(rate),
+			Source:   provider.Name(),
+			Base: databases.Base{
+				CreatedDate:  now,
+				ModifiedDate: now,
+			},
+		}
+		result := db.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "valute_id"}, {Name: "rate_date"}},
+			DoUpdates: clause.AssignmentColumns([]string{"rate", "source", "modified_date"}),
+		}).Create(&exchangeRate)
+		if result.Error != nil {
+			return stored, result.Error
+		}
+		stored = append(stored, exchangeRate)
+	}
+
+	return stored, nil
+}
+
+// RateOn date өдөр хүчинтэй ханш, өөрөөр хэлбэл тухайн өдөр эсвэл түүнээс өмнөх
+// хамгийн сүүлийн ханш. Амралтын өдөр банк ханш зарладаггүй.
+// Үндсэн валютын ханш үргэлж 1.
+func RateOn(db *gorm.DB, valute databases.RefValute, date time.Time) (*databases.MedExchangeRate, error) {
+	code := strings.ToUpper(strings.TrimSpace(valute.Name))
+	if code == BaseCurrency() {
+		return &databases.MedExchangeRate{
+			ValuteID: valute.Base.ID,
+			Code:     code,
+			RateDate: dayStart(date),
+			Rate:     databases.NewDecimal(decimal.NewFromInt(1)),
+			Source:   "base",
+		}, nil
+	}
+
+	var rate databases.MedExchangeRate
+	result := db.
+		Where("valute_id = ? AND rate_date <= ?", valute.Base.ID, dayStart(date)).
+		Order("rate_date desc").
+		Limit(1).
+		Find(&rate)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("%w: %s %s", ErrRateNotFound, code, date.Format("2006-01-02"))
+	}
+	return &rate, nil
+}
+
+// ResolveValuteValue захиалга, орлогын ValuteValue-г тогтооно: хоосон бол тухайн
+// өдрийн ханш, оруулсан бол RateTolerance хувиас их зөрөхгүй эсэхийг шалгана.
+// valuteID 0 бол төгрөгөөр гэж үзээд оруулсныг нь буцаана.
+func ResolveValuteValue(db *gorm.DB, valuteID uint, date time.Time, entered decimal.Decimal) (decimal.Decimal, error) {
+	if valuteID == 0 {
+		return entered, nil
+	}
+
+	var valute databases.RefValute
+	if err := db.First(&valute, valuteID).Error; err != nil {
+		return decimal.Zero, err
+	}
+
+	rate, err := RateOn(db, valute, date)
+	if err != nil {
+		return decimal.Zero, err
+	}
+
+	if !entered.IsPositive() {
+		return rate.Rate.Decimal, nil
+	}
+
+	diff := entered.Sub(rate.Rate.Decimal).Abs().Div(rate.Rate.Decimal).Mul(decimal.NewFromInt(100))
+	if diff.GreaterThan(decimal.NewFromFloat(RateTolerance())) {
+		return decimal.Zero, fmt.Errorf("%w: %s %s, оруулсан %s", ErrRateMismatch, rate.Code, rate.Rate.StringFixed(2), entered.StringFixed(2))
+	}
+	return entered, nil
+}
+
+// RateHistory валютын from-оос to хүртэлх хадгалсан ханш, шинэ нь эхэндээ
+func RateHistory(db *gorm.DB, valuteID uint, from, to time.Time) ([]databases.MedExchangeRate, error) {
+	rates := []databases.MedExchangeRate{}
+	result := db.
+		Where("valute_id = ? AND rate_date BETWEEN ? AND ?", valuteID, dayStart(from), dayStart(to)).
+		Order("rate_date desc").
+		Find(&rates)
+	return rates, result.Error
+}
@@ -0,0 +1,404 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"unicode"
+
+	databases "gitlab.com/fibocloud/medtech/gin/databases"
+	gorm "gorm.io/gorm"
+)
+
+// ErrGeoType мэдэгдэхгүй түвшин
+var ErrGeoType = errors.New("Түвшин буруу: country, city, district байна")
+
+// Газарзүйн түвшин
+const (
+	GeoCountry  = "country"
+	GeoCity     = "city"
+	GeoDistrict = "district"
+	GeoStreet   = "street"
+)
+
+// geoPathSeparator бүтэн замын тусгаарлагч
+const geoPathSeparator = " › "
+
+// geoFuzzyThreshold үг алдаатай бичсэнийг тааруулах доод хязгаар
+const geoFuzzyThreshold = 0.75
+
+// GeoNode улс, аймаг хот, дүүрэг, хорооны нэг бичлэг
+type GeoNode struct {
+	Type        string `json:"type"`         // country, city, district, street
+	ID          uint   `json:"id"`           //
+	Name        string `json:"name"`         //
+	HasChildren bool   `json:"has_children"` // Модонд дэлгэх боломжтой эсэх
+}
+
+// GeoMatch хайлтын үр дүн, улсаас эхэлсэн бүтэн замтай
+type GeoMatch struct {
+	GeoNode
+	Path  []GeoNode `json:"path"`  // Улс › аймаг, хот › дүүрэг › хороо, өөрийгөө оруулаад
+	Label string    `json:"label"` // Замыг нэг мөр болгосон
+	Score float64   `json:"score"` // 1 бол яг таарсан
+}
+
+// geoEntry хайлтад ачаалсан бичлэг
+type geoEntry struct {
+	node   GeoNode
+	parent *geoEntry
+	words  []string // Өөрийн нэрийн үгс, хөрвүүлсэн
+}
+
+// GeoSearch улс, аймаг хот, дүүрэг, хорооноос нэрийн эхлэл болон алдаатай бичлэгээр
+// хайна. Олон үгтэй бол үг бүр зам дээрх аль нэг нэрт таарах ёстой тул
+// "улаанбаатар баянзүрх" гэвэл Улаанбаатарын Баянзүрхийг олно.
+// types хоосон бол бүх түвшнээс хайна.
+func GeoSearch(db *gorm.DB, query string, types []string, limit int, locales []string) ([]GeoMatch, error) {
+	matches := []GeoMatch{}
+
+	tokens := strings.Fields(geoFold(query))
+	if len(tokens) == 0 {
+		return matches, nil
+	}
+
+	entries, err := loadGeoEntries(db, locales)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := map[string]bool{}
+	for _, geoType := range types {
+		allowed[geoType] = true
+	}
+
+	for _, entry := range entries {
+		if len(allowed) > 0 && !allowed[entry.node.Type] {
+			continue
+		}
+
+		score, ok := scoreGeoEntry(entry, tokens)
+		if !ok {
+			continue
+		}
+
+		match := GeoMatch{GeoNode: entry.node, Score: score}
+		var names []string
+		for node := entry; node != nil; node = node.parent {
+			match.Path = append([]GeoNode{node.node}, match.Path...)
+			names = append([]string{node.node.Name}, names...)
+		}
+		match.Label = strings.Join(names, geoPathSeparator)
+		matches = append(matches, match)
+	}
+
+	level := map[string]int{GeoCountry: 0, GeoCity: 1, GeoDistrict: 2, GeoStreet: 3}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		if level[matches[i].Type] != level[matches[j].Type] {
+			return level[matches[i].Type] < level[matches[j].Type]
+		}
+		return matches[i].Label < matches[j].Label
+	})
+
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches, nil
+}
+
+// scoreGeoEntry үг бүрийн хамгийн сайн таарцын дундаж. Нэг ч үг өөрийн нэрт
+// таараагүй бол эцгийнхээ нэрээр л таарсан гэж үзэж хасна.
+func scoreGeoEntry(entry *geoEntry, tokens []string) (float64, bool) {
+	total := 0.0
+	ownMatched := false
+	for _, token := range tokens {
+		best := 0.0
+		for node := entry; node != nil; node = node.parent {
+			score := 0.0
+			for _, word := range node.words {
+				if wordScore := geoWordScore(word, token); wordScore > score {
+					score = wordScore
+				}
+			}
+			if score > best {
+				best = score
+			}
+			if node == entry && score > 0 {
+				ownMatched = true
+			}
+		}
+		if best == 0 {
+			return 0, false
+		}
+		total += best
+	}
+
+	if !ownMatched {
+		return 0, false
+	}
+	return total / float64(len(tokens)), true
+}
+
+// geoWordScore нэг үг ба хайлтын үгийн таарц: яг 1, эхлэл 0.9, агуулсан 0.6,
+// алдаатай бичсэн бол төстэй байдлын хагас
+func geoWordScore(word, token string) float64 {
+	switch {
+	case word == token:
+		return 1
+	case strings.HasPrefix(word, token):
+		return 0.9
+	case strings.Contains(word, token):
+		return 0.6
+	}
+
+	similarity := NameSimilarity(word, token)
+	// бичиж дуусаагүй үгийг ижил урттай эхлэлтэй нь харьцуулна
+	if wordRunes, tokenRunes := []rune(word), []rune(token); len(wordRunes) > len(tokenRunes) {
+		if prefix := NameSimilarity(string(wordRunes[:len(tokenRunes)]), token); prefix > similarity {
+			similarity = prefix
+		}
+	}
+	if len([]rune(token)) < 3 || similarity < geoFuzzyThreshold {
+		return 0
+	}
+	return similarity / 2
+}
+
+// geoFold жижиг үсэг болгож ө, ү, ё-г о, у, е болгоно. Гараас ихэвчлэн ингэж бичдэг.
+func geoFold(text string) string {
+	text = strings.ToLower(text)
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case 'ө':
+			return 'о'
+		case 'ү':
+			return 'у'
+		case 'ё':
+			return 'е'
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return ' '
+	}, text)
+}
+
+// geoTables хайлтын мөрүүд хамаарах хүснэгтүүд, бичигдвэл кэш хүчингүй болно
+var geoTables = []string{"ref_countries", "ref_cities", "ref_districts", "ref_streets", "med_translations"}
+
+// geoRow кэшлэх нэг бичлэг. Улс, аймаг хот, дүүрэг, хорооны дарааллаар тул эцэг нь түрүүлнэ.
+type geoRow struct {
+	Type     string `json:"type"`
+	ID       uint   `json:"id"`
+	ParentID uint   `json:"parent_id"`
+	Name     string `json:"name"`      // Орчуулсан нэр
+	BaseName string `json:"base_name"` // Name баганын нэр
+}
+
+// loadGeoEntries бүх түвшнийг лавлахын кэшээс авч эцгийг нь холбоно. Үндсэн болон
+// орчуулсан нэрийн аль алинаар хайна.
+func loadGeoEntries(db *gorm.DB, locales []string) ([]*geoEntry, error) {
+	cache := GetRefCache()
+	etag := cache.ETag("geo:entries@"+strings.Join(locales, ","), geoTables)
+	data, err := cache.Load(etag, func() ([]byte, error) {
+		rows, err := loadGeoRows(db, locales)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []geoRow
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, err
+	}
+
+	parentType := map[string]string{GeoCity: GeoCountry, GeoDistrict: GeoCity, GeoStreet: GeoDistrict}
+	byType := map[string]map[uint]*geoEntry{}
+	entries := make([]*geoEntry, 0, len(rows))
+	for _, row := range rows {
+		parent := byType[parentType[row.Type]][row.ParentID]
+		entry := &geoEntry{
+			node:   GeoNode{Type: row.Type, ID: row.ID, Name: row.Name},
+			parent: parent,
+			words:  geoWords(row.Name, row.BaseName),
+		}
+		if parent != nil {
+			parent.node.HasChildren = true
+		}
+		if byType[row.Type] == nil {
+			byType[row.Type] = map[uint]*geoEntry{}
+		}
+		byType[row.Type][row.ID] = entry
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// geoWords нэрсийн хөрвүүлсэн үгс, давхардалгүй
+func geoWords(names ...string) []string {
+	var words []string
+	seen := map[string]bool{}
+	for _, name := range names {
+		for _, word := range strings.Fields(geoFold(name)) {
+			if !seen[word] {
+				seen[word] = true
+				words = append(words, word)
+			}
+		}
+	}
+	return words
+}
+
+// loadGeoRows бүх түвшнийг өгөгдлийн сангаас уншиж нэрийг locales-оор орчуулна
+func loadGeoRows(db *gorm.DB, locales []string) ([]geoRow, error) {
+	var countries []databases.RefCountry
+	if err := db.Select("id, name").Find(&countries).Error; err != nil {
+		return nil, err
+	}
+	var cities []databases.RefCity
+	if err := db.Select("id, name, country_id").Find(&cities).Error; err != nil {
+		return nil, err
+	}
+	var districts []databases.RefDistrict
+	if err := db.Select("id, name, city_id").Find(&districts).Error; err != nil {
+		return nil, err
+	}
+	var streets []databases.RefStreet
+	if err := db.Select("id, name, district_id").Find(&streets).Error; err != nil {
+		return nil, err
+	}
+
+	// орчуулахаас өмнө үндсэн нэрийг хадгална
+	var rows []geoRow
+	for _, country := range countries {
+		rows = append(rows, geoRow{Type: GeoCountry, ID: country.Base.ID, BaseName: country.Name})
+	}
+	for _, city := range cities {
+		rows = append(rows, geoRow{Type: GeoCity, ID: city.Base.ID, ParentID: city.CountryID, BaseName: city.Name})
+	}
+	for _, district := range districts {
+		rows = append(rows, geoRow{Type: GeoDistrict, ID: district.Base.ID, ParentID: district.CityID, BaseName: district.Name})
+	}
+	for _, street := range streets {
+		rows = append(rows, geoRow{Type: GeoStreet, ID: street.Base.ID, ParentID: street.DistrictID, BaseName: street.Name})
+	}
+
+	for _, records := range []interface{}{&countries, &cities, &districts, &streets} {
+		if err := TranslateNames(db, locales, records); err != nil {
+			return nil, err
+		}
+	}
+
+	var names []string
+	for _, country := range countries {
+		names = append(names, country.Name)
+	}
+	for _, city := range cities {
+		names = append(names, city.Name)
+	}
+	for _, district := range districts {
+		names = append(names, district.Name)
+	}
+	for _, street := range streets {
+		names = append(names, street.Name)
+	}
+	for i := range rows {
+		rows[i].Name = names[i]
+	}
+
+	return rows, nil
+}
+
+// GeoChildren модны нэг зангилааны дэд бичлэгүүд. parentType хоосон бол улсууд.
+func GeoChildren(db *gorm.DB, parentType string, parentID uint, locales []string) ([]GeoNode, error) {
+	nodes := []GeoNode{}
+
+	// дэд түвшний хүснэгт, эцгийн багана
+	var childTable, parentColumn string
+	switch parentType {
+	case "":
+		var countries []databases.RefCountry
+		if err := db.Order("name").Find(&countries).Error; err != nil {
+			return nil, err
+		}
+		if err := TranslateNames(db, locales, &countries); err != nil {
+			return nil, err
+		}
+		for _, country := range countries {
+			nodes = append(nodes, GeoNode{Type: GeoCountry, ID: country.Base.ID, Name: country.Name})
+		}
+		childTable, parentColumn = "ref_cities", "country_id"
+	case GeoCountry:
+		var cities []databases.RefCity
+		if err := db.Where("country_id = ?", parentID).Order("sort_order, name").Find(&cities).Error; err != nil {
+			return nil, err
+		}
+		if err := TranslateNames(db, locales, &cities); err != nil {
+			return nil, err
+		}
+		for _, city := range cities {
+			nodes = append(nodes, GeoNode{Type: GeoCity, ID: city.Base.ID, Name: city.Name})
+		}
+		childTable, parentColumn = "ref_districts", "city_id"
+	case GeoCity:
+		var districts []databases.RefDistrict
+		if err := db.Where("city_id = ?", parentID).Order("sort_order, name").Find(&districts).Error; err != nil {
+			return nil, err
+		}
+		if err := TranslateNames(db, locales, &districts); err != nil {
+			return nil, err
+		}
+		for _, district := range districts {
+			nodes = append(nodes, GeoNode{Type: GeoDistrict, ID: district.Base.ID, Name: district.Name})
+		}
+		childTable, parentColumn = "ref_streets", "district_id"
+	case GeoDistrict:
+		var streets []databases.RefStreet
+		if err := db.Where("district_id = ?", parentID).Order("sort_order, name").Find(&streets).Error; err != nil {
+			return nil, err
+		}
+		if err := TranslateNames(db, locales, &streets); err != nil {
+			return nil, err
+		}
+		for _, street := range streets {
+			nodes = append(nodes, GeoNode{Type: GeoStreet, ID: street.Base.ID, Name: street.Name})
+		}
+		return nodes, nil
+	default:
+		return nil, ErrGeoType
+	}
+
+	if len(nodes) == 0 {
+		return nodes, nil
+	}
+
+	var ids []uint
+	for _, node := range nodes {
+		ids = append(ids, node.ID)
+	}
+
+	var parents []uint
+	result := db.Table(childTable).Distinct(parentColumn).Where(parentColumn+" IN ?", ids).Pluck(parentColumn, &parents)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	hasChildren := map[uint]bool{}
+	for _, id := range parents {
+		hasChildren[id] = true
+	}
+	for i := range nodes {
+		nodes[i].HasChildren = hasChildren[nodes[i].ID]
+	}
+
+	return nodes, nil
+}
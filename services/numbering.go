@@ -0,0 +1,251 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	databases "gitlab.com/fibocloud/medtech/gin/databases"
+	gorm "gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Дугаарлах баримтын төрлүүд
+const (
+	NumberCustomer    = "customer"
+	NumberOrderBook   = "order_book"
+	NumberIncome      = "income"
+	NumberOutcome     = "outcome"
+	NumberInvoice     = "invoice"
+	NumberPriceChange = "price_change"
+)
+
+// Дугаар шинээр эхлэх үе
+const (
+	ResetNever   = "never"
+	ResetYearly  = "yearly"
+	ResetMonthly = "monthly"
+	ResetDaily   = "daily"
+)
+
+// DefaultNumberFormats тохиргоо үүсээгүй үед ашиглах анхны утгууд
+var DefaultNumberFormats = map[string]databases.MedNumberFormat{
+	NumberCustomer:    {Code: NumberCustomer, Name: "Харилцагчийн код", DatePattern: "YYYYMM", Padding: 3, ResetPeriod: ResetMonthly, IsActive: true},
+	NumberOrderBook:   {Code: NumberOrderBook, Name: "Захиалгын дугаар", Prefix: "ЗХ", DatePattern: "YYYYMMDD", Separator: "-", Padding: 4, ResetPeriod: ResetDaily, IsActive: true},
+	NumberIncome:      {Code: NumberIncome, Name: "Орлогын дугаар", Prefix: "ОР", DatePattern: "YYYYMM", Separator: "-", Padding: 4, ResetPeriod: ResetMonthly, IsActive: true},
+	NumberOutcome:     {Code: NumberOutcome, Name: "Зарлагын дугаар", Prefix: "ЗР", DatePattern: "YYYYMM", Separator: "-", Padding: 4, ResetPeriod: ResetMonthly, IsActive: true},
+	NumberInvoice:     {Code: NumberInvoice, Name: "Нэхэмжлэхийн дугаар", Prefix: "НХ", DatePattern: "YYYY", Separator: "-", Padding: 6, ResetPeriod: ResetYearly, IsActive: true},
+	NumberPriceChange: {Code: NumberPriceChange, Name: "Үнийн өөрчлөлтийн дугаар", Prefix: "ҮӨ", DatePattern: "YYYYMM", Separator: "-", Padding: 4, ResetPeriod: ResetMonthly, IsActive: true},
+}
+
+// ErrNumberFormat дугаарлалтын тохиргоо буруу
+var ErrNumberFormat = errors.New("Дугаарлалтын тохиргоо буруу байна")
+
+// ValidateNumberFormat тохиргооны утгуудыг шалгана
+func ValidateNumberFormat(format databases.MedNumberFormat) error {
+	switch format.ResetPeriod {
+	case ResetNever, ResetYearly, ResetMonthly, ResetDaily:
+	default:
+		return fmt.Errorf("%w: reset_period %q", ErrNumberFormat, format.ResetPeriod)
+	}
+
+	if format.Padding < 1 || format.Padding > 12 {
+		return fmt.Errorf("%w: padding 1-12", ErrNumberFormat)
+	}
+
+	rest := format.DatePattern
+	for _, token := range []string{"YYYY", "YY", "MM", "DD"} {
+		rest = strings.ReplaceAll(rest, token, "")
+	}
+	if strings.Trim(rest, "-/.") != "" {
+		return fmt.Errorf("%w: date_pattern %q", ErrNumberFormat, format.DatePattern)
+	}
+
+	// Дараалал үе бүр 1-ээс эхэлдэг тул дугаарт тухайн үеийг ялгах огнооны хэсэг байх ёстой
+	for _, token := range numberPeriodTokens[format.ResetPeriod] {
+		if !strings.Contains(format.DatePattern, token) {
+			return fmt.Errorf("%w: %s reset_period-д date_pattern %s агуулна", ErrNumberFormat, format.ResetPeriod, token)
+		}
+	}
+	return nil
+}
+
+// numberPeriodTokens reset_period бүрт date_pattern-д заавал байх огнооны хэсэг
+var numberPeriodTokens = map[string][]string{
+	ResetYearly:  {"YY"},
+	ResetMonthly: {"YY", "MM"},
+	ResetDaily:   {"YY", "MM", "DD"},
+}
+
+// NextNumber тухайн төрлийн дараагийн дугаарыг олгоно. Дуудагчийн transaction
+// дотор ажиллах ба rollback хийвэл дугаар буцаж чөлөөлөгдөнө.
+func NextNumber(tx *gorm.DB, code string, date time.Time) (string, error) {
+	format, err := LoadNumberFormat(tx, code)
+	if err != nil {
+		return "", err
+	}
+
+	periodKey := numberPeriodKey(format.ResetPeriod, date)
+	sequence := databases.MedNumberSequence{
+		FormatCode: format.Code,
+		PeriodKey:  periodKey,
+		Base: databases.Base{
+			CreatedDate:  time.Now(),
+			ModifiedDate: time.Now(),
+		},
+	}
+
+	created := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&sequence)
+	if created.Error != nil {
+		return "", created.Error
+	}
+	if created.RowsAffected > 0 {
+		if err := seedNumberSequence(tx, format, date, &sequence); err != nil {
+			return "", err
+		}
+	}
+
+	err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("format_code = ?", format.Code).
+		Where("period_key = ?", periodKey).
+		First(&sequence).Error
+	if err != nil {
+		return "", err
+	}
+
+	sequence.LastValue++
+	err = tx.Model(&sequence).Updates(map[string]interface{}{
+		"last_value":    sequence.LastValue,
+		"modified_date": time.Now(),
+	}).Error
+	if err != nil {
+		return "", err
+	}
+
+	return FormatNumber(format, date, sequence.LastValue), nil
+}
+
+// numberSources дугаарлалт нэвтрэхээс өмнө олгогдсон дугаарууд хадгалагдсан багана
+var numberSources = map[string]struct{ Table, Column string }{
+	NumberCustomer: {Table: "med_customers", Column: "code"},
+}
+
+// seedNumberSequence шинээр үүссэн үеийн дарааллыг тухайн үед өмнө нь
+// олгогдсон хамгийн их дугаараас эхлүүлнэ
+func seedNumberSequence(tx *gorm.DB, format databases.MedNumberFormat, date time.Time, sequence *databases.MedNumberSequence) error {
+	last, err := existingLastNumber(tx, format, date)
+	if err != nil || last == 0 {
+		return err
+	}
+
+	sequence.LastValue = last
+	return tx.Model(sequence).Update("last_value", last).Error
+}
+
+// existingLastNumber тухайн үеийн угтвартай хадгалагдсан дугааруудын хамгийн ихийг олно
+func existingLastNumber(db *gorm.DB, format databases.MedNumberFormat, date time.Time) (int, error) {
+	source, ok := numberSources[format.Code]
+	if !ok {
+		return 0, nil
+	}
+
+	head := FormatNumber(format, date, 0)
+	head = head[:len(head)-format.Padding]
+
+	var codes []string
+	err := db.Table(source.Table).
+		Where(source.Column+" LIKE ?", head+"%").
+		Pluck(source.Column, &codes).Error
+	if err != nil {
+		return 0, err
+	}
+
+	last := 0
+	for _, existing := range codes {
+		value, err := strconv.Atoi(strings.TrimPrefix(existing, head))
+		if err != nil {
+			continue
+		}
+		if value > last {
+			last = value
+		}
+	}
+	return last, nil
+}
+
+// PreviewNumber дугаар олгохгүйгээр дараагийн дугаарыг харуулна
+func PreviewNumber(db *gorm.DB, code string, date time.Time) (string, error) {
+	format, err := LoadNumberFormat(db, code)
+	if err != nil {
+		return "", err
+	}
+
+	var sequence databases.MedNumberSequence
+	found := db.Where("format_code = ?", format.Code).
+		Where("period_key = ?", numberPeriodKey(format.ResetPeriod, date)).
+		Limit(1).
+		Find(&sequence).RowsAffected
+	if found == 0 {
+		if sequence.LastValue, err = existingLastNumber(db, format, date); err != nil {
+			return "", err
+		}
+	}
+
+	return FormatNumber(format, date, sequence.LastValue+1), nil
+}
+
+// LoadNumberFormat тохиргоог уншина, байхгүй бол анхны утгаар үүсгэнэ
+func LoadNumberFormat(db *gorm.DB, code string) (databases.MedNumberFormat, error) {
+	var format databases.MedNumberFormat
+	if db.Where("code = ?", code).Limit(1).Find(&format).RowsAffected > 0 {
+		if !format.IsActive {
+			return format, fmt.Errorf("%w: %s идэвхгүй", ErrNumberFormat, code)
+		}
+		// Шалгалт нэмэгдэхээс өмнө хадгалсан тохиргоо давхардсан дугаар олгохгүй байх
+		return format, ValidateNumberFormat(format)
+	}
+
+	format, ok := DefaultNumberFormats[code]
+	if !ok {
+		return format, fmt.Errorf("%w: %s", ErrNumberFormat, code)
+	}
+
+	format.CreatedDate = time.Now()
+	format.ModifiedDate = time.Now()
+	err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&format).Error
+	return format, err
+}
+
+// FormatNumber угтвар, огноо, дугаарыг нийлүүлнэ
+func FormatNumber(format databases.MedNumberFormat, date time.Time, value int) string {
+	datePart := numberDatePart(format.DatePattern, date)
+	number := fmt.Sprintf("%0*d", format.Padding, value)
+	if datePart == "" {
+		return format.Prefix + format.Separator + number
+	}
+	return format.Prefix + datePart + format.Separator + number
+}
+
+func numberDatePart(pattern string, date time.Time) string {
+	replacer := strings.NewReplacer(
+		"YYYY", date.Format("2006"),
+		"YY", date.Format("06"),
+		"MM", date.Format("01"),
+		"DD", date.Format("02"),
+	)
+	return replacer.Replace(pattern)
+}
+
+func numberPeriodKey(resetPeriod string, date time.Time) string {
+	switch resetPeriod {
+	case ResetYearly:
+		return date.Format("2006")
+	case ResetMonthly:
+		return date.Format("200601")
+	case ResetDaily:
+		return date.Format("20060102")
+	}
+	return "-"
+}
@@ -0,0 +1,704 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/shopspring/decimal"
+	viper "github.com/spf13/viper"
+	databases "gitlab.com/fibocloud/medtech/gin/databases"
+	utils "gitlab.com/fibocloud/medtech/gin/utils"
+	gorm "gorm.io/gorm"
+)
+
+// Төлбөрийн нөхцөлийн алдаанууд
+var (
+	ErrTermPercent        = errors.New("Хуваан төлөлтийн хувийн нийлбэр 100 байх ёстой")
+	ErrTermDueDays        = errors.New("Хуваан төлөлтийн хоног өсөх дарааллаар, сүүлийнх нь төлөх хоногтой тэнцүү байх ёстой")
+	ErrTermDiscount       = errors.New("Хөнгөлөлт эдлэх хоног төлөх хоногоос хэтэрч болохгүй")
+	ErrTermTaken          = errors.New("Энэ төлбөрийн төрөлд нөхцөл бүртгэлтэй байна")
+	ErrPaymentTypeMissing = errors.New("Харилцагчид төлбөрийн төрөл сонгоогүй байна")
+	ErrOutcomeNotFound    = errors.New("Зарлага олдсонгүй")
+	ErrScheduleNotFound   = errors.New("Зарлагын төлбөрийн хуваарь гараагүй байна")
+	ErrNotifyFailed       = errors.New("Хугацаа хэтэрсэн төлбөрийн мэдэгдэл илгээж чадсангүй")
+)
+
+// PaymentSchedule зарлагын төлбөрийн хуваарь
+type PaymentSchedule struct {
+	OutcomeID     uint                              `json:"outcome_id"`      // Урьдчилан харах үед 0
+	CustomerID    uint                              `json:"customer_id"`     //
+	PaymentTypeID uint                              `json:"payment_type_id"` //
+	Date          time.Time                         `json:"date"`            // Зарлагын огноо
+	Amount        databases.Decimal                 `json:"amount"`          // Нийт дүн
+	DueDate       time.Time                         `json:"due_date"`        // Эцсийн төлөх огноо
+	Term          *databases.MedPaymentTerm         `json:"term"`            // Хуваарь гаргасан нөхцөл
+	Installments  []databases.MedOutcomeInstallment `json:"installments"`    //
+}
+
+// OverdueFilter хугацаа хэтэрсэн төлбөрийн шүүлт
+type OverdueFilter struct {
+	AsOf       time.Time
+	CustomerID uint
+}
+
+// OverdueItem хугацаа хэтэрсэн хуваарь
+type OverdueItem struct {
+	InstallmentID uint              `json:"installment_id"` //
+	OutcomeID     uint              `json:"outcome_id"`     //
+	CustomerID    uint              `json:"customer_id"`    //
+	Code          string            `json:"code"`           // Харилцагчийн код
+	Name          string            `json:"name"`           // Харилцагчийн нэр
+	Seq           int               `json:"seq"`            //
+	DueDate       time.Time         `json:"due_date"`       //
+	Amount        databases.Decimal `json:"amount"`         //
+	PaidAmount    databases.Decimal `json:"paid_amount"`    //
+	Remaining     databases.Decimal `json:"remaining"`      // Төлөх үлдэгдэл
+	DaysOverdue   int               `json:"days_overdue"`   // Хэтэрсэн хоног
+	NotifiedDate  *time.Time        `json:"notified_date"`  // Сүүлд мэдэгдсэн огноо
+}
+
+// OverdueNotifier хугацаа хэтэрсэн төлбөрийг мэдэгдэх үйлчилгээ
+type OverdueNotifier interface {
+	Name() string
+	Notify(ctx context.Context, items []OverdueItem) error
+}
+
+// ValidatePaymentTerm нөхцөлийн хоног, хувийг шалгана
+func ValidatePaymentTerm(term databases.MedPaymentTerm) error {
+	if term.DiscountPercent > 0 && term.DiscountDays > term.NetDays {
+		return ErrTermDiscount
+	}
+	if len(term.Installments) == 0 {
+		return nil
+	}
+
+	total := 0.0
+	previous := 0
+	for _, installment := range term.Installments {
+		if installment.DueDays < previous {
+			return ErrTermDueDays
+		}
+		previous = installment.DueDays
+		total += installment.Percent
+	}
+	if math.Abs(total-100) > 0.0001 {
+		return ErrTermPercent
+	}
+	if previous != term.NetDays {
+		return ErrTermDueDays
+	}
+	return nil
+}
+
+// SavePaymentTerm нөхцөлийг шалгаад хадгална, хуваан төлөлтийг бүхлээр нь солино
+func SavePaymentTerm(db *gorm.DB, term *databases.MedPaymentTerm) error {
+	if err := ValidatePaymentTerm(*term); err != nil {
+		return err
+	}
+
+	var paymentType databases.MedPaymentType
+	if err := db.First(&paymentType, term.PaymentTypeID).Error; err != nil {
+		return err
+	}
+
+	var count int64
+	result := db.Model(&databases.MedPaymentTerm{}).
+		Where("payment_type_id = ? AND id <> ?", term.PaymentTypeID, term.Base.ID).
+		Count(&count)
+	if result.Error != nil {
+		return result.Error
+	}
+	if count > 0 {
+		return ErrTermTaken
+	}
+
+	installments := term.Installments
+	term.Installments = nil
+	defer func() {
+		term.Installments = installments
+	}()
+
+	if err := db.Save(term).Error; err != nil {
+		return err
+	}
+	if err := db.Where("payment_term_id = ?", term.Base.ID).Delete(&databases.MedPaymentTermInstallment{}).Error; err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for i, installment := range installments {
+		installment.PaymentTermID = term.Base.ID
+		installment.Seq = i + 1
+		installment.Base = databases.Base{CreatedDate: now, ModifiedDate: now}
+		if err := db.Create(installment).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LegacyPaymentTerm нөхцөл бүртгээгүй төлбөрийн төрлийн PaymentDay, PrepaidPercent-ээс
+// нөхцөл үүсгэнэ: урьдчилгааг тэр өдөртөө, үлдэгдлийг PaymentDay хоногт
+func LegacyPaymentTerm(paymentType databases.MedPaymentType) databases.MedPaymentTerm {
+	term := databases.MedPaymentTerm{
+		PaymentTypeID: paymentType.Base.ID,
+		NetDays:       int(paymentType.PaymentDay),
+		Description:   paymentType.PaymentCondition,
+		IsActive:      true,
+	}
+
+	switch {
+	case paymentType.PrepaidPercent >= 100:
+		term.NetDays = 0
+	case paymentType.PrepaidPercent > 0:
+		term.Installments = []*databases.MedPaymentTermInstallment{
+			{Seq: 1, Percent: float64(paymentType.PrepaidPercent), DueDays: 0},
+			{Seq: 2, Percent: float64(100 - paymentType.PrepaidPercent), DueDays: term.NetDays},
+		}
+	}
+	return term
+}
+
+// TermForPaymentType төлбөрийн төрлийн идэвхитэй нөхцөл, бүртгээгүй бол LegacyPaymentTerm
+func TermForPaymentType(db *gorm.DB, paymentTypeID uint) (*databases.MedPaymentTerm, error) {
+	var term databases.MedPaymentTerm
+	result := db.
+		Preload("Installments", func(db *gorm.DB) *gorm.DB {
+			return db.Order("seq")
+		}).
+		Where("payment_type_id = ? AND is_active = ?", paymentTypeID, true).
+		Limit(1).
+		Find(&term)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected > 0 {
+		return &term, nil
+	}
+
+	var paymentType databases.MedPaymentType
+	if err := db.First(&paymentType, paymentTypeID).Error; err != nil {
+		return nil, err
+	}
+	term = LegacyPaymentTerm(paymentType)
+	return &term, nil
+}
+
+// PlanInstallments date өдрийн amount дүнг нөхцлөөр хуваарилна. Сүүлийн хэсэг
+// бүхэлчлэлийн зөрүүг авна. Хөнгөлөлт эдлэх өдрөөс хойш төлөх хэсэгт л хөнгөлөлт өгнө.
+func PlanInstallments(term databases.MedPaymentTerm, date time.Time, amount decimal.Decimal) []databases.MedOutcomeInstallment {
+	parts := term.Installments
+	if len(parts) == 0 {
+		parts = []*databases.MedPaymentTermInstallment{{Seq: 1, Percent: 100, DueDays: term.NetDays}}
+	}
+
+	places, mode := utils.CurrencyRounding(BaseCurrency())
+	start := dayStart(date)
+
+	var discountDate *time.Time
+	if term.DiscountPercent > 0 && term.DiscountDays > 0 {
+		date := start.AddDate(0, 0, term.DiscountDays)
+		discountDate = &date
+	}
+
+	installments := []databases.MedOutcomeInstallment{}
+	rest := amount
+	for i, part := range parts {
+		value := rest
+		if i < len(parts)-1 {
+			value = utils.RoundDecimal(amount.Mul(decimal.NewFromFloat(part.Percent)).Div(decimal.NewFromInt(100)), places, mode)
+		}
+		rest = rest.Sub(value)
+
+		installment := databases.MedOutcomeInstallment{
+			PaymentTypeID: term.PaymentTypeID,
+			Seq:           i + 1,
+			DueDate:       start.AddDate(0, 0, part.DueDays),
+			Amount:        databases.NewDecimal(value),
+		}
+		if discountDate != nil && installment.DueDate.After(*discountDate) {
+			installment.DiscountDate = discountDate
+			installment.DiscountAmount = databases.NewDecimal(utils.RoundDecimal(value.Mul(decimal.NewFromFloat(term.DiscountPercent)).Div(decimal.NewFromInt(100)), places, mode))
+		}
+		installments = append(installments, installment)
+	}
+	return installments
+}
+
+// PreviewSchedule харилцагчийн төлбөрийн төрлөөр date өдрийн amount дүнтэй зарлагын
+// хуваарийг хадгалахгүйгээр гаргана. Захиалгын DueDate-ийг үүгээр бөглөнө.
+func PreviewSchedule(db *gorm.DB, customerID uint, date time.Time, amount decimal.Decimal) (*PaymentSchedule, error) {
+	var customer databases.MedCustomer
+	if err := db.First(&customer, customerID).Error; err != nil {
+		return nil, err
+	}
+	if customer.PaymentTypeID == 0 {
+		return nil, ErrPaymentTypeMissing
+	}
+
+	term, err := TermForPaymentType(db, customer.PaymentTypeID)
+	if err != nil {
+		return nil, err
+	}
+
+	installments := PlanInstallments(*term, date, amount)
+	for i := range installments {
+		installments[i].CustomerID = customerID
+	}
+
+	return &PaymentSchedule{
+		CustomerID:    customerID,
+		PaymentTypeID: customer.PaymentTypeID,
+		Date:          date,
+		Amount:        databases.NewDecimal(amount),
+		DueDate:       installments[len(installments)-1].DueDate,
+		Term:          term,
+		Installments:  installments,
+	}, nil
+}
+
+// outcomeRow хуваарь гаргахад хэрэгтэй зарлагын талбарууд
+type outcomeRow struct {
+	ID          uint
+	CustomerID  uint
+	Total       databases.Decimal // Үндсэн валютаар
+	CreatedDate time.Time
+}
+
+func loadOutcome(db *gorm.DB, outcomeID uint) (*outcomeRow, error) {
+	var outcome outcomeRow
+	result := db.Table("med_outcomes").
+		Select("id, customer_id, "+outcomeBaseTotal+" AS total, created_date").
+		Where("id = ?", outcomeID).
+		Limit(1).
+		Find(&outcome)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("%w: %d", ErrOutcomeNotFound, outcomeID)
+	}
+	return &outcome, nil
+}
+
+// createOutcomeSchedule зарлагын хуваарийг дахин гаргаж хадгална, төлөлтийг тулгахгүй
+func createOutcomeSchedule(db *gorm.DB, outcome outcomeRow) (*PaymentSchedule, error) {
+	schedule, err := PreviewSchedule(db, outcome.CustomerID, outcome.CreatedDate, RoundBase(outcome.Total.Decimal).Decimal)
+	if err != nil {
+		return nil, err
+	}
+	schedule.OutcomeID = outcome.ID
+
+	if err := db.Where("outcome_id = ?", outcome.ID).Delete(&databases.MedOutcomeInstallment{}).Error; err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for i := range schedule.Installments {
+		schedule.Installments[i].OutcomeID = outcome.ID
+		schedule.Installments[i].Base = databases.Base{CreatedDate: now, ModifiedDate: now}
+	}
+	if err := db.Create(&schedule.Installments).Error; err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+// GenerateOutcomeSchedule зарлагын харилцагчийн төлбөрийн төрлөөр хуваарь гаргаж,
+// өмнөх хуваарийг солино. Харилцагчийн төлбөрийг дахин тулгана.
+func GenerateOutcomeSchedule(db *gorm.DB, outcomeID uint) (*PaymentSchedule, error) {
+	outcome, err := loadOutcome(db, outcomeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := createOutcomeSchedule(db, *outcome); err != nil {
+		return nil, err
+	}
+	return OutcomeSchedule(db, outcomeID)
+}
+
+// GenerateMissingSchedules хуваарьгүй зарлагуудад хуваарь гаргана. Төлбөрийн төрөлгүй
+// харилцагчийн зарлагыг алгасна.
+func GenerateMissingSchedules(db *gorm.DB) (int, error) {
+	var outcomes []outcomeRow
+	result := db.Table("med_outcomes o").
+		Select("o.id, o.customer_id, o.total * COALESCE(NULLIF(o.valute_value, 0), 1) AS total, o.created_date").
+		Where("NOT EXISTS (SELECT 1 FROM med_outcome_installments i WHERE i.outcome_id = o.id)").
+		Order("o.id").
+		Find(&outcomes)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	created := 0
+	var customerIDs []uint
+	seen := map[uint]bool{}
+	for _, outcome := range outcomes {
+		if _, err := createOutcomeSchedule(db, outcome); err != nil {
+			if errors.Is(err, ErrPaymentTypeMissing) || errors.Is(err, gorm.ErrRecordNotFound) {
+				log.Println("payment schedule: outcome", outcome.ID, "skipped:", err)
+				continue
+			}
+			return created, err
+		}
+		created++
+		if !seen[outcome.CustomerID] {
+			seen[outcome.CustomerID] = true
+			customerIDs = append(customerIDs, outcome.CustomerID)
+		}
+	}
+
+	return created, SyncInstallments(db, customerIDs, time.Now())
+}
+
+// OutcomeSchedule зарлагын хадгалсан хуваарь, төлөлтийг тулгасны дараа
+func OutcomeSchedule(db *gorm.DB, outcomeID uint) (*PaymentSchedule, error) {
+	outcome, err := loadOutcome(db, outcomeID)
+	if err != nil {
+		return nil, err
+	}
+	if err := SyncInstallments(db, []uint{outcome.CustomerID}, time.Now()); err != nil {
+		return nil, err
+	}
+
+	var installments []databases.MedOutcomeInstallment
+	if err := db.Where("outcome_id = ?", outcomeID).Order("seq").Find(&installments).Error; err != nil {
+		return nil, err
+	}
+	if len(installments) == 0 {
+		return nil, ErrScheduleNotFound
+	}
+
+	schedule := PaymentSchedule{
+		OutcomeID:     outcomeID,
+		CustomerID:    outcome.CustomerID,
+		PaymentTypeID: installments[0].PaymentTypeID,
+		Date:          outcome.CreatedDate,
+		DueDate:       installments[len(installments)-1].DueDate,
+		Installments:  installments,
+	}
+	for _, installment := range installments {
+		schedule.Amount = databases.NewDecimal(schedule.Amount.Add(installment.Amount.Decimal))
+	}
+
+	if schedule.PaymentTypeID != 0 {
+		term, err := TermForPaymentType(db, schedule.PaymentTypeID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		schedule.Term = term
+	}
+	return &schedule, nil
+}
+
+// outcomePaid нэг харилцагчийн гүйлгээнээс зарлага бүрийн төлөгдсөн дүнг OpenItems-тэй
+// ижил дарааллаар, өөрөөр хэлбэл хамгийн хуучин зарлагаас эхлэн олно
+func outcomePaid(entries []LedgerEntry) map[uint]decimal.Decimal {
+	open := map[uint]decimal.Decimal{}
+	for _, item := range OpenItems(entries) {
+		open[item.DocumentID] = open[item.DocumentID].Add(item.Debit.Decimal)
+	}
+
+	paid := map[uint]decimal.Decimal{}
+	for _, entry := range entries {
+		if entry.Type != LedgerOutcome {
+			continue
+		}
+		paid[entry.DocumentID] = entry.Debit.Sub(open[entry.DocumentID])
+	}
+	return paid
+}
+
+// allocatePaid зарлагын төлөгдсөн дүнг хуваарийн эхнийхээс эхлэн хуваарилна
+func allocatePaid(installments []databases.MedOutcomeInstallment, paid decimal.Decimal) []decimal.Decimal {
+	amounts := make([]decimal.Decimal, len(installments))
+	for i, installment := range installments {
+		amount := decimal.Min(installment.Amount.Decimal, paid)
+		if amount.IsNegative() {
+			amount = decimal.Zero
+		}
+		amounts[i] = amount
+		paid = paid.Sub(amount)
+	}
+	return amounts
+}
+
+// installmentSettled хуваарь төлөгдсөн эсэх. Эрт төлөлтийн хөнгөлөлт авлагын гүйлгээнд
+// орлого болж бичигддэггүй тул зөвхөн бүтэн дүнг төлсөн бол төлөгдсөнд тооцно.
+// Хөнгөлөлтийг эдлүүлэхдээ түүнийг төлбөр болгон бүртгэнэ.
+func installmentSettled(installment databases.MedOutcomeInstallment, paid decimal.Decimal) bool {
+	return paid.GreaterThanOrEqual(installment.Amount.Decimal)
+}
+
+// SyncInstallments харилцагчдын төлбөр, буцаалтыг хуваарьт тулгаж PaidAmount, IsPaid-ийг
+// шинэчилнэ. med_outcomes.is_paid багана байвал бүх хуваарь нь төлөгдсөн зарлагыг тэмдэглэнэ.
+func SyncInstallments(db *gorm.DB, customerIDs []uint, asOf time.Time) error {
+	if len(customerIDs) == 0 {
+		return nil
+	}
+
+	entries, err := CustomerLedger(db, customerIDs, dayStart(asOf).AddDate(0, 0, 1))
+	if err != nil {
+		return err
+	}
+
+	byCustomer := map[uint][]LedgerEntry{}
+	for _, entry := range entries {
+		byCustomer[entry.CustomerID] = append(byCustomer[entry.CustomerID], entry)
+	}
+	paid := map[uint]decimal.Decimal{}
+	for _, customerEntries := range byCustomer {
+		for outcomeID, amount := range outcomePaid(customerEntries) {
+			paid[outcomeID] = amount
+		}
+	}
+
+	var installments []databases.MedOutcomeInstallment
+	result := db.Where("customer_id IN ?", customerIDs).Order("outcome_id, seq").Find(&installments)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	byOutcome := map[uint][]databases.MedOutcomeInstallment{}
+	var outcomeIDs []uint
+	for _, installment := range installments {
+		if _, ok := byOutcome[installment.OutcomeID]; !ok {
+			outcomeIDs = append(outcomeIDs, installment.OutcomeID)
+		}
+		byOutcome[installment.OutcomeID] = append(byOutcome[installment.OutcomeID], installment)
+	}
+
+	now := time.Now()
+	var paidOutcomes, openOutcomes []uint
+	for _, outcomeID := range outcomeIDs {
+		list := byOutcome[outcomeID]
+		amounts := allocatePaid(list, paid[outcomeID])
+
+		allPaid := true
+		for i, installment := range list {
+			settled := installmentSettled(installment, amounts[i])
+			allPaid = allPaid && settled
+			if installment.PaidAmount.Equal(amounts[i]) && installment.IsPaid == settled {
+				continue
+			}
+
+			var paidDate *time.Time
+			if settled {
+				paidDate = &now
+				if installment.IsPaid && installment.PaidDate != nil {
+					paidDate = installment.PaidDate
+				}
+			}
+			result := db.Model(&databases.MedOutcomeInstallment{}).
+				Where("id = ?", installment.Base.ID).
+				Updates(map[string]interface{}{
+					"paid_amount":   amounts[i],
+					"is_paid":       settled,
+					"paid_date":     paidDate,
+					"modified_date": now,
+				})
+			if result.Error != nil {
+				return result.Error
+			}
+		}
+
+		if allPaid {
+			paidOutcomes = append(paidOutcomes, outcomeID)
+		} else {
+			openOutcomes = append(openOutcomes, outcomeID)
+		}
+	}
+
+	if !db.Migrator().HasColumn("med_outcomes", "is_paid") {
+		return nil
+	}
+	if len(paidOutcomes) > 0 {
+		if err := db.Table("med_outcomes").Where("id IN ?", paidOutcomes).Update("is_paid", true).Error; err != nil {
+			return err
+		}
+	}
+	if len(openOutcomes) > 0 {
+		if err := db.Table("med_outcomes").Where("id IN ?", openOutcomes).Update("is_paid", false).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SyncOpenInstallments төлөгдөөгүй хуваарьтай бүх харилцагчийн төлөлтийг тулгана
+func SyncOpenInstallments(db *gorm.DB, asOf time.Time) error {
+	var customerIDs []uint
+	result := db.Model(&databases.MedOutcomeInstallment{}).
+		Where("is_paid = ?", false).
+		Distinct("customer_id").
+		Pluck("customer_id", &customerIDs)
+	if result.Error != nil {
+		return result.Error
+	}
+	return SyncInstallments(db, customerIDs, asOf)
+}
+
+// OverdueInstallments asOf өдрөөс өмнө төлөх байсан, төлөгдөөгүй хуваарь
+func OverdueInstallments(db *gorm.DB, filter OverdueFilter) ([]OverdueItem, error) {
+	if filter.AsOf.IsZero() {
+		filter.AsOf = time.Now()
+	}
+	asOf := dayStart(filter.AsOf)
+
+	query := db.Table("med_outcome_installments i").
+		Select("i.id AS installment_id, i.outcome_id, i.customer_id, c.code, c.name, i.seq, i.due_date, i.amount, i.paid_amount, i.notified_date").
+		Joins("JOIN med_customers c ON c.id = i.customer_id").
+		Where("i.is_paid = ? AND i.due_date < ?", false, asOf)
+	if filter.CustomerID != 0 {
+		query = query.Where("i.customer_id = ?", filter.CustomerID)
+	}
+
+	items := []OverdueItem{}
+	if err := query.Order("i.due_date, c.name, i.seq").Scan(&items).Error; err != nil {
+		return nil, err
+	}
+
+	for i := range items {
+		items[i].Remaining = databases.NewDecimal(items[i].Amount.Sub(items[i].PaidAmount.Decimal))
+		items[i].DaysOverdue = int(asOf.Sub(dayStart(items[i].DueDate)).Hours() / 24)
+	}
+	return items, nil
+}
+
+// ReminderDays нэг хуваарийг дахин мэдэгдэх хүртэлх хоног
+func ReminderDays() int {
+	if days := viper.GetInt("paymentTerms.remindDays"); days > 0 {
+		return days
+	}
+	return 7
+}
+
+// NotifyOverdue сүүлийн ReminderDays хоногт мэдэгдээгүй, хугацаа хэтэрсэн хуваарийг
+// notifier-ээр илгээж NotifiedDate-ийг тэмдэглэнэ
+func NotifyOverdue(ctx context.Context, db *gorm.DB, notifier OverdueNotifier, asOf time.Time) (int, error) {
+	items, err := OverdueInstallments(db, OverdueFilter{AsOf: asOf})
+	if err != nil {
+		return 0, err
+	}
+
+	remindAfter := dayStart(asOf).AddDate(0, 0, -ReminderDays())
+	var due []OverdueItem
+	var ids []uint
+	for _, item := range items {
+		if item.NotifiedDate != nil && item.NotifiedDate.After(remindAfter) {
+			continue
+		}
+		due = append(due, item)
+		ids = append(ids, item.InstallmentID)
+	}
+	if len(due) == 0 {
+		return 0, nil
+	}
+
+	if err := notifier.Notify(ctx, due); err != nil {
+		return 0, err
+	}
+
+	result := db.Model(&databases.MedOutcomeInstallment{}).
+		Where("id IN ?", ids).
+		Update("notified_date", time.Now())
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return len(due), nil
+}
+
+// NewOverdueNotifier тохиргооноос хамааран мэдэгдэх үйлчилгээ үүсгэнэ
+func NewOverdueNotifier() OverdueNotifier {
+	switch viper.GetString("paymentTerms.notifier") {
+	case "webhook":
+		timeout := time.Duration(viper.GetInt("paymentTerms.timeout")) * time.Second
+		if timeout <= 0 {
+			timeout = 5 * time.Second
+		}
+		return &WebhookNotifier{URL: viper.GetString("paymentTerms.webhookURL"), Client: &http.Client{Timeout: timeout}}
+	default:
+		return &LogNotifier{}
+	}
+}
+
+// LogNotifier хугацаа хэтэрсэн төлбөрийг логт бичнэ
+type LogNotifier struct{}
+
+// Name notifier нэр
+func (n *LogNotifier) Name() string {
+	return "log"
+}
+
+// Notify хуваарь бүрийг логт бичнэ
+func (n *LogNotifier) Notify(ctx context.Context, items []OverdueItem) error {
+	for _, item := range items {
+		log.Printf("overdue: customer %s outcome %d #%d due %s remaining %s (%d days)",
+			item.Code, item.OutcomeID, item.Seq, item.DueDate.Format("2006-01-02"), item.Remaining.StringFixed(2), item.DaysOverdue)
+	}
+	return nil
+}
+
+// WebhookNotifier хугацаа хэтэрсэн төлбөрийг {"items": [...]} хэлбэрээр URL руу POST хийнэ
+type WebhookNotifier struct {
+	URL    string
+	Client *http.Client
+}
+
+// Name notifier нэр
+func (n *WebhookNotifier) Name() string {
+	return "webhook"
+}
+
+// Notify бүх хуваарийг нэг хүсэлтээр илгээнэ
+func (n *WebhookNotifier) Notify(ctx context.Context, items []OverdueItem) error {
+	body, err := json.Marshal(map[string]interface{}{"items": items})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrNotifyFailed, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := n.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrNotifyFailed, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("%w: %v", ErrNotifyFailed, res.Status)
+	}
+	return nil
+}
+
+// StartPaymentScheduleJob өдөр бүр хуваарьгүй зарлагад хуваарь гаргаж, төлөлтийг тулгаад
+// хугацаа хэтэрсэн төлбөрийг мэдэгдэнэ
+func StartPaymentScheduleJob(db *gorm.DB) {
+	hour := viper.GetInt("paymentTerms.runHour")
+	if hour <= 0 {
+		hour = 8
+	}
+
+	ScheduleDaily("payment_schedule", hour, func(ctx context.Context) error {
+		if _, err := GenerateMissingSchedules(db); err != nil {
+			return err
+		}
+		if err := SyncOpenInstallments(db, time.Now()); err != nil {
+			return err
+		}
+		_, err := NotifyOverdue(ctx, db, NewOverdueNotifier(), time.Now())
+		return err
+	})
+}
@@ -0,0 +1,48 @@
+package services
+
+import (
+	databases "gitlab.com/fibocloud/medtech/gin/databases"
+	gorm "gorm.io/gorm"
+)
+
+// UserHasRole хэрэглэгч codes дотроос аль нэг дүртэй эсэх
+func UserHasRole(db *gorm.DB, user databases.MedSystemUser, codes []string) (bool, error) {
+	if user.Base.ID == 0 || len(codes) == 0 {
+		return false, nil
+	}
+
+	var roles []databases.MedSystemRole
+	err := db.Model(&user).Association("Roles").Find(&roles)
+	if err != nil {
+		return false, err
+	}
+
+	for _, role := range roles {
+		for _, code := range codes {
+			if role.Code == code {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// UserHasPermission хэрэглэгчийн аль нэг дүрд code эрх олгогдсон эсэх
+func UserHasPermission(db *gorm.DB, user databases.MedSystemUser, code string) (bool, error) {
+	if user.Base.ID == 0 || code == "" {
+		return false, nil
+	}
+
+	var count int64
+	result := db.Table("map_users_roles").
+		Joins("JOIN map_permissions_roles ON map_permissions_roles.med_system_role_id = map_users_roles.med_system_role_id").
+		Joins("JOIN med_system_permissions ON med_system_permissions.id = map_permissions_roles.med_system_permission_id").
+		Where("map_users_roles.med_system_user_id = ?", user.Base.ID).
+		Where("med_system_permissions.code = ?", code).
+		Where("med_system_permissions.is_active = ?", true).
+		Count(&count)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return count > 0, nil
+}
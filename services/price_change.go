@@ -0,0 +1,448 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+	viper "github.com/spf13/viper"
+	databases "gitlab.com/fibocloud/medtech/gin/databases"
+	gorm "gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Үнийн өөрчлөлтийн баримтын төлөв
+const (
+	PriceChangeDraft    = "draft"    // Ноорог
+	PriceChangeApproved = "approved" // Баталсан, хэрэгжих огноог хүлээж буй
+	PriceChangeApplied  = "applied"  // Хэрэгжсэн
+	PriceChangeRejected = "rejected" // Татгалзсан
+	PriceChangeFailed   = "failed"   // Хэрэгжүүлэх үед алдаа гарсан, apply_error-д шалтгаан
+)
+
+// Үнийн өөрчлөлтийн мөрийн төрөл
+const (
+	PriceChangeWarehouse = "warehouse" // Агуулахын барааны үнэ
+	PriceChangeCustomer  = "customer"  // Харилцагчийн тусгай үнэ
+)
+
+// Үнийн өөрчлөлтийн алдаанууд
+var (
+	ErrPriceChangeEmpty   = errors.New("Үнийн өөрчлөлтийн мөр оруулна уу")
+	ErrPriceChangeLine    = errors.New("Үнийн өөрчлөлтийн мөр буруу байна")
+	ErrPriceChangeState   = errors.New("Энэ төлөвтэй баримтыг өөрчлөх боломжгүй")
+	ErrPriceApprovalRight = errors.New("Үнэ батлах эрхгүй байна")
+	ErrPriceSelfApproval  = errors.New("Өөрийн үүсгэсэн баримтыг батлах боломжгүй")
+	ErrPriceRejectReason  = errors.New("Татгалзах шалтгаан оруулна уу")
+)
+
+// PriceHistoryQuery үнийн түүх шүүх нөхцөл
+type PriceHistoryQuery struct {
+	WarehouseItemID uint
+	ItemID          uint
+	CustomerID      uint
+	PriceTypeID     uint
+}
+
+// PriceApprovePermission үнэ батлах эрхийн код
+func PriceApprovePermission() string {
+	if code := viper.GetString("pricing.approvePermission"); code != "" {
+		return code
+	}
+	return "price_approve"
+}
+
+// CreatePriceChange мөр бүрийн одоогийн үнийг хуучин утга болгон бичиж ноорог баримт үүсгэнэ
+func CreatePriceChange(tx *gorm.DB, change *databases.MedPriceChange) error {
+	if len(change.Lines) == 0 {
+		return ErrPriceChangeEmpty
+	}
+
+	for i, line := range change.Lines {
+		err := preparePriceChangeLine(tx, line)
+		if err != nil {
+			return err
+		}
+
+		// нэг баримт дотор ижил харилцагч, бараанд давхцах хугацаа өгөхгүй
+		for _, other := range change.Lines[:i] {
+			if line.Kind == PriceChangeCustomer && other.Kind == PriceChangeCustomer &&
+				line.CustomerID == other.CustomerID && line.ItemID == other.ItemID &&
+				!line.StartDate.After(*other.EndDate) && !line.EndDate.Before(*other.StartDate) {
+				return fmt.Errorf("%w: %d-р бараа давхардсан", ErrPriceOverlap, line.ItemID)
+			}
+		}
+	}
+
+	code, err := NextNumber(tx, NumberPriceChange, time.Now())
+	if err != nil {
+		return err
+	}
+
+	change.Code = code
+	change.State = PriceChangeDraft
+	return tx.Create(change).Error
+}
+
+// preparePriceChangeLine мөрийг шалгаж хуучин үнийг бөглөнө, шинэ үнийг бүхэлчилнэ
+func preparePriceChangeLine(tx *gorm.DB, line *databases.MedPriceChangeDtl) error {
+	line.NewPrice = RoundBase(line.NewPrice.Decimal)
+
+	switch line.Kind {
+	case PriceChangeWarehouse:
+		if line.WarehouseItemID == 0 || line.PriceTypeID == 0 {
+			return fmt.Errorf("%w: агуулахын бараа, үнийн төрөл сонгоно уу", ErrPriceChangeLine)
+		}
+
+		var warehouseItem databases.MedWarehouseItem
+		result := tx.First(&warehouseItem, line.WarehouseItemID)
+		if result.Error != nil {
+			return result.Error
+		}
+		line.ItemID = warehouseItem.ItemID
+
+		current, err := currentWarehousePrice(tx, line.WarehouseItemID, line.PriceTypeID)
+		if err != nil {
+			return err
+		}
+		line.OldPrice = RoundBase(decimal.NewFromFloat(current.SalesPrice))
+
+	case PriceChangeCustomer:
+		if line.CustomerID == 0 || line.ItemID == 0 || line.StartDate == nil || line.EndDate == nil {
+			return fmt.Errorf("%w: харилцагч, бараа, хугацаа оруулна уу", ErrPriceChangeLine)
+		}
+
+		err := CheckCustomerPriceOverlap(tx, line.CustomerID, line.ItemID, *line.StartDate, *line.EndDate, 0)
+		if err != nil {
+			return err
+		}
+
+		line.PriceTypeID = CustomerPriceTypeID()
+		if line.IsPercent {
+			line.NewPrice = databases.Decimal{}
+		} else {
+			line.NewPercent = 0
+		}
+
+		var current databases.MedPriceCustomer
+		result := tx.
+			Where("customer_id = ?", line.CustomerID).
+			Where("item_id = ?", line.ItemID).
+			Where("is_active = ?", true).
+			Where("start_date <= ?", time.Now()).
+			Where("end_date >= ?", time.Now()).
+			Limit(1).
+			Find(&current)
+		if result.Error != nil {
+			return result.Error
+		}
+		line.OldPrice = RoundBase(decimal.NewFromFloat(current.SalesPrice))
+		line.OldPercent = current.Percent
+
+	default:
+		return fmt.Errorf("%w: %s", ErrPriceChangeLine, line.Kind)
+	}
+
+	return nil
+}
+
+// ApprovePriceChange үнэ батлах эрхтэй хэрэглэгч ноорог баримтыг батална.
+// Хэрэгжих огноо болсон бол шууд хэрэгжүүлнэ.
+func ApprovePriceChange(tx *gorm.DB, id uint, user databases.MedSystemUser) (*databases.MedPriceChange, error) {
+	allowed, err := UserHasPermission(tx, user, PriceApprovePermission())
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, ErrPriceApprovalRight
+	}
+
+	change, err := lockPriceChange(tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if change.State != PriceChangeDraft {
+		return nil, ErrPriceChangeState
+	}
+	if change.CreatedUserID == user.Base.ID {
+		return nil, ErrPriceSelfApproval
+	}
+
+	now := time.Now()
+	change.State = PriceChangeApproved
+	change.ApprovedUserID = user.Base.ID
+	change.ApprovedDate = &now
+	change.ModifiedUserID = user.Base.ID
+	change.Base.ModifiedDate = now
+
+	result := tx.Omit(clause.Associations).Save(change)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	if !change.EffectiveDate.After(now) {
+		err = applyPriceChange(tx, change, user.Base.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return change, nil
+}
+
+// RejectPriceChange ноорог баримтаас татгалзана
+func RejectPriceChange(tx *gorm.DB, id uint, reason string, user databases.MedSystemUser) (*databases.MedPriceChange, error) {
+	if strings.TrimSpace(reason) == "" {
+		return nil, ErrPriceRejectReason
+	}
+
+	allowed, err := UserHasPermission(tx, user, PriceApprovePermission())
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, ErrPriceApprovalRight
+	}
+
+	change, err := lockPriceChange(tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if change.State != PriceChangeDraft {
+		return nil, ErrPriceChangeState
+	}
+
+	change.State = PriceChangeRejected
+	change.RejectReason = strings.TrimSpace(reason)
+	change.ModifiedUserID = user.Base.ID
+	change.Base.ModifiedDate = time.Now()
+
+	result := tx.Omit(clause.Associations).Save(change)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return change, nil
+}
+
+// StartPriceChangeJob хэрэгжих огноо болсон баталсан баримтуудыг тогтмол хэрэгжүүлнэ
+func StartPriceChangeJob(db *gorm.DB) {
+	interval := time.Duration(viper.GetInt("pricing.applyInterval")) * time.Minute
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+
+	Schedule("price_change", interval, func(ctx context.Context) error {
+		_, err := ApplyDuePriceChanges(db)
+		return err
+	})
+}
+
+// ApplyDuePriceChanges баримт бүрийг тусдаа транзакцаар хэрэгжүүлнэ. Хэрэгжээгүй баримтыг
+// failed төлөвт оруулж алдааг бичээд дараагийн баримтыг үргэлжлүүлнэ.
+func ApplyDuePriceChanges(db *gorm.DB) (int, error) {
+	var ids []uint
+	result := db.Model(&databases.MedPriceChange{}).
+		Where("state = ?", PriceChangeApproved).
+		Where("effective_date <= ?", time.Now()).
+		Order("effective_date asc").
+		Pluck("id", &ids)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	applied := 0
+	for _, id := range ids {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			change, err := lockPriceChange(tx, id)
+			if err != nil {
+				return err
+			}
+			// өөр процесс түрүүлж хэрэгжүүлсэн
+			if change.State != PriceChangeApproved {
+				return nil
+			}
+			return applyPriceChange(tx, change, change.ApprovedUserID)
+		})
+		if err != nil {
+			log.Println("price change", id, "failed:", err)
+			failed := db.Model(&databases.MedPriceChange{}).
+				Where("id = ?", id).
+				Where("state = ?", PriceChangeApproved).
+				Updates(map[string]interface{}{
+					"state":         PriceChangeFailed,
+					"apply_error":   err.Error(),
+					"modified_date": time.Now(),
+				})
+			if failed.Error != nil {
+				log.Println("price change", id, "mark failed:", failed.Error)
+			}
+			continue
+		}
+		applied++
+	}
+
+	return applied, nil
+}
+
+// applyPriceChange агуулахын үнийг шинэ мөрөөр солих, харилцагчийн үнийг нэмэх
+func applyPriceChange(tx *gorm.DB, change *databases.MedPriceChange, userID uint) error {
+	now := time.Now()
+
+	for _, line := range change.Lines {
+		switch line.Kind {
+		case PriceChangeWarehouse:
+			current, err := currentWarehousePrice(tx, line.WarehouseItemID, line.PriceTypeID)
+			if err != nil {
+				return err
+			}
+			line.OldPrice = RoundBase(decimal.NewFromFloat(current.SalesPrice))
+
+			result := tx.Model(&databases.MedSalesPriceDtl{}).
+				Where("warehouse_item_id = ?", line.WarehouseItemID).
+				Where("price_type_id = ?", line.PriceTypeID).
+				Where("is_active = ?", true).
+				Updates(map[string]interface{}{
+					"is_active":        false,
+					"modified_date":    now,
+					"modified_user_id": userID,
+				})
+			if result.Error != nil {
+				return result.Error
+			}
+
+			// агуулахын болон харилцагчийн үнийн хүснэгт float багана хэвээр
+			salesPrice, _ := line.NewPrice.Float64()
+			price := databases.MedSalesPriceDtl{
+				WarehouseItemID: line.WarehouseItemID,
+				PriceTypeID:     line.PriceTypeID,
+				SalesPrice:      salesPrice,
+				IsActive:        true,
+				CreatedUserID:   userID,
+				ModifiedUserID:  userID,
+				Base: databases.Base{
+					CreatedDate:  now,
+					ModifiedDate: now,
+				},
+			}
+			result = tx.Create(&price)
+			if result.Error != nil {
+				return result.Error
+			}
+			line.AppliedRecordID = price.Base.ID
+
+		case PriceChangeCustomer:
+			// батлахыг хүлээх хооронд өөр баримтаар үнэ орсон байж болно
+			err := CheckCustomerPriceOverlap(tx, line.CustomerID, line.ItemID, *line.StartDate, *line.EndDate, 0)
+			if err != nil {
+				return err
+			}
+
+			salesPrice, _ := line.NewPrice.Float64()
+			price := databases.MedPriceCustomer{
+				ItemID:         line.ItemID,
+				CustomerID:     line.CustomerID,
+				PriceTypeID:    line.PriceTypeID,
+				SalesPrice:     salesPrice,
+				IsPercent:      line.IsPercent,
+				Percent:        line.NewPercent,
+				StartDate:      *line.StartDate,
+				EndDate:        *line.EndDate,
+				IsActive:       true,
+				CreatedUserID:  userID,
+				ModifiedUserID: userID,
+				Base: databases.Base{
+					CreatedDate:  now,
+					ModifiedDate: now,
+				},
+			}
+			result := tx.Create(&price)
+			if result.Error != nil {
+				return result.Error
+			}
+			line.AppliedRecordID = price.Base.ID
+		}
+
+		result := tx.Model(line).Updates(map[string]interface{}{
+			"old_price":         line.OldPrice,
+			"applied_record_id": line.AppliedRecordID,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+	}
+
+	change.State = PriceChangeApplied
+	change.AppliedDate = &now
+	return tx.Model(change).Updates(map[string]interface{}{
+		"state":         PriceChangeApplied,
+		"applied_date":  now,
+		"modified_date": now,
+	}).Error
+}
+
+// PriceHistory хэрэгжсэн үнийн өөрчлөлтүүд, шинэ нь эхэндээ
+func PriceHistory(db *gorm.DB, query PriceHistoryQuery) ([]databases.MedPriceChangeDtl, error) {
+	db = db.
+		Joins("JOIN med_price_changes ON med_price_changes.id = med_price_change_dtls.price_change_id").
+		Where("med_price_changes.state = ?", PriceChangeApplied)
+
+	if query.WarehouseItemID != 0 {
+		db = db.Where("med_price_change_dtls.warehouse_item_id = ?", query.WarehouseItemID)
+	}
+	if query.ItemID != 0 {
+		db = db.Where("med_price_change_dtls.item_id = ?", query.ItemID)
+	}
+	if query.CustomerID != 0 {
+		db = db.Where("med_price_change_dtls.customer_id = ?", query.CustomerID)
+	}
+	if query.PriceTypeID != 0 {
+		db = db.Where("med_price_change_dtls.price_type_id = ?", query.PriceTypeID)
+	}
+
+	var lines []databases.MedPriceChangeDtl
+	result := db.
+		Preload("PriceChange.ApprovedUser.Person").
+		Preload("PriceChange.CreatedUser.Person").
+		Preload("Customer").
+		Preload("Item").
+		Preload("PriceType").
+		Order("med_price_changes.applied_date desc").
+		Order("med_price_change_dtls.id desc").
+		Find(&lines)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return lines, nil
+}
+
+// currentWarehousePrice агуулахын барааны идэвхитэй үнэ, байхгүй бол хоосон
+func currentWarehousePrice(tx *gorm.DB, warehouseItemID, priceTypeID uint) (databases.MedSalesPriceDtl, error) {
+	var price databases.MedSalesPriceDtl
+	result := tx.
+		Where("warehouse_item_id = ?", warehouseItemID).
+		Where("price_type_id = ?", priceTypeID).
+		Where("is_active = ?", true).
+		Order("id desc").
+		Limit(1).
+		Find(&price)
+	return price, result.Error
+}
+
+func lockPriceChange(tx *gorm.DB, id uint) (*databases.MedPriceChange, error) {
+	var change databases.MedPriceChange
+	result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&change, id)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	result = tx.Where("price_change_id = ?", id).Order("id asc").Find(&change.Lines)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &change, nil
+}
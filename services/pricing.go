@@ -0,0 +1,392 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+	constracts "gitlab.com/fibocloud/medtech/gin/constracts"
+	databases "gitlab.com/fibocloud/medtech/gin/databases"
+	gorm "gorm.io/gorm"
+)
+
+// Үнийн эх сурвалж
+const (
+	PriceSourceBase     = "base"     // Агуулахын суурь үнэ
+	PriceSourceRule     = "rule"     // Харилцагчийн төрөл, ангиллын дүрэм
+	PriceSourceCustomer = "customer" // Харилцагчийн тусгай үнэ
+)
+
+// DefaultPriceTypeID суурь үнийн төрөл өгөөгүй үед зарах үнийг авна
+func DefaultPriceTypeID() uint {
+	return RefID(TablePriceTypes, constracts.PriceTypeSales)
+}
+
+// Үнийн алдаанууд
+var (
+	ErrPriceDateRange = errors.New("Үнийн эхлэх огноо дуусах огнооноос өмнө байх ёстой")
+	ErrPriceOverlap   = errors.New("Энэ хугацаанд давхцах үнэ бүртгэгдсэн байна")
+	ErrPriceNotFound  = errors.New("Барааны суурь үнэ олдсонгүй")
+)
+
+// PriceQuery үнэ тооцоолох нөхцөл
+type PriceQuery struct {
+	CustomerID  uint      `json:"customer_id"`
+	ItemID      uint      `json:"item_id"`
+	PriceTypeID uint      `json:"price_type_id"`
+	Date        time.Time `json:"date"`
+	Quantity    float64   `json:"quantity"`
+}
+
+// PriceCandidate тооцоололд оролцсон үнэ, дүрэм
+type PriceCandidate struct {
+	Source     string            `json:"source"`      // base, rule, customer
+	ID         uint              `json:"id"`          // Дүрэм, тусгай үнийн ID
+	Name       string            `json:"name"`        //
+	IsPercent  bool              `json:"is_percent"`  //
+	Percent    float64           `json:"percent"`     //
+	SalesPrice databases.Decimal `json:"sales_price"` //
+	Priority   int               `json:"priority"`    //
+	Price      databases.Decimal `json:"price"`       // Хэрэгжвэл гарах үнэ
+	Applicable bool              `json:"applicable"`  // Нөхцөл таарсан эсэх
+	Reason     string            `json:"reason"`      // Таараагүй шалтгаан
+}
+
+// PriceResolution үнийн тооцооллын үр дүн, аль дүрэм хэрэгжсэнийг тайлбарлана
+type PriceResolution struct {
+	PriceQuery
+	BasePrice  databases.Decimal `json:"base_price"` //
+	Price      databases.Decimal `json:"price"`      // Эцсийн үнэ
+	Winner     *PriceCandidate   `json:"winner"`     // Хэрэгжсэн дүрэм
+	Candidates []PriceCandidate  `json:"candidates"` // Шалгасан бүх дүрэм
+}
+
+// WarehousePrice агуулахын барааны суурь үнээс тооцсон харилцагчийн үнэ
+type WarehousePrice struct {
+	WarehouseItem *databases.MedWarehouseItem `json:"warehouse_item"` //
+	BasePrice     databases.Decimal           `json:"base_price"`     //
+	Price         databases.Decimal           `json:"price"`          // Эцсийн үнэ
+	Source        string                      `json:"source"`         // base, rule, customer
+}
+
+// ResolvePrice харилцагчийн тусгай үнэ > төрөл, ангиллын дүрэм > суурь үнэ дарааллаар
+// тухайн огноо, тоо хэмжээнд хүчинтэй үнийг олно
+func ResolvePrice(db *gorm.DB, query PriceQuery) (*PriceResolution, error) {
+	query = priceQueryDefaults(query)
+
+	var customer databases.MedCustomer
+	result := db.Preload("Types").First(&customer, query.CustomerID)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	basePrice, err := BasePrice(db, query.ItemID, query.PriceTypeID)
+	if err != nil {
+		return nil, err
+	}
+
+	return resolvePriceFrom(db, query, customer, basePrice)
+}
+
+// ResolveWarehousePrices үлдэгдэлтэй агуулахын бараа бүрийн суурь үнээс ResolvePrice-ийн
+// дүрмээр харилцагчийн үнийг тооцно
+func ResolveWarehousePrices(db *gorm.DB, query PriceQuery) ([]WarehousePrice, error) {
+	query = priceQueryDefaults(query)
+
+	var customer databases.MedCustomer
+	result := db.Preload("Types").First(&customer, query.CustomerID)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	var warehousePrices []databases.MedSalesPriceDtl
+	result = db.
+		Where("warehouse_item_id IN (?)", db.Table("med_warehouse_items").Select("id").Where("item_id = ?", query.ItemID).Not("total_qty = ?", 0)).
+		Where("price_type_id = ?", query.PriceTypeID).
+		Where("is_active = ?", true).
+		Preload("WarehouseItem.Item").
+		Find(&warehousePrices)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	prices := []WarehousePrice{}
+	for _, warehousePrice := range warehousePrices {
+		resolution, err := resolvePriceFrom(db, query, customer, RoundBase(decimal.NewFromFloat(warehousePrice.SalesPrice)))
+		if err != nil {
+			return nil, err
+		}
+
+		prices = append(prices, WarehousePrice{
+			WarehouseItem: warehousePrice.WarehouseItem,
+			BasePrice:     resolution.BasePrice,
+			Price:         resolution.Price,
+			Source:        resolution.Winner.Source,
+		})
+	}
+
+	return prices, nil
+}
+
+func priceQueryDefaults(query PriceQuery) PriceQuery {
+	if query.PriceTypeID == 0 {
+		query.PriceTypeID = DefaultPriceTypeID()
+	}
+	if query.Date.IsZero() {
+		query.Date = time.Now()
+	}
+	if query.Quantity <= 0 {
+		query.Quantity = 1
+	}
+	return query
+}
+
+// resolvePriceFrom basePrice дээр харилцагчийн тусгай үнэ, дүрмүүдийг давуу эрхээр хэрэгжүүлнэ
+func resolvePriceFrom(db *gorm.DB, query PriceQuery, customer databases.MedCustomer, basePrice databases.Decimal) (*PriceResolution, error) {
+	resolution := PriceResolution{
+		PriceQuery: query,
+		BasePrice:  basePrice,
+		Price:      basePrice,
+		Candidates: []PriceCandidate{},
+	}
+
+	customerPrices, err := customerPriceCandidates(db, query, basePrice)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := priceRuleCandidates(db, query, customer, basePrice)
+	if err != nil {
+		return nil, err
+	}
+
+	resolution.Candidates = append(resolution.Candidates, customerPrices...)
+	resolution.Candidates = append(resolution.Candidates, rules...)
+	resolution.Candidates = append(resolution.Candidates, PriceCandidate{
+		Source:     PriceSourceBase,
+		Name:       "Суурь үнэ",
+		SalesPrice: basePrice,
+		Price:      basePrice,
+		Applicable: true,
+	})
+
+	// жагсаалт давуу эрхийн дарааллаар эрэмбэлэгдсэн тул эхний таарсан нь хэрэгжинэ
+	for i := range resolution.Candidates {
+		if resolution.Candidates[i].Applicable {
+			resolution.Winner = &resolution.Candidates[i]
+			resolution.Price = resolution.Candidates[i].Price
+			break
+		}
+	}
+
+	return &resolution, nil
+}
+
+// BasePrice үлдэгдэлтэй агуулахын бараанаас хамгийн сүүлд тогтоосон үнэ
+func BasePrice(db *gorm.DB, itemID, priceTypeID uint) (databases.Decimal, error) {
+	var price databases.MedSalesPriceDtl
+	result := db.
+		Where("warehouse_item_id IN (?)", db.Table("med_warehouse_items").Select("id").Where("item_id = ?", itemID).Not("total_qty = ?", 0)).
+		Where("price_type_id = ?", priceTypeID).
+		Where("is_active = ?", true).
+		Order("id desc").
+		Limit(1).
+		Find(&price)
+	if result.Error != nil {
+		return databases.Decimal{}, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return databases.Decimal{}, ErrPriceNotFound
+	}
+
+	return RoundBase(decimal.NewFromFloat(price.SalesPrice)), nil
+}
+
+// customerPriceCandidates харилцагчийн тухайн барааны тусгай үнүүд, шинэ нь эхэндээ
+func customerPriceCandidates(db *gorm.DB, query PriceQuery, basePrice databases.Decimal) ([]PriceCandidate, error) {
+	var prices []databases.MedPriceCustomer
+	result := db.
+		Where("customer_id = ?", query.CustomerID).
+		Where("item_id = ?", query.ItemID).
+		Where("is_active = ?", true).
+		Order("start_date desc").
+		Find(&prices)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	var candidates []PriceCandidate
+	for _, price := range prices {
+		salesPrice := RoundBase(decimal.NewFromFloat(price.SalesPrice))
+		candidate := PriceCandidate{
+			Source:     PriceSourceCustomer,
+			ID:         price.Base.ID,
+			Name:       "Харилцагчийн тусгай үнэ",
+			IsPercent:  price.IsPercent,
+			Percent:    price.Percent,
+			SalesPrice: salesPrice,
+			Price:      applyPrice(basePrice, price.IsPercent, price.Percent, salesPrice),
+		}
+		candidate.Reason = dateReason(query.Date, price.StartDate, price.EndDate)
+		candidate.Applicable = candidate.Reason == ""
+
+		candidates = append(candidates, candidate)
+	}
+
+	return candidates, nil
+}
+
+// priceRuleCandidates барааны болон бүх барааны дүрмүүд давуу эрхийн дарааллаар
+func priceRuleCandidates(db *gorm.DB, query PriceQuery, customer databases.MedCustomer, basePrice databases.Decimal) ([]PriceCandidate, error) {
+	var rules []databases.MedPriceRule
+	result := db.
+		Where("item_id IN (?)", []uint{query.ItemID, 0}).
+		Where("is_active = ?", true).
+		Find(&rules)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	sort.SliceStable(rules, func(i, j int) bool {
+		return priceRuleLess(rules[i], rules[j])
+	})
+
+	typeIDs := map[uint]bool{}
+	for _, customerType := range customer.Types {
+		typeIDs[customerType.Base.ID] = true
+	}
+
+	var candidates []PriceCandidate
+	for _, rule := range rules {
+		candidate := PriceCandidate{
+			Source:     PriceSourceRule,
+			ID:         rule.Base.ID,
+			Name:       rule.Name,
+			IsPercent:  rule.IsPercent,
+			Percent:    rule.Percent,
+			SalesPrice: rule.SalesPrice,
+			Priority:   rule.Priority,
+			Price:      applyPrice(basePrice, rule.IsPercent, rule.Percent, rule.SalesPrice),
+		}
+
+		switch {
+		case rule.CustomerTypeID != 0 && !typeIDs[rule.CustomerTypeID]:
+			candidate.Reason = "Харилцагчийн төрөл таарахгүй"
+		case rule.ClassificationID != 0 && rule.ClassificationID != customer.ClassificationID:
+			candidate.Reason = "Харилцагчийн ангилал таарахгүй"
+		case rule.PriceTypeID != 0 && rule.PriceTypeID != query.PriceTypeID:
+			candidate.Reason = "Үнийн төрөл таарахгүй"
+		case query.Quantity < rule.MinQuantity:
+			candidate.Reason = fmt.Sprintf("Тоо хэмжээ %.2f-аас бага", rule.MinQuantity)
+		default:
+			candidate.Reason = dateReason(query.Date, rule.StartDate, rule.EndDate)
+		}
+		candidate.Applicable = candidate.Reason == ""
+
+		candidates = append(candidates, candidate)
+	}
+
+	return candidates, nil
+}
+
+// priceRuleLess өндөр ач холбогдолтой, илүү нарийвчилсан дүрэм түрүүлнэ
+func priceRuleLess(a, b databases.MedPriceRule) bool {
+	if a.Priority != b.Priority {
+		return a.Priority > b.Priority
+	}
+	if (a.ItemID != 0) != (b.ItemID != 0) {
+		return a.ItemID != 0
+	}
+	if ruleSpecificity(a) != ruleSpecificity(b) {
+		return ruleSpecificity(a) > ruleSpecificity(b)
+	}
+	return a.MinQuantity > b.MinQuantity
+}
+
+func ruleSpecificity(rule databases.MedPriceRule) int {
+	specificity := 0
+	if rule.ClassificationID != 0 {
+		specificity++
+	}
+	if rule.CustomerTypeID != 0 {
+		specificity++
+	}
+	return specificity
+}
+
+func dateReason(date, start, end time.Time) string {
+	if date.Before(start) || date.After(end) {
+		return fmt.Sprintf("Хүчинтэй хугацаа %s - %s", start.Format("2006-01-02"), end.Format("2006-01-02"))
+	}
+	return ""
+}
+
+// applyPrice хувь бол суурь үнэд нэмнэ (хөнгөлөлт бол хасах хувь), үгүй бол тогтмол үнэ.
+// Хувиар тооцсон үнийг үндсэн валютын бүхэлчлэлээр бүхэлчилнэ.
+func applyPrice(basePrice databases.Decimal, isPercent bool, percent float64, salesPrice databases.Decimal) databases.Decimal {
+	if !isPercent {
+		return salesPrice
+	}
+	factor := decimal.NewFromInt(100).Add(decimal.NewFromFloat(percent)).Div(decimal.NewFromInt(100))
+	return RoundBase(basePrice.Mul(factor))
+}
+
+// CheckCustomerPriceOverlap харилцагч, барааны хүчинтэй тусгай үнэтэй хугацаа давхцах эсэх
+func CheckCustomerPriceOverlap(db *gorm.DB, customerID, itemID uint, start, end time.Time, excludeID uint) error {
+	if !start.Before(end) {
+		return ErrPriceDateRange
+	}
+
+	var existing databases.MedPriceCustomer
+	result := db.
+		Where("customer_id = ?", customerID).
+		Where("item_id = ?", itemID).
+		Where("is_active = ?", true).
+		Where("start_date <= ?", end).
+		Where("end_date >= ?", start).
+		Where("id <> ?", excludeID).
+		Limit(1).
+		Find(&existing)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		return fmt.Errorf("%w: %s - %s", ErrPriceOverlap, existing.StartDate.Format("2006-01-02"), existing.EndDate.Format("2006-01-02"))
+	}
+
+	return nil
+}
+
+// CheckPriceRuleOverlap ижил нөхцөлтэй идэвхитэй дүрэмтэй хугацаа давхцах эсэх
+func CheckPriceRuleOverlap(db *gorm.DB, rule databases.MedPriceRule) error {
+	if !rule.StartDate.Before(rule.EndDate) {
+		return ErrPriceDateRange
+	}
+	if !rule.IsActive {
+		return nil
+	}
+
+	var existing databases.MedPriceRule
+	result := db.
+		Where("customer_type_id = ?", rule.CustomerTypeID).
+		Where("classification_id = ?", rule.ClassificationID).
+		Where("item_id = ?", rule.ItemID).
+		Where("price_type_id = ?", rule.PriceTypeID).
+		Where("min_quantity = ?", rule.MinQuantity).
+		Where("is_active = ?", true).
+		Where("start_date <= ?", rule.EndDate).
+		Where("end_date >= ?", rule.StartDate).
+		Where("id <> ?", rule.Base.ID).
+		Limit(1).
+		Find(&existing)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		return fmt.Errorf("%w: %s", ErrPriceOverlap, existing.Name)
+	}
+
+	return nil
+}
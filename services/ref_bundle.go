@@ -0,0 +1,539 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	viper "github.com/spf13/viper"
+	yaml "gopkg.in/yaml.v2"
+	gorm "gorm.io/gorm"
+)
+
+// Лавлахын багцын алдаанууд
+var (
+	ErrBundleFormat  = errors.New("Багцын формат json эсвэл yaml байна")
+	ErrBundleVersion = errors.New("Багцын хувилбар таарахгүй байна")
+	ErrBundleParent  = errors.New("Эцэг бичлэг олдсонгүй")
+	ErrBundleRight   = errors.New("Лавлахын багц оруулах эрхгүй байна")
+)
+
+// RefBundleVersion багцын форматын хувилбар
+const RefBundleVersion = 1
+
+// RefBundleImportPermission лавлахын багц оруулах эрхийн код
+func RefBundleImportPermission() string {
+	if code := viper.GetString("refBundle.importPermission"); code != "" {
+		return code
+	}
+	return "ref_bundle_import"
+}
+
+// refBundleKeySeparator эцгийн түлхүүр ба нэрийн тусгаарлагч
+const refBundleKeySeparator = "/"
+
+// RefBundle орчин хооронд зөөх лавлахууд. Бичлэгийг ID-гаар биш нэрээр нь таньдаг.
+type RefBundle struct {
+	Version      int                 `json:"version" yaml:"version"`
+	ExportedDate time.Time           `json:"exported_date" yaml:"exported_date"`
+	Tables       []RefBundleTable    `json:"tables" yaml:"tables"`
+	Constants    []RefBundleConstant `json:"constants" yaml:"constants"` // Системийн код аль бичлэгт байгаа
+}
+
+// RefBundleTable нэг хүснэгтийн бичлэгүүд
+type RefBundleTable struct {
+	Table string         `json:"table" yaml:"table"`
+	Rows  []RefBundleRow `json:"rows" yaml:"rows"`
+}
+
+// RefBundleRow нэг бичлэг
+type RefBundleRow struct {
+	Key    string                 `json:"key" yaml:"key"`                           // Байгалийн түлхүүр: эцгийн түлхүүр/нэр
+	Parent string                 `json:"parent,omitempty" yaml:"parent,omitempty"` // Эцгийн түлхүүр
+	Fields map[string]interface{} `json:"fields" yaml:"fields"`                     // name болон бусад багана
+}
+
+// RefBundleConstant RefCodes-ын код ба түүнийг агуулсан бичлэгийн түлхүүр
+type RefBundleConstant struct {
+	Table string `json:"table" yaml:"table"`
+	Code  string `json:"code" yaml:"code"`
+	Key   string `json:"key" yaml:"key"`
+}
+
+// RefBundleReport импортын үр дүн, dry-run үед хийгдэх байсан өөрчлөлт
+type RefBundleReport struct {
+	DryRun    bool                      `json:"dry_run"`
+	Tables    []RefBundleTableDiff      `json:"tables"`
+	Constants []RefBundleConstantResult `json:"constants"`
+}
+
+// RefBundleTableDiff нэг хүснэгтийн зөрүү
+type RefBundleTableDiff struct {
+	Table     string            `json:"table"`
+	Skipped   bool              `json:"skipped"` // Энэ орчинд хүснэгт байхгүй
+	Created   []string          `json:"created"`
+	Updated   []RefBundleChange `json:"updated"`
+	Unchanged int               `json:"unchanged"`
+	Extra     []string          `json:"extra"` // Энэ орчинд байгаа ч багцад байхгүй, устгахгүй
+}
+
+// RefBundleChange өөрчлөгдөх бичлэг ба баганууд
+type RefBundleChange struct {
+	Key    string                    `json:"key"`
+	Fields map[string][2]interface{} `json:"fields"` // багана -> [одоогийн, шинэ]
+}
+
+// RefBundleConstantResult импортын дараах системийн кодын байдал
+type RefBundleConstantResult struct {
+	Table   string `json:"table"`
+	Code    string `json:"code"`
+	Key     string `json:"key"`
+	ID      uint   `json:"id"`      // Энэ орчинд харгалзах ID
+	Missing bool   `json:"missing"` // Код олдоогүй, сервер асахгүй
+}
+
+// refBundleSpec багцад орох хүснэгт. Эцэгтэй бол эцэг нь өмнө нь байх ёстой.
+// parentTable нь өөрөө бол түлхүүр нь зөвхөн нэр, эцгийг сүүлд холбоно.
+type refBundleSpec struct {
+	table        string
+	fields       []string // name-ээс бусад хуулах баганууд
+	parentTable  string
+	parentColumn string
+}
+
+var refBundleSpecs = []refBundleSpec{
+	{table: "med_status_types", fields: []string{"code", "description", "is_active"}},
+	{table: "med_statuses", fields: []string{"code", "description", "is_active", "color_code"}, parentTable: "med_status_types", parentColumn: "status_type_id"},
+	{table: "med_payment_types", fields: []string{"description", "is_active", "payment_day", "prepaid_percent", "payment_condition"}},
+	{table: "med_payment_methods", fields: []string{"description", "is_active"}},
+	{table: "med_address_types", fields: []string{"code", "is_active"}},
+	{table: "med_content_types", fields: []string{"code", "is_active"}, parentTable: "med_content_types", parentColumn: "parent_id"},
+	{table: "med_measures", fields: []string{"description", "code", "is_active"}},
+	{table: "med_specifications", fields: []string{"description", "is_active"}},
+	{table: "med_customer_classifications", fields: []string{"code", "description", "is_active", "rank", "min_purchase", "min_order_count", "max_avg_pay_days", "is_auto_assign"}},
+	{table: "ref_valutes", fields: []string{"description", "is_active", "symbol"}},
+}
+
+// refBundleRecord хүснэгтэд байгаа бичлэг
+type refBundleRecord struct {
+	id     uint
+	key    string
+	parent uint
+	values map[string]interface{}
+}
+
+// ExportRefBundle лавлахуудыг багц болгоно. Энэ орчинд байхгүй хүснэгтийг алгасна.
+func ExportRefBundle(db *gorm.DB) (*RefBundle, error) {
+	bundle := RefBundle{
+		Version:      RefBundleVersion,
+		ExportedDate: time.Now(),
+		Tables:       []RefBundleTable{},
+		Constants:    []RefBundleConstant{},
+	}
+
+	keys := map[string]map[uint]string{}
+	codes := map[string]map[string]string{}
+	for _, spec := range refBundleSpecs {
+		if !db.Migrator().HasTable(spec.table) {
+			continue
+		}
+
+		records, err := loadRefBundleRecords(db, spec, keys)
+		if err != nil {
+			return nil, err
+		}
+
+		keys[spec.table] = map[uint]string{}
+		codes[spec.table] = map[string]string{}
+		for _, record := range records {
+			keys[spec.table][record.id] = record.key
+			if code, ok := record.values["code"].(string); ok && code != "" {
+				codes[spec.table][code] = record.key
+			}
+		}
+
+		table := RefBundleTable{Table: spec.table, Rows: []RefBundleRow{}}
+		for _, record := range records {
+			row := RefBundleRow{Key: record.key, Fields: record.values}
+			if record.parent != 0 {
+				row.Parent = keys[spec.parentTable][record.parent]
+			}
+			table.Rows = append(table.Rows, row)
+		}
+		bundle.Tables = append(bundle.Tables, table)
+	}
+
+	for _, refCode := range RefCodes() {
+		if key, ok := codes[refCode.Table][refCode.Code]; ok {
+			bundle.Constants = append(bundle.Constants, RefBundleConstant{Table: refCode.Table, Code: refCode.Code, Key: key})
+		}
+	}
+
+	return &bundle, nil
+}
+
+// ImportRefBundle багцын бичлэгүүдийг түлхүүрээр нь тааруулж нэмж, засна. Багцад
+// байхгүй бичлэгийг устгахгүй, зөвхөн тайланд Extra болгоно.
+func ImportRefBundle(tx *gorm.DB, bundle *RefBundle, userID uint) (*RefBundleReport, error) {
+	if bundle.Version != RefBundleVersion {
+		return nil, fmt.Errorf("%w: %d", ErrBundleVersion, bundle.Version)
+	}
+
+	byTable := map[string]RefBundleTable{}
+	for _, table := range bundle.Tables {
+		byTable[table.Table] = table
+	}
+
+	report := RefBundleReport{Tables: []RefBundleTableDiff{}, Constants: []RefBundleConstantResult{}}
+	ids := map[string]map[string]uint{}
+	now := time.Now()
+
+	for _, spec := range refBundleSpecs {
+		table, ok := byTable[spec.table]
+		if !ok {
+			continue
+		}
+
+		diff := RefBundleTableDiff{
+			Table:   spec.table,
+			Created: []string{},
+			Updated: []RefBundleChange{},
+			Extra:   []string{},
+		}
+		if !tx.Migrator().HasTable(spec.table) {
+			diff.Skipped = true
+			report.Tables = append(report.Tables, diff)
+			continue
+		}
+
+		keys := map[string]map[uint]string{}
+		for parentTable, parentIDs := range ids {
+			keys[parentTable] = map[uint]string{}
+			for key, id := range parentIDs {
+				keys[parentTable][id] = key
+			}
+		}
+
+		records, err := loadRefBundleRecords(tx, spec, keys)
+		if err != nil {
+			return nil, err
+		}
+
+		existing := map[string]refBundleRecord{}
+		ids[spec.table] = map[string]uint{}
+		for _, record := range records {
+			existing[record.key] = record
+			ids[spec.table][record.key] = record.id
+		}
+
+		// код өөр түлхүүртэй бичлэгт байвал багцын бичлэг рүү шилжүүлнэ
+		for _, row := range table.Rows {
+			code, _ := row.Fields["code"].(string)
+			if code == "" {
+				continue
+			}
+			for _, record := range records {
+				if record.key == row.Key || record.values["code"] != code {
+					continue
+				}
+				if err := tx.Table(spec.table).Where("id = ?", record.id).Update("code", "").Error; err != nil {
+					return nil, err
+				}
+				record.values["code"] = ""
+				diff.Updated = append(diff.Updated, RefBundleChange{
+					Key:    record.key,
+					Fields: map[string][2]interface{}{"code": {code, ""}},
+				})
+			}
+		}
+
+		selfParent := spec.parentTable == spec.table
+		seen := map[string]bool{}
+		for _, row := range table.Rows {
+			seen[row.Key] = true
+
+			values := map[string]interface{}{}
+			for _, column := range append([]string{"name"}, spec.fields...) {
+				if value, ok := row.Fields[column]; ok {
+					values[column] = value
+				}
+			}
+
+			if spec.parentTable != "" && !selfParent {
+				parentID, ok := ids[spec.parentTable][row.Parent]
+				if !ok {
+					return nil, fmt.Errorf("%w: %s %s", ErrBundleParent, spec.table, row.Key)
+				}
+				values[spec.parentColumn] = parentID
+			}
+
+			record, ok := existing[row.Key]
+			if !ok {
+				values["created_user_id"] = userID
+				values["modified_user_id"] = userID
+				values["created_date"] = now
+				values["modified_date"] = now
+				if err := tx.Table(spec.table).Create(values).Error; err != nil {
+					return nil, fmt.Errorf("%s %s: %w", spec.table, row.Key, err)
+				}
+
+				var id uint
+				result := tx.Table(spec.table).Select("id").Where(refBundleKeyWhere(spec, values)).Order("id desc").Limit(1).Scan(&id)
+				if result.Error != nil {
+					return nil, result.Error
+				}
+				ids[spec.table][row.Key] = id
+				diff.Created = append(diff.Created, row.Key)
+				continue
+			}
+
+			change := RefBundleChange{Key: row.Key, Fields: map[string][2]interface{}{}}
+			updates := map[string]interface{}{}
+			for column, value := range values {
+				if column == spec.parentColumn {
+					if uint(toFloat(value)) != record.parent {
+						change.Fields[column] = [2]interface{}{record.parent, value}
+						updates[column] = value
+					}
+					continue
+				}
+				if !sameBundleValue(record.values[column], value) {
+					change.Fields[column] = [2]interface{}{record.values[column], value}
+					updates[column] = value
+				}
+			}
+
+			if len(updates) == 0 {
+				diff.Unchanged++
+				continue
+			}
+
+			updates["modified_user_id"] = userID
+			updates["modified_date"] = now
+			if err := tx.Table(spec.table).Where("id = ?", record.id).Updates(updates).Error; err != nil {
+				return nil, fmt.Errorf("%s %s: %w", spec.table, row.Key, err)
+			}
+			diff.Updated = append(diff.Updated, change)
+		}
+
+		// өөрийгөө заасан эцгийг бүх бичлэг үүссэний дараа холбоно
+		if selfParent {
+			for _, row := range table.Rows {
+				var parentID uint
+				if row.Parent != "" {
+					id, ok := ids[spec.table][row.Parent]
+					if !ok {
+						return nil, fmt.Errorf("%w: %s %s", ErrBundleParent, spec.table, row.Key)
+					}
+					parentID = id
+				}
+				record, ok := existing[row.Key]
+				if ok && record.parent == parentID || !ok && parentID == 0 {
+					continue
+				}
+				result := tx.Table(spec.table).Where("id = ?", ids[spec.table][row.Key]).Update(spec.parentColumn, parentID)
+				if result.Error != nil {
+					return nil, result.Error
+				}
+				if ok {
+					diff.Updated = append(diff.Updated, RefBundleChange{
+						Key:    row.Key,
+						Fields: map[string][2]interface{}{spec.parentColumn: {record.parent, parentID}},
+					})
+				}
+			}
+		}
+
+		for _, record := range records {
+			if !seen[record.key] {
+				diff.Extra = append(diff.Extra, record.key)
+			}
+		}
+
+		report.Tables = append(report.Tables, diff)
+	}
+
+	for _, refCode := range RefCodes() {
+		if _, ok := byTable[refCode.Table]; !ok {
+			continue
+		}
+
+		result := RefBundleConstantResult{Table: refCode.Table, Code: refCode.Code}
+		if tx.Migrator().HasTable(refCode.Table) {
+			var codeIDs []uint
+			if err := tx.Table(refCode.Table).Where("code = ?", refCode.Code).Pluck("id", &codeIDs).Error; err != nil {
+				return nil, err
+			}
+			if len(codeIDs) > 0 {
+				result.ID = codeIDs[0]
+			}
+		}
+		for key, id := range ids[refCode.Table] {
+			if result.ID != 0 && id == result.ID {
+				result.Key = key
+			}
+		}
+		result.Missing = result.ID == 0
+		report.Constants = append(report.Constants, result)
+	}
+
+	return &report, nil
+}
+
+// loadRefBundleRecords хүснэгтийн бичлэгүүдийг түлхүүртэй нь уншина. keys-д эцгийн
+// хүснэгтийн ID -> түлхүүр байх ёстой.
+func loadRefBundleRecords(db *gorm.DB, spec refBundleSpec, keys map[string]map[uint]string) ([]refBundleRecord, error) {
+	columns := append([]string{"id", "name"}, spec.fields...)
+	if spec.parentColumn != "" {
+		columns = append(columns, spec.parentColumn)
+	}
+
+	var rows []map[string]interface{}
+	if err := db.Table(spec.table).Select(columns).Order("id").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	records := []refBundleRecord{}
+	for _, row := range rows {
+		record := refBundleRecord{
+			id:     uint(toFloat(row["id"])),
+			values: map[string]interface{}{},
+		}
+		for _, column := range append([]string{"name"}, spec.fields...) {
+			record.values[column] = row[column]
+		}
+
+		name := fmt.Sprint(row["name"])
+		record.key = name
+		if spec.parentColumn != "" {
+			record.parent = uint(toFloat(row[spec.parentColumn]))
+			if spec.parentTable != spec.table {
+				record.key = keys[spec.parentTable][record.parent] + refBundleKeySeparator + name
+			}
+		}
+		records = append(records, record)
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].key < records[j].key
+	})
+	return records, nil
+}
+
+// refBundleKeyWhere шинээр үүсгэсэн бичлэгийг нэр, эцгээр нь олох нөхцөл
+func refBundleKeyWhere(spec refBundleSpec, values map[string]interface{}) map[string]interface{} {
+	where := map[string]interface{}{"name": values["name"]}
+	if spec.parentColumn != "" && spec.parentTable != spec.table {
+		where[spec.parentColumn] = values[spec.parentColumn]
+	}
+	return where
+}
+
+// sameBundleValue json, yaml-аас уншсан утгыг өгөгдлийн сангийнхтай харьцуулна
+func sameBundleValue(current, value interface{}) bool {
+	if current == nil {
+		current = ""
+	}
+	if value == nil {
+		value = ""
+	}
+	if isNumber(current) || isNumber(value) {
+		return toFloat(current) == toFloat(value)
+	}
+	return fmt.Sprint(current) == fmt.Sprint(value)
+}
+
+func isNumber(value interface{}) bool {
+	switch value.(type) {
+	case int, int32, int64, uint, uint32, uint64, float32, float64:
+		return true
+	}
+	return false
+}
+
+func toFloat(value interface{}) float64 {
+	switch v := value.(type) {
+	case int:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint:
+		return float64(v)
+	case uint32:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	case float32:
+		return float64(v)
+	case float64:
+		return v
+	case string:
+		f, _ := strconv.ParseFloat(v, 64)
+		return f
+	case []byte:
+		f, _ := strconv.ParseFloat(string(v), 64)
+		return f
+	}
+	return 0
+}
+
+// ReadRefBundle json эсвэл yaml багц уншина. format хоосон бол json.
+func ReadRefBundle(reader io.Reader, format string) (*RefBundle, error) {
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	var bundle RefBundle
+	switch RefBundleFormat(format) {
+	case "json":
+		err = json.Unmarshal(data, &bundle)
+	case "yaml":
+		err = yaml.Unmarshal(data, &bundle)
+	default:
+		return nil, ErrBundleFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &bundle, nil
+}
+
+// WriteRefBundle багцыг json эсвэл yaml болгож бичнэ
+func WriteRefBundle(writer io.Writer, bundle *RefBundle, format string) error {
+	var data []byte
+	var err error
+	switch RefBundleFormat(format) {
+	case "json":
+		data, err = json.MarshalIndent(bundle, "", "  ")
+	case "yaml":
+		data, err = yaml.Marshal(bundle)
+	default:
+		return ErrBundleFormat
+	}
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(data)
+	return err
+}
+
+// RefBundleFormat формат эсвэл файлын нэрээс json, yaml-ын аль нь болохыг гаргана
+func RefBundleFormat(format string) string {
+	format = strings.ToLower(strings.TrimSpace(format))
+	switch {
+	case format == "" || format == "json" || strings.HasSuffix(format, ".json"):
+		return "json"
+	case format == "yaml" || format == "yml" || strings.HasSuffix(format, ".yaml") || strings.HasSuffix(format, ".yml"):
+		return "yaml"
+	}
+	return format
+}
@@ -0,0 +1,267 @@
+package services
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	viper "github.com/spf13/viper"
+	databases "gitlab.com/fibocloud/medtech/gin/databases"
+	gorm "gorm.io/gorm"
+	clause "gorm.io/gorm/clause"
+)
+
+// CachedRefTables бичигдэх бүрт кэшийг нь хүчингүй болгох хүснэгтүүд
+var CachedRefTables = map[string]bool{
+	"ref_countries":                true,
+	"ref_cities":                   true,
+	"ref_districts":                true,
+	"ref_streets":                  true,
+	"ref_valutes":                  true,
+	"med_statuses":                 true,
+	"med_status_types":             true,
+	"med_payment_types":            true,
+	"med_payment_methods":          true,
+	"med_address_types":            true,
+	"med_customers":                true,
+	"med_customer_types":           true,
+	"med_customer_classifications": true,
+	"med_translations":             true,
+}
+
+// CacheBackend кэшийн хадгалалт. ttl 0 бол хугацаагүй.
+type CacheBackend interface {
+	Name() string
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte, ttl time.Duration)
+}
+
+// RefCache лавлахын кэш. Хүснэгт бүр хувилбартай бөгөөд бичих үед хувилбар нь
+// солигдоно. Кэшийн түлхүүр, ETag хоёул хамаарах хүснэгтүүдийн хувилбараас гарна.
+type RefCache struct {
+	Backend CacheBackend
+	TTL     time.Duration
+	Enabled bool
+}
+
+// refCache InitRefCache дуудаагүй үед санах ойн кэш
+var refCache = &RefCache{Backend: NewMemoryCacheBackend(), TTL: 10 * time.Minute, Enabled: true}
+
+// InitRefCache тохиргооноос кэш үүсгээд gorm-ын бичих үйлдэл бүрт хүчингүй болгоно
+func InitRefCache(db *gorm.DB) {
+	var backend CacheBackend
+	switch viper.GetString("refCache.backend") {
+	case "database":
+		backend = &DatabaseCacheBackend{DB: db}
+	default:
+		backend = NewMemoryCacheBackend()
+	}
+
+	ttl := time.Duration(viper.GetInt("refCache.ttl")) * time.Second
+	if ttl <= 0 {
+		ttl = 10 * time.Minute
+	}
+
+	enabled := true
+	if viper.IsSet("refCache.enabled") {
+		enabled = viper.GetBool("refCache.enabled")
+	}
+
+	refCache = &RefCache{Backend: backend, TTL: ttl, Enabled: enabled}
+	RegisterRefCacheInvalidation(db)
+}
+
+// GetRefCache идэвхитэй кэш
+func GetRefCache() *RefCache {
+	return refCache
+}
+
+// RegisterRefCacheInvalidation CachedRefTables-д бичсэн create, update, delete бүрийн
+// дараа тухайн хүснэгтийн кэшийг хүчингүй болгоно. Гүйлгээ дотор бичсэн бол commit
+// хийгдэхээс өмнө уншсан хуучин утга TTL дуустал үлдэж болно.
+func RegisterRefCacheInvalidation(db *gorm.DB) {
+	invalidate := func(db *gorm.DB) {
+		if db.Error != nil || db.Statement.Table == "" {
+			return
+		}
+		if CachedRefTables[db.Statement.Table] {
+			refCache.Invalidate(db.Statement.Table)
+		}
+	}
+
+	db.Callback().Create().After("gorm:create").Register("ref_cache:create", invalidate)
+	db.Callback().Update().After("gorm:update").Register("ref_cache:update", invalidate)
+	db.Callback().Delete().After("gorm:delete").Register("ref_cache:delete", invalidate)
+}
+
+// Invalidate хүснэгтүүдийн хувилбарыг солино
+func (rc *RefCache) Invalidate(tables ...string) {
+	version := strconv.FormatInt(time.Now().UnixNano(), 36)
+	for _, table := range tables {
+		rc.Backend.Set(refVersionKey(table), []byte(version), 0)
+	}
+}
+
+// ETag key болон хүснэгтүүдийн одоогийн хувилбараас ETag гаргана
+func (rc *RefCache) ETag(key string, tables []string) string {
+	sorted := append([]string{}, tables...)
+	sort.Strings(sorted)
+
+	hash := sha1.New()
+	hash.Write([]byte(key))
+	for _, table := range sorted {
+		hash.Write([]byte("|" + table + "=" + rc.version(table)))
+	}
+	return `"` + hex.EncodeToString(hash.Sum(nil))[:20] + `"`
+}
+
+// Load etag-д харгалзах JSON-г кэшээс, байхгүй бол load-аар уншиж хадгална.
+// Кэш унтраалттай бол шууд load дуудна.
+func (rc *RefCache) Load(etag string, load func() ([]byte, error)) ([]byte, error) {
+	if !rc.Enabled {
+		return load()
+	}
+
+	key := "ref:data:" + etag
+	if value, ok := rc.Backend.Get(key); ok {
+		return value, nil
+	}
+
+	value, err := load()
+	if err != nil {
+		return nil, err
+	}
+	rc.Backend.Set(key, value, rc.TTL)
+	return value, nil
+}
+
+// version хүснэгтийн хувилбар, анх удаа бол шинээр үүсгэнэ
+func (rc *RefCache) version(table string) string {
+	if version, ok := rc.Backend.Get(refVersionKey(table)); ok {
+		return string(version)
+	}
+	rc.Invalidate(table)
+	if version, ok := rc.Backend.Get(refVersionKey(table)); ok {
+		return string(version)
+	}
+	return ""
+}
+
+func refVersionKey(table string) string {
+	return "ref:version:" + strings.ToLower(table)
+}
+
+// MemoryCacheBackend нэг процессын санах ойн кэш
+type MemoryCacheBackend struct {
+	mu        sync.RWMutex
+	entries   map[string]memoryCacheEntry
+	lastSweep time.Time
+}
+
+type memoryCacheEntry struct {
+	value  []byte
+	expire time.Time // zero бол хугацаагүй
+}
+
+// NewMemoryCacheBackend хоосон санах ойн кэш
+func NewMemoryCacheBackend() *MemoryCacheBackend {
+	return &MemoryCacheBackend{entries: map[string]memoryCacheEntry{}, lastSweep: time.Now()}
+}
+
+// Name backend нэр
+func (m *MemoryCacheBackend) Name() string {
+	return "memory"
+}
+
+// Get хугацаа нь дуусаагүй утга
+func (m *MemoryCacheBackend) Get(key string) ([]byte, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	entry, ok := m.entries[key]
+	if !ok || (!entry.expire.IsZero() && time.Now().After(entry.expire)) {
+		return nil, false
+	}
+	return entry.value, true
+}
+
+// Set утга хадгална. Минут тутамд хугацаа дууссаныг цэвэрлэнэ.
+func (m *MemoryCacheBackend) Set(key string, value []byte, ttl time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	entry := memoryCacheEntry{value: value}
+	if ttl > 0 {
+		entry.expire = now.Add(ttl)
+	}
+	m.entries[key] = entry
+
+	if now.Sub(m.lastSweep) < time.Minute {
+		return
+	}
+	for key, entry := range m.entries {
+		if !entry.expire.IsZero() && now.After(entry.expire) {
+			delete(m.entries, key)
+		}
+	}
+	m.lastSweep = now
+}
+
+// DatabaseCacheBackend med_cache_entries хүснэгтэд хадгалах тул хувилбар болон утга нь
+// бүх instance-д хуваалцагдана. Алдаа гарвал кэшгүй ажиллана.
+type DatabaseCacheBackend struct {
+	DB *gorm.DB
+}
+
+// Name backend нэр
+func (d *DatabaseCacheBackend) Name() string {
+	return "database"
+}
+
+// Get хугацаа нь дуусаагүй утга
+func (d *DatabaseCacheBackend) Get(key string) ([]byte, bool) {
+	var entry databases.MedCacheEntry
+	result := d.DB.
+		Where("key = ? AND (expire_date IS NULL OR expire_date > ?)", key, time.Now()).
+		Limit(1).
+		Find(&entry)
+	if result.Error != nil {
+		log.Println("ref cache get failed:", result.Error)
+		return nil, false
+	}
+	return entry.Value, result.RowsAffected > 0
+}
+
+// Set утга upsert хийж хугацаа нь дууссаныг устгана
+func (d *DatabaseCacheBackend) Set(key string, value []byte, ttl time.Duration) {
+	now := time.Now()
+	entry := databases.MedCacheEntry{
+		Key:   key,
+		Value: value,
+		Base: databases.Base{
+			CreatedDate:  now,
+			ModifiedDate: now,
+		},
+	}
+	if ttl > 0 {
+		expire := now.Add(ttl)
+		entry.ExpireDate = &expire
+	}
+
+	result := d.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"value", "expire_date", "modified_date"}),
+	}).Create(&entry)
+	if result.Error != nil {
+		log.Println("ref cache set failed:", result.Error)
+		return
+	}
+
+	d.DB.Where("expire_date < ?", now).Delete(&databases.MedCacheEntry{})
+}
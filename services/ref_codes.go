@@ -0,0 +1,259 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	viper "github.com/spf13/viper"
+	constracts "gitlab.com/fibocloud/medtech/gin/constracts"
+	gorm "gorm.io/gorm"
+)
+
+// Лавлахын кодын алдаанууд
+var (
+	ErrRefCodeMissing  = errors.New("Лавлахын код бүртгэгдээгүй байна")
+	ErrRefCodeTaken    = errors.New("Энэ кодтой бичлэг бүртгэлтэй байна")
+	ErrRefCodeRequired = errors.New("Системд ашиглагддаг кодтой бичлэгийг устгах, кодыг нь өөрчлөх боломжгүй")
+)
+
+// Кодтой лавлахын хүснэгтүүд
+const (
+	TableCountries       = "ref_countries"
+	TableStatusTypes     = "med_status_types"
+	TableStatuses        = "med_statuses"
+	TableAddressTypes    = "med_address_types"
+	TableContentTypes    = "med_content_types"
+	TableClassifications = "med_customer_classifications"
+	TablePriceTypes      = "med_price_types"
+)
+
+// RefCode системд ашиглагддаг, кодоор нь ID-г олох лавлахын бичлэг
+type RefCode struct {
+	Table    string
+	Code     string
+	LegacyID uint // Код нэвтрэхээс өмнө хатуу бичигдсэн ID, зөвхөн SeedLegacyRefCodes ашиглана
+}
+
+// RefCodes эхлэхэд заавал байх ёстой кодууд
+func RefCodes() []RefCode {
+	return []RefCode{
+		{Table: TableCountries, Code: constracts.MongoliaCountry, LegacyID: 67},
+		{Table: TableStatusTypes, Code: constracts.StatusTypeCustomer, LegacyID: 3},
+		{Table: TableStatuses, Code: constracts.StatusCustomerAccountConfirmed, LegacyID: 12},
+		{Table: TableStatuses, Code: constracts.StatusCustomerPermissionCreated, LegacyID: 13},
+		{Table: TableStatuses, Code: constracts.StatusOrderReturned},
+		{Table: TableContentTypes, Code: constracts.ContentLicence, LegacyID: 1},
+		{Table: TableContentTypes, Code: constracts.ContentCertification, LegacyID: 2},
+		{Table: TableContentTypes, Code: constracts.ContentDirectorCards, LegacyID: 3},
+		{Table: TableAddressTypes, Code: constracts.AddressTypeDefault, LegacyID: 1},
+		{Table: TableClassifications, Code: DefaultClassificationCode(), LegacyID: 4},
+		{Table: TablePriceTypes, Code: constracts.PriceTypeSales, LegacyID: 1},
+		{Table: TablePriceTypes, Code: constracts.PriceTypeCustomer, LegacyID: 3},
+	}
+}
+
+// refCodeIDs хүснэгт:код -> ID, InitRefCodes дүүргэнэ. Багц оруулсны дараа дахин
+// дүүргэгддэг тул refCodeMutex-ээр хамгаална.
+var (
+	refCodeIDs   = map[string]uint{}
+	refCodeMutex sync.RWMutex
+)
+
+func refCodeKey(table, code string) string {
+	return table + ":" + code
+}
+
+// EnsureRefCodeColumns кодтой хүснэгтүүдэд code багана нэмнэ
+func EnsureRefCodeColumns(db *gorm.DB) error {
+	seen := map[string]bool{}
+	for _, refCode := range RefCodes() {
+		if seen[refCode.Table] {
+			continue
+		}
+		seen[refCode.Table] = true
+
+		if !db.Migrator().HasTable(refCode.Table) {
+			continue
+		}
+		if err := db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS code varchar(64)", refCode.Table)).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// InitRefCodes RefCodes-ын ID-г уншина. Олдоогүй код байвал бүгдийг нь жагсааж алдаа
+// буцаана. Кодыг лавлахын багцаар эсвэл -seed-ref-codes шилжүүлгээр оруулна.
+func InitRefCodes(db *gorm.DB) error {
+	if err := EnsureRefCodeColumns(db); err != nil {
+		return err
+	}
+
+	ids := map[string]uint{}
+	var missing []string
+	for _, refCode := range RefCodes() {
+		id, err := resolveRefCode(db, refCode)
+		if err != nil {
+			return err
+		}
+		if id == 0 {
+			missing = append(missing, refCodeKey(refCode.Table, refCode.Code))
+			continue
+		}
+		ids[refCodeKey(refCode.Table, refCode.Code)] = id
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %s. Лавлахын багц оруулах эсвэл -seed-ref-codes ажиллуулна уу",
+			ErrRefCodeMissing, strings.Join(missing, ", "))
+	}
+
+	refCodeMutex.Lock()
+	refCodeIDs = ids
+	refCodeMutex.Unlock()
+	return nil
+}
+
+// resolveRefCode кодын ID, олдоогүй бол 0
+func resolveRefCode(db *gorm.DB, refCode RefCode) (uint, error) {
+	if !db.Migrator().HasTable(refCode.Table) {
+		return 0, nil
+	}
+
+	var ids []uint
+	result := db.Table(refCode.Table).Where("code = ?", refCode.Code).Pluck("id", &ids)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	if len(ids) > 1 {
+		return 0, fmt.Errorf("%w: %s %s", ErrRefCodeTaken, refCode.Table, refCode.Code)
+	}
+	if len(ids) == 1 {
+		return ids[0], nil
+	}
+	return 0, nil
+}
+
+// SeedLegacyRefCodes код нэвтрэхээс өмнөх өгөгдлийн санд олдоогүй кодыг LegacyID
+// бичлэгт онооно. Зөвхөн кодгүй бичлэгт бичих бөгөөд оноосон бүрийг буцаана.
+// Эхлэх үед биш, шилжүүлгээр гараар ажиллуулна.
+func SeedLegacyRefCodes(tx *gorm.DB) ([]string, error) {
+	if err := EnsureRefCodeColumns(tx); err != nil {
+		return nil, err
+	}
+
+	var assigned []string
+	for _, refCode := range RefCodes() {
+		id, err := resolveRefCode(tx, refCode)
+		if err != nil {
+			return nil, err
+		}
+		if id != 0 || refCode.LegacyID == 0 || !tx.Migrator().HasTable(refCode.Table) {
+			continue
+		}
+
+		result := tx.Table(refCode.Table).
+			Where("id = ? AND (code IS NULL OR code = '')", refCode.LegacyID).
+			Update("code", refCode.Code)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected > 0 {
+			assigned = append(assigned, fmt.Sprintf("%s -> %d", refCodeKey(refCode.Table, refCode.Code), refCode.LegacyID))
+		}
+	}
+	return assigned, nil
+}
+
+// RefID кодын ID. Зөвхөн RefCodes-д байгаа кодоор дуудна.
+func RefID(table, code string) uint {
+	refCodeMutex.RLock()
+	defer refCodeMutex.RUnlock()
+
+	id, ok := refCodeIDs[refCodeKey(table, code)]
+	if !ok {
+		panic(fmt.Sprintf("ref code %s.%s is not registered", table, code))
+	}
+	return id
+}
+
+// MongoliaCountryID Монгол улсын ID
+func MongoliaCountryID() uint {
+	return RefID(TableCountries, constracts.MongoliaCountry)
+}
+
+// StatusID кодоор төлөвийн ID
+func StatusID(code string) uint {
+	return RefID(TableStatuses, code)
+}
+
+// ContentTypeID кодоор бичиг баримтын төрлийн ID
+func ContentTypeID(code string) uint {
+	return RefID(TableContentTypes, code)
+}
+
+// StatusTypeID кодоор төлөвийн төрлийн ID
+func StatusTypeID(code string) uint {
+	return RefID(TableStatusTypes, code)
+}
+
+// CustomerPriceTypeID харилцагчийн тусгай үнийн төрөл
+func CustomerPriceTypeID() uint {
+	return RefID(TablePriceTypes, constracts.PriceTypeCustomer)
+}
+
+// DefaultAddressTypeID хаягийн төрөл сонгоогүй үед өгөх төрөл
+func DefaultAddressTypeID() uint {
+	return RefID(TableAddressTypes, constracts.AddressTypeDefault)
+}
+
+// DefaultClassificationCode шинэ толгой байгууллагад өгөх ангиллын код
+func DefaultClassificationCode() string {
+	if code := viper.GetString("classification.defaultCode"); code != "" {
+		return code
+	}
+	return constracts.ClassificationDefault
+}
+
+// RegisteredRef бичлэг RefCodes-ын аль нэг кодтой эсэх
+func RegisteredRef(table string, id uint) bool {
+	refCodeMutex.RLock()
+	defer refCodeMutex.RUnlock()
+
+	for key, registeredID := range refCodeIDs {
+		if registeredID == id && strings.HasPrefix(key, table+":") {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckRefCode id бичлэгт code оноож болох эсэх: өөр бичлэг ашиглаагүй, системийн
+// кодтой бичлэгийн кодыг өөрчлөөгүй байх ёстой. Шинэ бичлэг бол id 0.
+func CheckRefCode(db *gorm.DB, table string, id uint, code string) error {
+	if id != 0 && RegisteredRef(table, id) {
+		var current string
+		if err := db.Table(table).Select("code").Where("id = ?", id).Scan(&current).Error; err != nil {
+			return err
+		}
+		if current != code {
+			return ErrRefCodeRequired
+		}
+	}
+
+	if code == "" {
+		return nil
+	}
+
+	var count int64
+	result := db.Table(table).Where("code = ? AND id <> ?", code, id).Count(&count)
+	if result.Error != nil {
+		return result.Error
+	}
+	if count > 0 {
+		return ErrRefCodeTaken
+	}
+	return nil
+}
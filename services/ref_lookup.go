@@ -0,0 +1,96 @@
+package services
+
+import (
+	"strings"
+
+	databases "gitlab.com/fibocloud/medtech/gin/databases"
+	gorm "gorm.io/gorm"
+)
+
+// RefLookup улс, аймаг хот, дүүрэг, харилцагчийн төрлийг нэрээр нь олно.
+// Олон мөр импортлох үед нэг удаа ачаална.
+type RefLookup struct {
+	countries     map[string]uint
+	cities        map[uint]map[string]uint // улс -> нэр -> ID
+	districts     map[uint]map[string]uint // хот -> нэр -> ID
+	customerTypes map[string]*databases.MedCustomerType
+}
+
+// NewRefLookup лавлах хүснэгтүүдийг ачаална
+func NewRefLookup(db *gorm.DB) (*RefLookup, error) {
+	lookup := RefLookup{
+		countries:     map[string]uint{},
+		cities:        map[uint]map[string]uint{},
+		districts:     map[uint]map[string]uint{},
+		customerTypes: map[string]*databases.MedCustomerType{},
+	}
+
+	var countries []databases.RefCountry
+	if err := db.Find(&countries).Error; err != nil {
+		return nil, err
+	}
+	for _, country := range countries {
+		lookup.countries[refKey(country.Name)] = country.Base.ID
+	}
+
+	var cities []databases.RefCity
+	if err := db.Find(&cities).Error; err != nil {
+		return nil, err
+	}
+	for _, city := range cities {
+		if lookup.cities[city.CountryID] == nil {
+			lookup.cities[city.CountryID] = map[string]uint{}
+		}
+		lookup.cities[city.CountryID][refKey(city.Name)] = city.Base.ID
+	}
+
+	var districts []databases.RefDistrict
+	if err := db.Find(&districts).Error; err != nil {
+		return nil, err
+	}
+	for _, district := range districts {
+		if lookup.districts[district.CityID] == nil {
+			lookup.districts[district.CityID] = map[string]uint{}
+		}
+		lookup.districts[district.CityID][refKey(district.Name)] = district.Base.ID
+	}
+
+	var customerTypes []*databases.MedCustomerType
+	if err := db.Where("is_active = ?", true).Find(&customerTypes).Error; err != nil {
+		return nil, err
+	}
+	for _, customerType := range customerTypes {
+		lookup.customerTypes[refKey(customerType.Name)] = customerType
+	}
+
+	return &lookup, nil
+}
+
+// Country улсын ID
+func (l *RefLookup) Country(name string) (uint, bool) {
+	id, ok := l.countries[refKey(name)]
+	return id, ok
+}
+
+// City тухайн улсын аймаг, хотын ID
+func (l *RefLookup) City(countryID uint, name string) (uint, bool) {
+	id, ok := l.cities[countryID][refKey(name)]
+	return id, ok
+}
+
+// District тухайн хотын дүүрэг, сумын ID
+func (l *RefLookup) District(cityID uint, name string) (uint, bool) {
+	id, ok := l.districts[cityID][refKey(name)]
+	return id, ok
+}
+
+// CustomerType харилцагчийн төрөл
+func (l *RefLookup) CustomerType(name string) (*databases.MedCustomerType, bool) {
+	customerType, ok := l.customerTypes[refKey(name)]
+	return customerType, ok
+}
+
+// refKey том жижиг үсэг, илүү зайг үл тооно
+func refKey(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), " ")
+}
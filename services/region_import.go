@@ -0,0 +1,268 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	databases "gitlab.com/fibocloud/medtech/gin/databases"
+	gorm "gorm.io/gorm"
+)
+
+// region.json-ийн region_type_id
+const (
+	RegionTypeZone     = 40  // Бүс, улсад харгалзана
+	RegionTypeCity     = 39  // Аймаг, нийслэл -> RefCity
+	RegionTypeDistrict = 58  // Сум, дүүрэг -> RefDistrict
+	RegionTypeStreet   = 102 // Баг, хороо -> RefStreet
+)
+
+// Region region.json-ийн нэг бичлэг
+type Region struct {
+	ID           uint        `json:"id"`
+	RegionName   string      `json:"region_name"`
+	RegionNameEn interface{} `json:"region_name_en"`
+	RegionTypeID int         `json:"region_type_id"`
+	ParentID     uint        `json:"parent_id"`
+	Order        int         `json:"order"`
+	CreatedAt    string      `json:"created_at"`
+	UpdatedAt    string      `json:"updated_at"`
+	IsDeleted    bool        `json:"is_deleted"`
+	CreateID     int         `json:"create_id"`
+	UpdateID     int         `json:"update_id"`
+}
+
+// RegionChange нэр нь өөрчлөгдсөн эсвэл эх файлаас хасагдсан бүс нутаг
+type RegionChange struct {
+	Table      string `json:"table"`       // ref_cities, ref_districts, ref_streets
+	ID         uint   `json:"id"`          //
+	ExternalID uint   `json:"external_id"` //
+	Name       string `json:"name"`        // Одоогийн нэр
+	NewName    string `json:"new_name"`    // Нэр өөрчлөгдсөн бол
+}
+
+// RegionImportReport region.json импортын үр дүн
+type RegionImportReport struct {
+	Created   int            `json:"created"`
+	Updated   int            `json:"updated"`
+	Unchanged int            `json:"unchanged"`
+	Renamed   []RegionChange `json:"renamed"`
+	Removed   []RegionChange `json:"removed"` // Өмнө импортлогдсон ч файлд байхгүй болсон, устгахгүй
+	Orphans   []uint         `json:"orphans"` // Эцэг нь олдоогүй бичлэгийн external ID
+}
+
+// regionLevel бүс нутгийн төрөл ба харгалзах хүснэгт
+type regionLevel struct {
+	typeID       int
+	table        string
+	parentColumn string
+}
+
+var regionLevels = []regionLevel{
+	{typeID: RegionTypeCity, table: "ref_cities", parentColumn: "country_id"},
+	{typeID: RegionTypeDistrict, table: "ref_districts", parentColumn: "city_id"},
+	{typeID: RegionTypeStreet, table: "ref_streets", parentColumn: "district_id"},
+}
+
+// regionRow хүснэгтэд байгаа бичлэг
+type regionRow struct {
+	ID         uint
+	Name       string
+	ParentID   uint
+	ExternalID uint
+	SortOrder  int
+}
+
+// ReadRegions region.json уншина
+func ReadRegions(reader io.Reader) ([]Region, error) {
+	var regions []Region
+	if err := json.NewDecoder(reader).Decode(&regions); err != nil {
+		return nil, err
+	}
+	return regions, nil
+}
+
+// ImportRegions бүс нутгийн модыг аймаг, сум, багаар нь дарааллаар external ID-аар
+// upsert хийнэ. Бүсүүд countryID-д харгалзана. Өмнө нь external ID-гүй үүссэн бичлэгийг
+// эцэг, нэрээр нь таниж external ID-г нь нөхнө.
+func ImportRegions(tx *gorm.DB, regions []Region, countryID uint, userID uint) (*RegionImportReport, error) {
+	report := RegionImportReport{
+		Renamed: []RegionChange{},
+		Removed: []RegionChange{},
+		Orphans: []uint{},
+	}
+
+	sort.SliceStable(regions, func(i, j int) bool {
+		if regions[i].Order != regions[j].Order {
+			return regions[i].Order < regions[j].Order
+		}
+		return regions[i].ID < regions[j].ID
+	})
+
+	// эх файлын ID -> манай ID, өмнөх түвшнийх
+	parents := map[uint]uint{}
+	for _, region := range regions {
+		if region.RegionTypeID == RegionTypeZone && !region.IsDeleted {
+			parents[region.ID] = countryID
+		}
+	}
+
+	now := time.Now()
+	for _, level := range regionLevels {
+		var rows []regionRow
+		result := tx.Table(level.table).
+			Select(fmt.Sprintf("id, name, %s AS parent_id, external_id, sort_order", level.parentColumn)).
+			Scan(&rows)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+
+		byExternal := map[uint]*regionRow{}
+		legacy := map[string]*regionRow{}
+		for i := range rows {
+			row := &rows[i]
+			if row.ExternalID != 0 {
+				byExternal[row.ExternalID] = row
+			} else {
+				legacy[fmt.Sprintf("%d/%s", row.ParentID, refKey(row.Name))] = row
+			}
+		}
+
+		ids := map[uint]uint{}
+		seen := map[uint]bool{}
+		for _, region := range regions {
+			if region.RegionTypeID != level.typeID || region.IsDeleted {
+				continue
+			}
+
+			parentID, ok := parents[region.ParentID]
+			if !ok {
+				report.Orphans = append(report.Orphans, region.ID)
+				continue
+			}
+
+			row, ok := byExternal[region.ID]
+			if !ok {
+				row, ok = legacy[fmt.Sprintf("%d/%s", parentID, refKey(region.RegionName))]
+			}
+
+			if !ok {
+				id, err := createRegion(tx, level, region, parentID, userID, now)
+				if err != nil {
+					return nil, err
+				}
+				ids[region.ID] = id
+				report.Created++
+				if err := saveRegionNameEn(tx, level, id, region, userID); err != nil {
+					return nil, err
+				}
+				continue
+			}
+
+			ids[region.ID] = row.ID
+			seen[row.ID] = true
+			if err := saveRegionNameEn(tx, level, row.ID, region, userID); err != nil {
+				return nil, err
+			}
+
+			if row.Name == region.RegionName && row.ParentID == parentID &&
+				row.ExternalID == region.ID && row.SortOrder == region.Order {
+				report.Unchanged++
+				continue
+			}
+
+			if row.Name != region.RegionName {
+				report.Renamed = append(report.Renamed, RegionChange{
+					Table:      level.table,
+					ID:         row.ID,
+					ExternalID: region.ID,
+					Name:       row.Name,
+					NewName:    region.RegionName,
+				})
+			}
+
+			result := tx.Table(level.table).Where("id = ?", row.ID).Updates(map[string]interface{}{
+				"name":             region.RegionName,
+				level.parentColumn: parentID,
+				"external_id":      region.ID,
+				"sort_order":       region.Order,
+				"modified_user_id": userID,
+				"modified_date":    now,
+			})
+			if result.Error != nil {
+				return nil, result.Error
+			}
+			report.Updated++
+		}
+
+		for _, row := range rows {
+			if row.ExternalID != 0 && !seen[row.ID] {
+				report.Removed = append(report.Removed, RegionChange{
+					Table:      level.table,
+					ID:         row.ID,
+					ExternalID: row.ExternalID,
+					Name:       row.Name,
+				})
+			}
+		}
+
+		parents = ids
+	}
+
+	return &report, nil
+}
+
+// saveRegionNameEn region_name_en байвал англи орчуулга болгоно
+func saveRegionNameEn(tx *gorm.DB, level regionLevel, id uint, region Region, userID uint) error {
+	nameEn, ok := region.RegionNameEn.(string)
+	if !ok || strings.TrimSpace(nameEn) == "" {
+		return nil
+	}
+	return saveTranslation(tx, level.table, id, "en", nameEn, userID)
+}
+
+func createRegion(tx *gorm.DB, level regionLevel, region Region, parentID, userID uint, now time.Time) (uint, error) {
+	base := databases.Base{CreatedDate: now, ModifiedDate: now}
+
+	switch level.typeID {
+	case RegionTypeCity:
+		city := databases.RefCity{
+			Name:           region.RegionName,
+			CountryID:      parentID,
+			ExternalID:     region.ID,
+			SortOrder:      region.Order,
+			CreatedUserID:  userID,
+			ModifiedUserID: userID,
+			Base:           base,
+		}
+		err := tx.Create(&city).Error
+		return city.Base.ID, err
+	case RegionTypeDistrict:
+		district := databases.RefDistrict{
+			Name:           region.RegionName,
+			CityID:         parentID,
+			ExternalID:     region.ID,
+			SortOrder:      region.Order,
+			CreatedUserID:  userID,
+			ModifiedUserID: userID,
+			Base:           base,
+		}
+		err := tx.Create(&district).Error
+		return district.Base.ID, err
+	default:
+		street := databases.RefStreet{
+			Name:           region.RegionName,
+			DistrictID:     parentID,
+			ExternalID:     region.ID,
+			SortOrder:      region.Order,
+			CreatedUserID:  userID,
+			ModifiedUserID: userID,
+			Base:           base,
+		}
+		err := tx.Create(&street).Error
+		return street.Base.ID, err
+	}
+}
@@ -0,0 +1,327 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	viper "github.com/spf13/viper"
+	databases "gitlab.com/fibocloud/medtech/gin/databases"
+	gorm "gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Улсын бүртгэлээс хайх үеийн алдаанууд
+var (
+	ErrRegistryInvalid     = errors.New("Регистрийн дугаар буруу байна")
+	ErrRegistryNotFound    = errors.New("Байгууллага улсын бүртгэлд олдсонгүй")
+	ErrRegistryUnavailable = errors.New("Улсын бүртгэлийн сервистэй холбогдож чадсангүй")
+)
+
+// registryNumberPattern байгууллага 7 оронтой, хувь хүн 2 үсэг 8 оронтой
+var registryNumberPattern = regexp.MustCompile(`^(\d{7}|[А-ЯЁӨҮ]{2}\d{8})$`)
+
+// CompanyInfo улсын бүртгэлд бүртгэлтэй байгууллагын мэдээлэл
+type CompanyInfo struct {
+	RegistryNumber         string `json:"registry_number"`
+	Name                   string `json:"name"`
+	VatPayer               bool   `json:"vatpayer"`
+	CityPayer              bool   `json:"citypayer"`
+	VatPayerRegisteredDate string `json:"vatpayer_registered_date"`
+	LastReceiptDate        string `json:"last_receipt_date"`
+	Cached                 bool   `json:"cached"`
+}
+
+// CompanyRegistry байгууллагын мэдээлэл хайх үйлчилгээ
+type CompanyRegistry interface {
+	Name() string
+	Lookup(ctx context.Context, registryNumber string) (*CompanyInfo, error)
+}
+
+// NormalizeRegistryNumber хоосон зай хасаж том үсгээр буцаана
+func NormalizeRegistryNumber(registryNumber string) string {
+	return strings.ToUpper(strings.TrimSpace(registryNumber))
+}
+
+// ValidRegistryNumber РД-ийн формат шалгах
+func ValidRegistryNumber(registryNumber string) bool {
+	return registryNumberPattern.MatchString(registryNumber)
+}
+
+// NewCompanyRegistry тохиргооноос хамааран үйлчилгээ үүсгээд өгөгдлийн санд кэшлэнэ
+func NewCompanyRegistry(db *gorm.DB) *CachedRegistry {
+	var provider CompanyRegistry
+	switch viper.GetString("registry.provider") {
+	case "file":
+		provider = &FileRegistry{Path: viper.GetString("registry.fixture")}
+	default:
+		provider = NewEbarimtRegistry()
+	}
+
+	ttl := time.Duration(viper.GetInt("registry.cacheTTL")) * time.Hour
+	if ttl <= 0 {
+		ttl = 24 * time.Hour
+	}
+
+	return &CachedRegistry{DB: db, Provider: provider, TTL: ttl}
+}
+
+// EbarimtRegistry info.ebarimt.mn дээрээс хайна
+type EbarimtRegistry struct {
+	URL        string
+	Client     *http.Client
+	Retries    int
+	RetryDelay time.Duration
+}
+
+// NewEbarimtRegistry тохиргооноос ebarimt үйлчилгээ үүсгэнэ
+func NewEbarimtRegistry() *EbarimtRegistry {
+	registryURL := viper.GetString("registry.url")
+	if registryURL == "" {
+		registryURL = "http://info.ebarimt.mn/rest/merchant/info"
+	}
+
+	timeout := time.Duration(viper.GetInt("registry.timeout")) * time.Second
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+
+	return &EbarimtRegistry{
+		URL:        registryURL,
+		Client:     &http.Client{Timeout: timeout},
+		Retries:    viper.GetInt("registry.retries"),
+		RetryDelay: 500 * time.Millisecond,
+	}
+}
+
+// Name provider нэр
+func (r *EbarimtRegistry) Name() string {
+	return "ebarimt"
+}
+
+// Lookup РД-аар байгууллага хайна
+func (r *EbarimtRegistry) Lookup(ctx context.Context, registryNumber string) (*CompanyInfo, error) {
+	registryNumber = NormalizeRegistryNumber(registryNumber)
+	if !ValidRegistryNumber(registryNumber) {
+		return nil, ErrRegistryInvalid
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= r.Retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, fmt.Errorf("%w: %v", ErrRegistryUnavailable, ctx.Err())
+			case <-time.After(r.RetryDelay * time.Duration(attempt)):
+			}
+		}
+
+		info, err := r.fetch(ctx, registryNumber)
+		if err == nil || !errors.Is(err, ErrRegistryUnavailable) {
+			return info, err
+		}
+		lastErr = err
+	}
+
+	return nil, lastErr
+}
+
+func (r *EbarimtRegistry) fetch(ctx context.Context, registryNumber string) (*CompanyInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.URL+"?regno="+url.QueryEscape(registryNumber), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := r.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrRegistryUnavailable, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusInternalServerError {
+		return nil, fmt.Errorf("%w: %v", ErrRegistryUnavailable, res.Status)
+	}
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrRegistryUnavailable, err)
+	}
+
+	var payload map[string]interface{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrRegistryUnavailable, err)
+	}
+
+	info := CompanyInfo{
+		RegistryNumber:         registryNumber,
+		Name:                   stringValue(payload["name"]),
+		VatPayer:               boolValue(payload["vatpayer"]),
+		CityPayer:              boolValue(payload["citypayer"]),
+		VatPayerRegisteredDate: stringValue(payload["vatpayer_registered_date"]),
+		LastReceiptDate:        stringValue(payload["last_receipt_date"]),
+	}
+
+	if !boolValue(payload["found"]) || info.Name == "" {
+		return nil, ErrRegistryNotFound
+	}
+
+	return &info, nil
+}
+
+// FileRegistry json файлаас уншдаг туршилтын үйлчилгээ
+type FileRegistry struct {
+	Path        string
+	Unavailable bool
+}
+
+// Name provider нэр
+func (r *FileRegistry) Name() string {
+	return "file"
+}
+
+// Lookup РД-аар байгууллага хайна
+func (r *FileRegistry) Lookup(ctx context.Context, registryNumber string) (*CompanyInfo, error) {
+	registryNumber = NormalizeRegistryNumber(registryNumber)
+	if !ValidRegistryNumber(registryNumber) {
+		return nil, ErrRegistryInvalid
+	}
+
+	if r.Unavailable {
+		return nil, ErrRegistryUnavailable
+	}
+
+	file, err := ioutil.ReadFile(r.Path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrRegistryUnavailable, err)
+	}
+
+	var companies map[string]CompanyInfo
+	if err := json.Unmarshal(file, &companies); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrRegistryUnavailable, err)
+	}
+
+	info, ok := companies[registryNumber]
+	if !ok {
+		return nil, ErrRegistryNotFound
+	}
+
+	info.RegistryNumber = registryNumber
+	return &info, nil
+}
+
+// CachedRegistry хайлтын үр дүнг TTL хугацаанд өгөгдлийн санд хадгална
+type CachedRegistry struct {
+	DB       *gorm.DB
+	Provider CompanyRegistry
+	TTL      time.Duration
+}
+
+// Name provider нэр
+func (r *CachedRegistry) Name() string {
+	return r.Provider.Name()
+}
+
+// Lookup эхлээд кэшээс хайгаад хугацаа нь дууссан бол provider-оос татна.
+// Provider холбогдохгүй үед хуучирсан кэш байвал түүнийг буцаана.
+func (r *CachedRegistry) Lookup(ctx context.Context, registryNumber string) (*CompanyInfo, error) {
+	return r.lookup(ctx, registryNumber, true)
+}
+
+// Refresh кэшийн хугацааг үл харгалзан provider-оос дахин татна
+func (r *CachedRegistry) Refresh(ctx context.Context, registryNumber string) (*CompanyInfo, error) {
+	return r.lookup(ctx, registryNumber, false)
+}
+
+func (r *CachedRegistry) lookup(ctx context.Context, registryNumber string, useCache bool) (*CompanyInfo, error) {
+	registryNumber = NormalizeRegistryNumber(registryNumber)
+	if !ValidRegistryNumber(registryNumber) {
+		return nil, ErrRegistryInvalid
+	}
+
+	var cache databases.MedCompanyRegistryCache
+	cached := r.DB.Where("registry_number = ?", registryNumber).Limit(1).Find(&cache).RowsAffected > 0
+
+	if useCache && cached && cache.ExpireDate.After(time.Now()) {
+		return cacheResult(cache)
+	}
+
+	info, err := r.Provider.Lookup(ctx, registryNumber)
+	if err != nil && !errors.Is(err, ErrRegistryNotFound) {
+		if cached && errors.Is(err, ErrRegistryUnavailable) {
+			return cacheResult(cache)
+		}
+		return nil, err
+	}
+
+	cache.RegistryNumber = registryNumber
+	cache.Provider = r.Provider.Name()
+	cache.Found = info != nil
+	cache.ExpireDate = time.Now().Add(r.TTL)
+	cache.ModifiedDate = time.Now()
+	if cache.Base.ID == 0 {
+		cache.CreatedDate = time.Now()
+	}
+	if info != nil {
+		payload, _ := json.Marshal(info)
+		cache.Name = info.Name
+		cache.VatPayer = info.VatPayer
+		cache.CityPayer = info.CityPayer
+		cache.VatPayerRegisteredDate = info.VatPayerRegisteredDate
+		cache.Payload = string(payload)
+	}
+
+	r.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "registry_number"}},
+		DoUpdates: clause.AssignmentColumns([]string{"provider", "found", "name", "vat_payer", "city_payer", "vat_payer_registered_date", "payload", "expire_date", "modified_date"}),
+	}).Create(&cache)
+
+	return info, err
+}
+
+func cacheResult(cache databases.MedCompanyRegistryCache) (*CompanyInfo, error) {
+	if !cache.Found {
+		return nil, ErrRegistryNotFound
+	}
+
+	var info CompanyInfo
+	if cache.Payload != "" {
+		json.Unmarshal([]byte(cache.Payload), &info)
+	}
+	info.RegistryNumber = cache.RegistryNumber
+	info.Name = cache.Name
+	info.VatPayer = cache.VatPayer
+	info.CityPayer = cache.CityPayer
+	info.VatPayerRegisteredDate = cache.VatPayerRegisteredDate
+	info.Cached = true
+	return &info, nil
+}
+
+// ebarimt зарим талбарыг bool, заримыг string-ээр буцаадаг
+func boolValue(value interface{}) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true" || v == "1" || v == "Y"
+	case float64:
+		return v != 0
+	}
+	return false
+}
+
+func stringValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case nil:
+		return ""
+	}
+	return fmt.Sprint(value)
+}
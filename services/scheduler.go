@@ -0,0 +1,39 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Schedule fn-г interval тутамд арын горимд ажиллуулна
+func Schedule(name string, interval time.Duration, fn func(ctx context.Context) error) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if err := fn(context.Background()); err != nil {
+				log.Println("job", name, "failed:", err)
+			}
+		}
+	}()
+}
+
+// ScheduleDaily fn-г өдөр бүр hour цагт арын горимд ажиллуулна
+func ScheduleDaily(name string, hour int, fn func(ctx context.Context) error) {
+	go func() {
+		for {
+			now := time.Now()
+			next := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, now.Location())
+			if !next.After(now) {
+				next = next.AddDate(0, 0, 1)
+			}
+			time.Sleep(next.Sub(now))
+
+			if err := fn(context.Background()); err != nil {
+				log.Println("job", name, "failed:", err)
+			}
+		}
+	}()
+}
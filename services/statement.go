@@ -0,0 +1,325 @@
+package services
+
+import (
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+	constracts "gitlab.com/fibocloud/medtech/gin/constracts"
+	databases "gitlab.com/fibocloud/medtech/gin/databases"
+	gorm "gorm.io/gorm"
+)
+
+// Тооцооны хуулгын гүйлгээний төрөл
+const (
+	LedgerOutcome = "outcome" // Зарлага, нэхэмжлэл
+	LedgerPayment = "payment" // Төлбөр
+	LedgerReturn  = "return"  // Буцаалт
+)
+
+// outcomeBaseTotal зарлагын баримтын валютаар бичигдсэн total-ыг valute_value ханшаар
+// үндсэн валют руу хөрвүүлнэ. Ханш хоосон бол үндсэн валютын зарлага.
+const outcomeBaseTotal = "total * COALESCE(NULLIF(valute_value, 0), 1)"
+
+// customerLedgerSQL харилцагчдын авлагын бүх гүйлгээ, үндсэн валютаар. Төлбөрийн amount
+// үндсэн валютаар хадгалагддаг тул зарлага, буцаалтыг л хөрвүүлнэ. Буцаалт тусдаа баримтгүй,
+// захиалгыг буцаагдсан төлөвт шилжүүлснээр бүртгэгддэг тул тэр төлөвт байгаа захиалгын
+// зарлагыг уг төлөвт орсон огноогоор хасна. Түүхгүй хуучин захиалгад modified_date ашиглана.
+// Параметрүүдийг customerLedgerArgs бэлдэнэ.
+const customerLedgerSQL = `
+	SELECT customer_id, created_date AS date, 'outcome' AS type, id AS document_id, ` + outcomeBaseTotal + ` AS debit, 0 AS credit, description
+	FROM med_outcomes WHERE customer_id IN (?)
+	UNION ALL
+	SELECT customer_id, payment_date, 'payment', id, 0, amount, description
+	FROM med_customer_payments WHERE customer_id IN (?)
+	UNION ALL
+	SELECT o.customer_id, COALESCE(r.return_date, b.modified_date), 'return', o.id, 0, o.total * COALESCE(NULLIF(o.valute_value, 0), 1), b.description
+	FROM med_outcomes o JOIN med_order_books b ON b.id = o.order_book_id
+	LEFT JOIN (
+		SELECT record_id, MAX(created_date) AS return_date FROM med_status_logs
+		WHERE hdr_table_name = 'med_order_books' AND status_id = ? GROUP BY record_id
+	) r ON r.record_id = b.id
+	WHERE o.customer_id IN (?) AND b.status_id = ?`
+
+// customerLedgerArgs customerLedgerSQL-ийн параметрүүд дарааллаар нь
+func customerLedgerArgs(customerIDs []uint) []interface{} {
+	returned := StatusID(constracts.StatusOrderReturned)
+	return []interface{}{customerIDs, customerIDs, returned, customerIDs, returned}
+}
+
+// LedgerEntry авлагын нэг гүйлгээ
+type LedgerEntry struct {
+	CustomerID  uint              `json:"customer_id"`
+	Date        time.Time         `json:"date"`
+	Type        string            `json:"type"`        // outcome, payment, return
+	DocumentID  uint              `json:"document_id"` // Зарлага эсвэл төлбөрийн ID
+	Debit       databases.Decimal `json:"debit"`       // Авлага нэмэгдсэн
+	Credit      databases.Decimal `json:"credit"`      // Авлага хасагдсан
+	Description string            `json:"description"` //
+}
+
+// StatementLine хуулгын мөр, тухайн гүйлгээний дараах үлдэгдэлтэй
+type StatementLine struct {
+	LedgerEntry
+	Balance databases.Decimal `json:"balance"`
+}
+
+// Statement харилцагчийн тооцооны хуулга
+type Statement struct {
+	Customer       databases.MedCustomer `json:"customer"`
+	StartDate      time.Time             `json:"start_date"`
+	EndDate        time.Time             `json:"end_date"`
+	OpeningBalance databases.Decimal     `json:"opening_balance"` // Эхний үлдэгдэл
+	TotalDebit     databases.Decimal     `json:"total_debit"`     //
+	TotalCredit    databases.Decimal     `json:"total_credit"`    //
+	ClosingBalance databases.Decimal     `json:"closing_balance"` // Эцсийн үлдэгдэл
+	Lines          []StatementLine       `json:"lines"`           //
+}
+
+// AgingFilter насжилтын тайлангийн шүүлт
+type AgingFilter struct {
+	AsOf             time.Time
+	ClassificationID uint
+	DistrictID       uint
+	ParentID         uint // Толгой байгууллага ба бүх салбар
+	ByDueDate        bool // Хуваарьтай зарлагыг төлөх огнооноос нь насжуулна
+}
+
+// AgingRow харилцагчийн төлөгдөөгүй авлага хоногоор
+type AgingRow struct {
+	CustomerID uint              `json:"customer_id"`
+	Code       string            `json:"code"`
+	Name       string            `json:"name"`
+	NotDue     databases.Decimal `json:"not_due"` // Төлөх хугацаа болоогүй, зөвхөн ByDueDate үед
+	Days0To30  databases.Decimal `json:"days_0_30"`
+	Days31To60 databases.Decimal `json:"days_31_60"`
+	Days61To90 databases.Decimal `json:"days_61_90"`
+	Over90     databases.Decimal `json:"days_over_90"`
+	Total      databases.Decimal `json:"total"`
+}
+
+// AgingReport авлагын насжилтын тайлан
+type AgingReport struct {
+	AsOf  time.Time  `json:"as_of"`
+	Rows  []AgingRow `json:"rows"`
+	Total AgingRow   `json:"total"`
+}
+
+// CustomerLedger харилцагчдын until-аас өмнөх гүйлгээ огноогоор эрэмбэлэгдсэн.
+// Дүнг үндсэн валютын бүхэлчлэлээр бүхэлчилнэ.
+func CustomerLedger(db *gorm.DB, customerIDs []uint, until time.Time) ([]LedgerEntry, error) {
+	var entries []LedgerEntry
+	if len(customerIDs) == 0 {
+		return entries, nil
+	}
+
+	result := db.Raw(
+		"SELECT * FROM ("+customerLedgerSQL+") ledger WHERE date < ? ORDER BY customer_id, date, document_id",
+		append(customerLedgerArgs(customerIDs), until)...,
+	).Scan(&entries)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	for i := range entries {
+		entries[i].Debit = RoundBase(entries[i].Debit.Decimal)
+		entries[i].Credit = RoundBase(entries[i].Credit.Decimal)
+	}
+	return entries, nil
+}
+
+// CustomerStatement start-аас end хүртэлх (end-ийг оруулаад) хуулга
+func CustomerStatement(db *gorm.DB, customerID uint, start, end time.Time) (*Statement, error) {
+	var customer databases.MedCustomer
+	result := db.First(&customer, customerID)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	start = dayStart(start)
+	until := dayStart(end).AddDate(0, 0, 1)
+
+	entries, err := CustomerLedger(db, []uint{customerID}, until)
+	if err != nil {
+		return nil, err
+	}
+
+	statement := Statement{
+		Customer:  customer,
+		StartDate: start,
+		EndDate:   end,
+		Lines:     []StatementLine{},
+	}
+
+	balance := decimal.Zero
+	for _, entry := range entries {
+		balance = balance.Add(entry.Debit.Decimal).Sub(entry.Credit.Decimal)
+		if entry.Date.Before(start) {
+			statement.OpeningBalance = databases.NewDecimal(balance)
+			continue
+		}
+
+		statement.TotalDebit = databases.NewDecimal(statement.TotalDebit.Add(entry.Debit.Decimal))
+		statement.TotalCredit = databases.NewDecimal(statement.TotalCredit.Add(entry.Credit.Decimal))
+		statement.Lines = append(statement.Lines, StatementLine{LedgerEntry: entry, Balance: databases.NewDecimal(balance)})
+	}
+	statement.ClosingBalance = databases.NewDecimal(balance)
+
+	return &statement, nil
+}
+
+// ReceivablesAging төлбөр, буцаалтыг хамгийн хуучин зарлагаас эхлэн хасаад
+// үлдсэн авлагыг зарлагын огнооноос хойш өнгөрсөн хоногоор ангилна. ByDueDate үед
+// төлбөрийн хуваарьтай зарлагыг хэсэг бүрийн төлөх огнооноос хэтэрсэн хоногоор ангилна.
+func ReceivablesAging(db *gorm.DB, filter AgingFilter) (*AgingReport, error) {
+	if filter.AsOf.IsZero() {
+		filter.AsOf = time.Now()
+	}
+
+	customerDB := db.Model(&databases.MedCustomer{})
+	if filter.ClassificationID != 0 {
+		customerDB = customerDB.Where("classification_id = ?", filter.ClassificationID)
+	}
+	if filter.DistrictID != 0 {
+		customerDB = customerDB.Where("district_id = ?", filter.DistrictID)
+	}
+	if filter.ParentID != 0 {
+		group := []uint{filter.ParentID}
+		nodes, err := CustomerDescendants(db, filter.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		for _, node := range nodes {
+			group = append(group, node.Base.ID)
+		}
+		customerDB = customerDB.Where("id IN ?", group)
+	}
+
+	var customers []databases.MedCustomer
+	result := customerDB.Order("name").Find(&customers)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	var customerIDs []uint
+	for _, customer := range customers {
+		customerIDs = append(customerIDs, customer.Base.ID)
+	}
+
+	asOf := dayStart(filter.AsOf).AddDate(0, 0, 1)
+	entries, err := CustomerLedger(db, customerIDs, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	byCustomer := map[uint][]LedgerEntry{}
+	for _, entry := range entries {
+		byCustomer[entry.CustomerID] = append(byCustomer[entry.CustomerID], entry)
+	}
+
+	schedules := map[uint][]databases.MedOutcomeInstallment{}
+	if filter.ByDueDate && len(customerIDs) > 0 {
+		var installments []databases.MedOutcomeInstallment
+		result := db.Where("customer_id IN ?", customerIDs).Order("outcome_id, seq").Find(&installments)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		for _, installment := range installments {
+			schedules[installment.OutcomeID] = append(schedules[installment.OutcomeID], installment)
+		}
+	}
+
+	report := AgingReport{AsOf: filter.AsOf, Rows: []AgingRow{}}
+	for _, customer := range customers {
+		row := AgingRow{CustomerID: customer.Base.ID, Code: customer.Code, Name: customer.Name}
+		for _, open := range OpenItems(byCustomer[customer.Base.ID]) {
+			installments := schedules[open.DocumentID]
+			if len(installments) == 0 {
+				row.add(int(asOf.Sub(dayStart(open.Date)).Hours()/24)-1, open.Debit.Decimal)
+				continue
+			}
+
+			// Төлөгдсөн хэсгийг хуваарийн эхнээс хасаад үлдсэнийг төлөх огнооноос насжуулна
+			total := decimal.Zero
+			for _, installment := range installments {
+				total = total.Add(installment.Amount.Decimal)
+			}
+			amounts := allocatePaid(installments, total.Sub(open.Debit.Decimal))
+			for i, installment := range installments {
+				if installmentSettled(installment, amounts[i]) {
+					continue
+				}
+				remaining := installment.Amount.Sub(amounts[i])
+				days := int(asOf.Sub(dayStart(installment.DueDate)).Hours()/24) - 1
+				if days <= 0 {
+					row.NotDue = databases.NewDecimal(row.NotDue.Add(remaining))
+					row.Total = databases.NewDecimal(row.Total.Add(remaining))
+					continue
+				}
+				row.add(days, remaining)
+			}
+		}
+
+		if row.Total.IsZero() {
+			continue
+		}
+
+		report.Rows = append(report.Rows, row)
+		report.Total.NotDue = databases.NewDecimal(report.Total.NotDue.Add(row.NotDue.Decimal))
+		report.Total.Days0To30 = databases.NewDecimal(report.Total.Days0To30.Add(row.Days0To30.Decimal))
+		report.Total.Days31To60 = databases.NewDecimal(report.Total.Days31To60.Add(row.Days31To60.Decimal))
+		report.Total.Days61To90 = databases.NewDecimal(report.Total.Days61To90.Add(row.Days61To90.Decimal))
+		report.Total.Over90 = databases.NewDecimal(report.Total.Over90.Add(row.Over90.Decimal))
+		report.Total.Total = databases.NewDecimal(report.Total.Total.Add(row.Total.Decimal))
+	}
+
+	return &report, nil
+}
+
+// add days хоногийн amount авлагыг ангилна
+func (row *AgingRow) add(days int, amount decimal.Decimal) {
+	bucket := &row.Over90
+	switch {
+	case days <= 30:
+		bucket = &row.Days0To30
+	case days <= 60:
+		bucket = &row.Days31To60
+	case days <= 90:
+		bucket = &row.Days61To90
+	}
+	*bucket = databases.NewDecimal(bucket.Add(amount))
+	row.Total = databases.NewDecimal(row.Total.Add(amount))
+}
+
+// OpenItems нэг харилцагчийн гүйлгээнээс төлөгдөөгүй зарлагын үлдэгдлийг олно.
+// Debit нь тухайн зарлагын төлөгдөөгүй дүн болно.
+func OpenItems(entries []LedgerEntry) []LedgerEntry {
+	var debits []LedgerEntry
+	credit := decimal.Zero
+	for _, entry := range entries {
+		if entry.Debit.IsPositive() {
+			debits = append(debits, entry)
+		}
+		credit = credit.Add(entry.Credit.Decimal)
+	}
+
+	sort.SliceStable(debits, func(i, j int) bool {
+		return debits[i].Date.Before(debits[j].Date)
+	})
+
+	var open []LedgerEntry
+	for _, debit := range debits {
+		if credit.GreaterThanOrEqual(debit.Debit.Decimal) {
+			credit = credit.Sub(debit.Debit.Decimal)
+			continue
+		}
+		debit.Debit = databases.NewDecimal(debit.Debit.Sub(credit))
+		credit = decimal.Zero
+		open = append(open, debit)
+	}
+	return open
+}
+
+func dayStart(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+}
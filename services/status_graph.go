@@ -0,0 +1,472 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	databases "gitlab.com/fibocloud/medtech/gin/databases"
+	gorm "gorm.io/gorm"
+)
+
+// Төлөвийн графын алдаанууд
+var (
+	ErrGraphStatus      = errors.New("Төлөв энэ төрөлд хамаарахгүй байна")
+	ErrGraphNodes       = errors.New("Төрлийн идэвхитэй төлөв бүр графд нэг удаа орох ёстой")
+	ErrGraphStart       = errors.New("Графд идэвхитэй нэг анхны төлөв байх ёстой")
+	ErrGraphEdge        = errors.New("Шилжилт буруу байна")
+	ErrGraphRole        = errors.New("Шилжилтийн дүр олдсонгүй")
+	ErrGraphUnreachable = errors.New("Анхны төлөвөөс хүрэх боломжгүй төлөв байна")
+)
+
+// StatusGraphNode графын төлөв
+type StatusGraphNode struct {
+	StatusID    uint   `json:"status_id"`   //
+	Name        string `json:"name"`        //
+	Description string `json:"description"` //
+	ColorCode   string `json:"color_code"`  // Өнгө
+	Seq         int    `json:"seq"`         // Харуулах дараалал
+	IsStart     bool   `json:"is_start"`    // Анхны төлөв
+	IsActive    bool   `json:"is_active"`   //
+	IsFinal     bool   `json:"is_final"`    // Идэвхитэй гарах шилжилтгүй
+	Reachable   bool   `json:"reachable"`   // Анхны төлөвөөс идэвхитэй шилжилтээр хүрэх боломжтой
+}
+
+// StatusGraphEdge графын шилжилт
+type StatusGraphEdge struct {
+	TransitionID   uint                       `json:"transition_id"`   //
+	DocumentType   string                     `json:"document_type"`   // customer, order_book, income, outcome
+	Name           string                     `json:"name"`            // Үйлдлийн нэр
+	FromStatusID   uint                       `json:"from_status_id"`  //
+	ToStatusID     uint                       `json:"to_status_id"`    //
+	RequireComment bool                       `json:"require_comment"` // Тайлбар заавал эсэх
+	IsActive       bool                       `json:"is_active"`       //
+	Roles          []*databases.MedSystemRole `json:"roles"`           // Шилжүүлэх эрхтэй дүрүүд, хоосон бол бүгд
+}
+
+// StatusGraph төлөвийн төрлийн шилжилтийн граф
+type StatusGraph struct {
+	StatusTypeID  uint              `json:"status_type_id"`  //
+	Name          string            `json:"name"`            //
+	StartStatusID uint              `json:"start_status_id"` // 0 бол анхны төлөв тохируулаагүй
+	Nodes         []StatusGraphNode `json:"nodes"`           // Дарааллаар
+	Edges         []StatusGraphEdge `json:"edges"`           //
+	Unreachable   []uint            `json:"unreachable"`     // Хүрэх боломжгүй идэвхитэй төлөвүүд
+}
+
+// StatusNodeInput графын төлөвийн тохиргоо
+type StatusNodeInput struct {
+	StatusID  uint
+	Seq       int
+	IsStart   bool
+	ColorCode string // Хоосон бол өөрчлөхгүй
+}
+
+// StatusEdgeInput графын шилжилт
+type StatusEdgeInput struct {
+	DocumentType   string
+	Name           string
+	FromStatusID   uint
+	ToStatusID     uint
+	RequireComment bool
+	RoleIDs        []uint
+	IsActive       bool
+}
+
+// StatusGraphInput графыг бүхлээр нь солих өгөгдөл
+type StatusGraphInput struct {
+	Nodes []StatusNodeInput
+	Edges []StatusEdgeInput
+}
+
+// LoadStatusGraph төлөвийн төрлийн төлөвүүдийг дарааллаар нь, тэдгээрийн хоорондох
+// шилжилтийг бүх баримтын төрлөөр буцаана. Хүрэх боломжийг баримтын төрөл тус бүрээр
+// тооцно. Нэрийг locales хэлээр орчуулна.
+func LoadStatusGraph(db *gorm.DB, statusTypeID uint, locales []string) (*StatusGraph, error) {
+	var statusType databases.MedStatusType
+	if err := db.First(&statusType, statusTypeID).Error; err != nil {
+		return nil, err
+	}
+
+	statuses, err := typeStatuses(db, statusTypeID)
+	if err != nil {
+		return nil, err
+	}
+	_ = TranslateNames(db, locales, &statusType)
+	_ = TranslateNames(db, locales, &statuses)
+
+	var nodes []databases.MedStatusNode
+	if err := db.Where("status_id IN ?", statusIDs(statuses)).Find(&nodes).Error; err != nil {
+		return nil, err
+	}
+	nodeByStatus := map[uint]databases.MedStatusNode{}
+	for _, node := range nodes {
+		nodeByStatus[node.StatusID] = node
+	}
+
+	transitions, err := typeTransitions(db, statuses)
+	if err != nil {
+		return nil, err
+	}
+
+	graph := StatusGraph{
+		StatusTypeID: statusTypeID,
+		Name:         statusType.Name,
+		Nodes:        []StatusGraphNode{},
+		Edges:        []StatusGraphEdge{},
+		Unreachable:  []uint{},
+	}
+
+	outgoing := map[uint]bool{}
+	var active []statusEdge
+	for _, transition := range transitions {
+		graph.Edges = append(graph.Edges, StatusGraphEdge{
+			TransitionID:   transition.Base.ID,
+			DocumentType:   transition.DocumentType,
+			Name:           transition.Name,
+			FromStatusID:   transition.FromStatusID,
+			ToStatusID:     transition.ToStatusID,
+			RequireComment: transition.RequireComment,
+			IsActive:       transition.IsActive,
+			Roles:          transition.Roles,
+		})
+		if transition.IsActive {
+			outgoing[transition.FromStatusID] = true
+			active = append(active, statusEdge{document: transition.DocumentType, from: transition.FromStatusID, to: transition.ToStatusID})
+		}
+	}
+
+	ordered := map[uint]bool{}
+	for _, status := range statuses {
+		node, ok := nodeByStatus[status.Base.ID]
+		ordered[status.Base.ID] = ok
+		if node.IsStart {
+			graph.StartStatusID = status.Base.ID
+		}
+		graph.Nodes = append(graph.Nodes, StatusGraphNode{
+			StatusID:    status.Base.ID,
+			Name:        status.Name,
+			Description: status.Description,
+			ColorCode:   status.ColorCode,
+			Seq:         node.Seq,
+			IsStart:     node.IsStart,
+			IsActive:    status.IsActive,
+			IsFinal:     !outgoing[status.Base.ID],
+		})
+	}
+
+	// Графд тохируулаагүй төлөвүүд ID-аар сүүлд
+	sort.SliceStable(graph.Nodes, func(i, j int) bool {
+		a, b := graph.Nodes[i], graph.Nodes[j]
+		if ordered[a.StatusID] != ordered[b.StatusID] {
+			return ordered[a.StatusID]
+		}
+		if a.Seq != b.Seq {
+			return a.Seq < b.Seq
+		}
+		return a.StatusID < b.StatusID
+	})
+
+	reached := reachableStatuses(graph.StartStatusID, active)
+	for i := range graph.Nodes {
+		graph.Nodes[i].Reachable = reached[graph.Nodes[i].StatusID]
+		if graph.Nodes[i].IsActive && !graph.Nodes[i].Reachable {
+			graph.Unreachable = append(graph.Unreachable, graph.Nodes[i].StatusID)
+		}
+	}
+
+	return &graph, nil
+}
+
+// SaveStatusGraph графыг шалгаад төлөвийн дараалал, өнгө, шилжилтийг солино.
+// Төрлийн төлөвүүдийн хоорондох оруулаагүй шилжилтийг устгана.
+func SaveStatusGraph(db *gorm.DB, statusTypeID uint, input StatusGraphInput, userID uint) error {
+	var statusType databases.MedStatusType
+	if err := db.First(&statusType, statusTypeID).Error; err != nil {
+		return err
+	}
+
+	statuses, err := typeStatuses(db, statusTypeID)
+	if err != nil {
+		return err
+	}
+	if err := ValidateStatusGraph(statuses, input); err != nil {
+		return err
+	}
+
+	if err := saveStatusNodes(db, statusTypeID, statuses, input.Nodes, userID); err != nil {
+		return err
+	}
+	return saveStatusEdges(db, statuses, input.Edges, userID)
+}
+
+// ValidateStatusGraph төлөв бүр энэ төрлийнх, идэвхитэй төлөв бүр нэг удаа орсон, нэг анхны
+// төлөвтэй, идэвхитэй төлөв бүрт анхны төлөвөөс аль нэг баримтын төрлийн идэвхитэй
+// шилжилтээр хүрч болох эсэх. Баримт өөр төрлийн шилжилтээр явдаггүй тул төрлүүдийн
+// шилжилтийг нийлүүлж тооцохгүй.
+func ValidateStatusGraph(statuses []databases.MedStatus, input StatusGraphInput) error {
+	byID := map[uint]databases.MedStatus{}
+	for _, status := range statuses {
+		byID[status.Base.ID] = status
+	}
+
+	listed := map[uint]bool{}
+	var startID uint
+	starts := 0
+	for _, node := range input.Nodes {
+		if _, ok := byID[node.StatusID]; !ok {
+			return fmt.Errorf("%w: %d", ErrGraphStatus, node.StatusID)
+		}
+		if listed[node.StatusID] {
+			return fmt.Errorf("%w: %s", ErrGraphNodes, byID[node.StatusID].Name)
+		}
+		listed[node.StatusID] = true
+		if node.IsStart {
+			startID = node.StatusID
+			starts++
+		}
+	}
+	for _, status := range statuses {
+		if status.IsActive && !listed[status.Base.ID] {
+			return fmt.Errorf("%w: %s", ErrGraphNodes, status.Name)
+		}
+	}
+	if starts != 1 || !byID[startID].IsActive {
+		return ErrGraphStart
+	}
+
+	seen := map[string]bool{}
+	var active []statusEdge
+	for _, edge := range input.Edges {
+		if _, ok := DocumentTables[edge.DocumentType]; !ok {
+			return ErrUnknownDocument
+		}
+		for _, statusID := range []uint{edge.FromStatusID, edge.ToStatusID} {
+			if _, ok := byID[statusID]; !ok {
+				return fmt.Errorf("%w: %d", ErrGraphStatus, statusID)
+			}
+		}
+		if edge.FromStatusID == edge.ToStatusID {
+			return fmt.Errorf("%w: %s -> %s", ErrGraphEdge, byID[edge.FromStatusID].Name, byID[edge.ToStatusID].Name)
+		}
+
+		key := edgeKey(edge.DocumentType, edge.FromStatusID, edge.ToStatusID)
+		if seen[key] {
+			return fmt.Errorf("%w: %s %s -> %s давхардсан", ErrGraphEdge, edge.DocumentType, byID[edge.FromStatusID].Name, byID[edge.ToStatusID].Name)
+		}
+		seen[key] = true
+
+		if edge.IsActive {
+			active = append(active, statusEdge{document: edge.DocumentType, from: edge.FromStatusID, to: edge.ToStatusID})
+		}
+	}
+
+	reached := reachableStatuses(startID, active)
+	var unreachable []string
+	for _, status := range statuses {
+		if status.IsActive && !reached[status.Base.ID] {
+			unreachable = append(unreachable, status.Name)
+		}
+	}
+	if len(unreachable) > 0 {
+		return fmt.Errorf("%w: %s", ErrGraphUnreachable, strings.Join(unreachable, ", "))
+	}
+	return nil
+}
+
+type statusEdge struct {
+	document string
+	from     uint
+	to       uint
+}
+
+// reachableStatuses start-аас баримтын аль нэг төрлийн edges-ээр хүрэх төлөвүүд, start-ыг оруулаад
+func reachableStatuses(start uint, edges []statusEdge) map[uint]bool {
+	reached := map[uint]bool{}
+	if start == 0 {
+		return reached
+	}
+	reached[start] = true
+
+	next := map[string]map[uint][]uint{}
+	for _, edge := range edges {
+		if next[edge.document] == nil {
+			next[edge.document] = map[uint][]uint{}
+		}
+		next[edge.document][edge.from] = append(next[edge.document][edge.from], edge.to)
+	}
+
+	for _, documentNext := range next {
+		visited := map[uint]bool{start: true}
+		queue := []uint{start}
+		for len(queue) > 0 {
+			current := queue[0]
+			queue = queue[1:]
+			for _, to := range documentNext[current] {
+				if !visited[to] {
+					visited[to] = true
+					reached[to] = true
+					queue = append(queue, to)
+				}
+			}
+		}
+	}
+	return reached
+}
+
+func edgeKey(documentType string, fromStatusID, toStatusID uint) string {
+	return fmt.Sprintf("%s:%d:%d", documentType, fromStatusID, toStatusID)
+}
+
+func statusIDs(statuses []databases.MedStatus) []uint {
+	ids := []uint{}
+	for _, status := range statuses {
+		ids = append(ids, status.Base.ID)
+	}
+	return ids
+}
+
+func typeStatuses(db *gorm.DB, statusTypeID uint) ([]databases.MedStatus, error) {
+	var statuses []databases.MedStatus
+	result := db.Where("status_type_id = ?", statusTypeID).Order("id").Find(&statuses)
+	return statuses, result.Error
+}
+
+// typeTransitions хоёр төлөв нь statuses-д орсон шилжилтүүд
+func typeTransitions(db *gorm.DB, statuses []databases.MedStatus) ([]databases.MedStatusTransition, error) {
+	transitions := []databases.MedStatusTransition{}
+	if len(statuses) == 0 {
+		return transitions, nil
+	}
+
+	ids := statusIDs(statuses)
+	result := db.
+		Where("from_status_id IN ? AND to_status_id IN ?", ids, ids).
+		Preload("Roles").
+		Order("document_type, id").
+		Find(&transitions)
+	return transitions, result.Error
+}
+
+// saveStatusNodes төлөвийн зангилааг status_id-аар нь олж шинэчилнэ. Төлөв өөр төрлөөс
+// шилжиж ирсэн бол хуучин зангилааг нь энэ төрөлд шилжүүлнэ.
+func saveStatusNodes(db *gorm.DB, statusTypeID uint, statuses []databases.MedStatus, inputs []StatusNodeInput, userID uint) error {
+	var existing []databases.MedStatusNode
+	result := db.Where("status_type_id = ? OR status_id IN ?", statusTypeID, statusIDs(statuses)).Find(&existing)
+	if result.Error != nil {
+		return result.Error
+	}
+	byStatus := map[uint]databases.MedStatusNode{}
+	for _, node := range existing {
+		byStatus[node.StatusID] = node
+	}
+
+	now := time.Now()
+	listed := map[uint]bool{}
+	for _, input := range inputs {
+		listed[input.StatusID] = true
+
+		node, ok := byStatus[input.StatusID]
+		if !ok {
+			node = databases.MedStatusNode{
+				StatusID:      input.StatusID,
+				CreatedUserID: userID,
+				Base:          databases.Base{CreatedDate: now},
+			}
+		}
+		node.StatusTypeID = statusTypeID
+		node.Seq = input.Seq
+		node.IsStart = input.IsStart
+		node.ModifiedUserID = userID
+		node.Base.ModifiedDate = now
+		if err := db.Save(&node).Error; err != nil {
+			return err
+		}
+
+		if input.ColorCode != "" {
+			result := db.Model(&databases.MedStatus{}).
+				Where("id = ?", input.StatusID).
+				Updates(map[string]interface{}{"color_code": input.ColorCode, "modified_date": now})
+			if result.Error != nil {
+				return result.Error
+			}
+		}
+	}
+
+	for _, node := range existing {
+		if listed[node.StatusID] {
+			continue
+		}
+		if err := db.Delete(&databases.MedStatusNode{}, node.Base.ID).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func saveStatusEdges(db *gorm.DB, statuses []databases.MedStatus, inputs []StatusEdgeInput, userID uint) error {
+	transitions, err := typeTransitions(db, statuses)
+	if err != nil {
+		return err
+	}
+	byKey := map[string]databases.MedStatusTransition{}
+	for _, transition := range transitions {
+		byKey[edgeKey(transition.DocumentType, transition.FromStatusID, transition.ToStatusID)] = transition
+	}
+
+	now := time.Now()
+	listed := map[string]bool{}
+	for _, input := range inputs {
+		key := edgeKey(input.DocumentType, input.FromStatusID, input.ToStatusID)
+		listed[key] = true
+
+		roles := []*databases.MedSystemRole{}
+		if len(input.RoleIDs) > 0 {
+			if err := db.Find(&roles, input.RoleIDs).Error; err != nil {
+				return err
+			}
+			if len(roles) != len(input.RoleIDs) {
+				return ErrGraphRole
+			}
+		}
+
+		transition, ok := byKey[key]
+		if !ok {
+			transition = databases.MedStatusTransition{
+				DocumentType:  input.DocumentType,
+				FromStatusID:  input.FromStatusID,
+				ToStatusID:    input.ToStatusID,
+				CreatedUserID: userID,
+				Base:          databases.Base{CreatedDate: now},
+			}
+		}
+		transition.Name = input.Name
+		transition.RequireComment = input.RequireComment
+		transition.IsActive = input.IsActive
+		transition.ModifiedUserID = userID
+		transition.Base.ModifiedDate = now
+		transition.Roles = nil
+
+		if err := db.Save(&transition).Error; err != nil {
+			return err
+		}
+		if err := db.Model(&transition).Association("Roles").Replace(roles); err != nil {
+			return err
+		}
+	}
+
+	for key, transition := range byKey {
+		if listed[key] {
+			continue
+		}
+		if err := db.Model(&transition).Association("Roles").Clear(); err != nil {
+			return err
+		}
+		if err := db.Delete(&databases.MedStatusTransition{}, transition.Base.ID).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	viper "github.com/spf13/viper"
+	databases "gitlab.com/fibocloud/medtech/gin/databases"
+	gorm "gorm.io/gorm"
+)
+
+// TaxRefreshResult татварын мэдээлэл шинэчилсэн үр дүн
+type TaxRefreshResult struct {
+	Checked int                               `json:"checked"`
+	Changed []databases.MedCustomerTaxHistory `json:"changed"`
+	Failed  map[string]string                 `json:"failed"` // РД - алдаа
+}
+
+// ApplyTaxInfo улсын бүртгэлийн мэдээллийг харилцагч дээр хуулна
+func ApplyTaxInfo(customer *databases.MedCustomer, company *CompanyInfo) {
+	now := time.Now()
+	customer.VatPayer = company.VatPayer
+	customer.CityPayer = company.CityPayer
+	customer.VatRegisteredDate = company.VatPayerRegisteredDate
+	customer.TaxCheckedDate = &now
+}
+
+// StartTaxRefreshJob РД-тэй харилцагчдын татварын мэдээллийг тогтмол шинэчилнэ
+func StartTaxRefreshJob(db *gorm.DB) {
+	interval := time.Duration(viper.GetInt("registry.refreshInterval")) * time.Hour
+	if interval <= 0 {
+		interval = 24 * time.Hour
+	}
+
+	Schedule("tax_refresh", interval, func(ctx context.Context) error {
+		_, err := RefreshTaxStatus(ctx, db, NewCompanyRegistry(db), 0)
+		return err
+	})
+}
+
+// RefreshTaxStatus РД-тэй бүх харилцагчийг улсын бүртгэлээс дахин шалгаж
+// өөрчлөгдсөнийг түүхэнд бичнэ. Салбарууд толгой байгууллагын мэдээллийг авч,
+// өөрчлөгдсөн салбар бүрт мөн түүх бичигдэнэ.
+func RefreshTaxStatus(ctx context.Context, db *gorm.DB, registry *CachedRegistry, userID uint) (TaxRefreshResult, error) {
+	result := TaxRefreshResult{Failed: map[string]string{}}
+
+	var customers []databases.MedCustomer
+	err := db.Where("company_registry_number <> ?", "").Order("id asc").Find(&customers).Error
+	if err != nil {
+		return result, err
+	}
+
+	for _, customer := range customers {
+		company, err := registry.Refresh(ctx, customer.CompanyRegistryNumber)
+		if err != nil {
+			if errors.Is(err, ErrRegistryUnavailable) {
+				return result, err
+			}
+			result.Failed[customer.CompanyRegistryNumber] = err.Error()
+			continue
+		}
+		result.Checked++
+
+		ApplyTaxInfo(&customer, company)
+		updates := map[string]interface{}{
+			"vat_payer":           customer.VatPayer,
+			"city_payer":          customer.CityPayer,
+			"vat_registered_date": customer.VatRegisteredDate,
+			"tax_checked_date":    customer.TaxCheckedDate,
+		}
+
+		var histories []databases.MedCustomerTaxHistory
+		err = db.Transaction(func(tx *gorm.DB) error {
+			// Толгой байгууллага ба салбар бүрийн өмнөх утгыг түүхэнд бичнэ
+			var group []databases.MedCustomer
+			if err := tx.Where("id = ? OR parent_id = ?", customer.Base.ID, customer.Base.ID).
+				Order("id asc").
+				Find(&group).Error; err != nil {
+				return err
+			}
+
+			if err := tx.Model(&databases.MedCustomer{}).
+				Where("id = ? OR parent_id = ?", customer.Base.ID, customer.Base.ID).
+				Updates(updates).Error; err != nil {
+				return err
+			}
+
+			for _, member := range group {
+				history := databases.MedCustomerTaxHistory{
+					CustomerID:        member.Base.ID,
+					RegistryNumber:    customer.CompanyRegistryNumber,
+					OldVatPayer:       member.VatPayer,
+					OldCityPayer:      member.CityPayer,
+					OldRegisteredDate: member.VatRegisteredDate,
+					NewVatPayer:       company.VatPayer,
+					NewCityPayer:      company.CityPayer,
+					NewRegisteredDate: company.VatPayerRegisteredDate,
+					CreatedUserID:     userID,
+					Base: databases.Base{
+						CreatedDate: time.Now(),
+					},
+				}
+				changed := history.OldVatPayer != history.NewVatPayer ||
+					history.OldCityPayer != history.NewCityPayer ||
+					history.OldRegisteredDate != history.NewRegisteredDate
+				if !changed {
+					continue
+				}
+
+				if err := tx.Create(&history).Error; err != nil {
+					return err
+				}
+				changedCustomer := member
+				history.Customer = &changedCustomer
+				histories = append(histories, history)
+			}
+			return nil
+		})
+		if err != nil {
+			result.Failed[customer.CompanyRegistryNumber] = err.Error()
+			continue
+		}
+
+		result.Changed = append(result.Changed, histories...)
+	}
+
+	return result, nil
+}
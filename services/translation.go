@@ -0,0 +1,286 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	viper "github.com/spf13/viper"
+	databases "gitlab.com/fibocloud/medtech/gin/databases"
+	gorm "gorm.io/gorm"
+	clause "gorm.io/gorm/clause"
+)
+
+// Орчуулгын алдаанууд
+var (
+	ErrTranslationEntity   = errors.New("Орчуулах боломжгүй лавлах")
+	ErrTranslationLocale   = errors.New("Үндсэн хэлний нэрийг лавлах дээрээ засна уу")
+	ErrTranslationEntityID = errors.New("Лавлахын бичлэг олдсонгүй")
+)
+
+// TranslatableEntities нэрийг нь орчуулж болох лавлахууд, хүснэгтийн нэрээр
+var TranslatableEntities = map[string]bool{
+	"ref_countries":                true,
+	"ref_cities":                   true,
+	"ref_districts":                true,
+	"ref_streets":                  true,
+	"ref_valutes":                  true,
+	"med_statuses":                 true,
+	"med_status_types":             true,
+	"med_payment_types":            true,
+	"med_payment_methods":          true,
+	"med_address_types":            true,
+	"med_customer_types":           true,
+	"med_customer_classifications": true,
+}
+
+// TranslationInput нэг бичлэгийн нэг хэлний нэр. Name хоосон бол орчуулгыг устгана.
+type TranslationInput struct {
+	EntityID uint
+	Locale   string
+	Name     string
+}
+
+// TranslationRow лавлахын бичлэг ба бүх хэлний нэр
+type TranslationRow struct {
+	EntityID     uint              `json:"entity_id"`
+	Name         string            `json:"name"`         // Үндсэн хэлний нэр
+	Translations map[string]string `json:"translations"` // хэл -> нэр
+}
+
+// DefaultLocale лавлахын Name баганын хэл
+func DefaultLocale() string {
+	if locale := viper.GetString("i18n.defaultLocale"); locale != "" {
+		return normalizeLocale(locale)
+	}
+	return "mn"
+}
+
+// LocaleChain хүссэн хэлнүүдээс хайх дараалал гаргана: en-us -> en -> i18n.fallback -> үндсэн хэл.
+// Хэл хүсээгүй бол зөвхөн үндсэн хэл, i18n.fallback нь хүссэн хэлний дараа л орно.
+func LocaleChain(requested []string) []string {
+	var chain []string
+	seen := map[string]bool{}
+	add := func(locale string) {
+		locale = normalizeLocale(locale)
+		if locale == "" || seen[locale] {
+			return
+		}
+		seen[locale] = true
+		chain = append(chain, locale)
+	}
+
+	for _, locale := range requested {
+		locale = normalizeLocale(locale)
+		add(locale)
+		if i := strings.Index(locale, "-"); i > 0 {
+			add(locale[:i])
+		}
+	}
+	if len(chain) > 0 {
+		for _, locale := range viper.GetStringSlice("i18n.fallback") {
+			add(locale)
+		}
+	}
+	add(DefaultLocale())
+
+	return chain
+}
+
+// TranslateNames бичлэг эсвэл жагсаалтын Name талбарыг locales дарааллаар олдсон
+// эхний орчуулгаар солино. Үндсэн хэлэнд хүрвэл байгаа нэрээ үлдээнэ.
+// records нь pointer байх ёстой. Preload хийсэн дэд бичлэгийг орчуулахгүй.
+func TranslateNames(db *gorm.DB, locales []string, records interface{}) error {
+	if len(locales) == 0 || locales[0] == DefaultLocale() {
+		return nil
+	}
+
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(records); err != nil {
+		return err
+	}
+	if !TranslatableEntities[stmt.Schema.Table] {
+		return nil
+	}
+
+	var elems []reflect.Value
+	value := reflect.Indirect(reflect.ValueOf(records))
+	if value.Kind() == reflect.Slice {
+		for i := 0; i < value.Len(); i++ {
+			elems = append(elems, reflect.Indirect(value.Index(i)))
+		}
+	} else {
+		elems = append(elems, value)
+	}
+
+	var ids []uint
+	for _, elem := range elems {
+		if id, _, ok := translatableFields(elem); ok {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var translations []databases.MedTranslation
+	result := db.
+		Where("entity_type = ? AND entity_id IN ? AND locale IN ?", stmt.Schema.Table, ids, locales).
+		Find(&translations)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	names := map[uint]map[string]string{}
+	for _, translation := range translations {
+		if names[translation.EntityID] == nil {
+			names[translation.EntityID] = map[string]string{}
+		}
+		names[translation.EntityID][translation.Locale] = translation.Name
+	}
+
+	for _, elem := range elems {
+		id, name, ok := translatableFields(elem)
+		if !ok {
+			continue
+		}
+		for _, locale := range locales {
+			if locale == DefaultLocale() {
+				break
+			}
+			if translated, ok := names[id][locale]; ok {
+				name.SetString(translated)
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+// translatableFields бичлэгийн ID ба өөрчилж болох Name талбар
+func translatableFields(elem reflect.Value) (uint, reflect.Value, bool) {
+	if elem.Kind() != reflect.Struct {
+		return 0, reflect.Value{}, false
+	}
+	id := elem.FieldByName("ID")
+	name := elem.FieldByName("Name")
+	if !id.IsValid() || id.Kind() != reflect.Uint || !name.IsValid() || name.Kind() != reflect.String || !name.CanSet() {
+		return 0, reflect.Value{}, false
+	}
+	return uint(id.Uint()), name, true
+}
+
+// ListTranslations лавлахын бүх бичлэгийг орчуулгын хамт. locale хоосон бол бүх хэлээр.
+func ListTranslations(db *gorm.DB, entityType, locale string) ([]TranslationRow, error) {
+	if !TranslatableEntities[entityType] {
+		return nil, ErrTranslationEntity
+	}
+
+	rows := []TranslationRow{}
+	result := db.Table(entityType).Select("id AS entity_id, name").Order("id").Scan(&rows)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	translationDB := db.Where("entity_type = ?", entityType)
+	if locale != "" {
+		translationDB = translationDB.Where("locale = ?", normalizeLocale(locale))
+	}
+
+	var translations []databases.MedTranslation
+	result = translationDB.Find(&translations)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	byEntity := map[uint]map[string]string{}
+	for _, translation := range translations {
+		if byEntity[translation.EntityID] == nil {
+			byEntity[translation.EntityID] = map[string]string{}
+		}
+		byEntity[translation.EntityID][translation.Locale] = translation.Name
+	}
+
+	for i := range rows {
+		rows[i].Translations = byEntity[rows[i].EntityID]
+		if rows[i].Translations == nil {
+			rows[i].Translations = map[string]string{}
+		}
+	}
+
+	return rows, nil
+}
+
+// SaveTranslations нэг лавлахын орчуулгуудыг бөөнөөр хадгална
+func SaveTranslations(tx *gorm.DB, entityType string, items []TranslationInput, userID uint) error {
+	if !TranslatableEntities[entityType] {
+		return ErrTranslationEntity
+	}
+
+	idSet := map[uint]bool{}
+	for _, item := range items {
+		if normalizeLocale(item.Locale) == DefaultLocale() {
+			return ErrTranslationLocale
+		}
+		idSet[item.EntityID] = true
+	}
+
+	var ids []uint
+	for id := range idSet {
+		ids = append(ids, id)
+	}
+
+	var count int64
+	result := tx.Table(entityType).Where("id IN ?", ids).Count(&count)
+	if result.Error != nil {
+		return result.Error
+	}
+	if int(count) != len(ids) {
+		return ErrTranslationEntityID
+	}
+
+	for _, item := range items {
+		if err := saveTranslation(tx, entityType, item.EntityID, item.Locale, item.Name, userID); err != nil {
+			return fmt.Errorf("%d/%s: %w", item.EntityID, item.Locale, err)
+		}
+	}
+	return nil
+}
+
+// saveTranslation нэг орчуулгыг upsert хийнэ, name хоосон бол устгана
+func saveTranslation(tx *gorm.DB, entityType string, entityID uint, locale, name string, userID uint) error {
+	locale = normalizeLocale(locale)
+	name = strings.TrimSpace(name)
+
+	if name == "" {
+		return tx.
+			Where("entity_type = ? AND entity_id = ? AND locale = ?", entityType, entityID, locale).
+			Delete(&databases.MedTranslation{}).Error
+	}
+
+	now := time.Now()
+	translation := databases.MedTranslation{
+		EntityType:     entityType,
+		EntityID:       entityID,
+		Locale:         locale,
+		Name:           name,
+		CreatedUserID:  userID,
+		ModifiedUserID: userID,
+		Base: databases.Base{
+			CreatedDate:  now,
+			ModifiedDate: now,
+		},
+	}
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "entity_type"}, {Name: "entity_id"}, {Name: "locale"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "modified_user_id", "modified_date"}),
+	}).Create(&translation).Error
+}
+
+// normalizeLocale en_US -> en-us
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
@@ -0,0 +1,358 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"gitlab.com/fibocloud/medtech/gin/constracts"
+	databases "gitlab.com/fibocloud/medtech/gin/databases"
+	gorm "gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Төлөв удирдах баримтын төрлүүд
+const (
+	DocumentCustomer  = "customer"
+	DocumentOrderBook = "order_book"
+	DocumentIncome    = "income"
+	DocumentOutcome   = "outcome"
+)
+
+// DocumentTables баримтын төрөл, хүснэгтийн нэр. MedStatusLog.HdrTableName-д мөн ашиглана.
+var DocumentTables = map[string]string{
+	DocumentCustomer:  "med_customers",
+	DocumentOrderBook: "med_order_books",
+	DocumentIncome:    "med_incomes",
+	DocumentOutcome:   "med_outcomes",
+}
+
+// Төлөв шилжүүлэх үеийн алдаанууд
+var (
+	ErrUnknownDocument      = errors.New("Баримтын төрөл буруу байна")
+	ErrTransitionNotAllowed = errors.New("Төлөвийн шилжилт зөвшөөрөгдөөгүй байна")
+	ErrTransitionRole       = errors.New("Төлөв шилжүүлэх эрхгүй байна")
+	ErrTransitionComment    = errors.New("Төлөв шилжүүлэх шалтгаан оруулна уу")
+	ErrWorkflowStart        = errors.New("Баримтыг төлөвийн графын анхны төлөвөөс эхлүүлнэ")
+)
+
+// StatusChange төлөв өөрчлөгдсөн үйл явдал
+type StatusChange struct {
+	DocumentType string    `json:"document_type"`
+	RecordID     uint      `json:"record_id"`
+	FromStatusID uint      `json:"from_status_id"`
+	ToStatusID   uint      `json:"to_status_id"`
+	Description  string    `json:"description"`
+	UserID       uint      `json:"user_id"`
+	Date         time.Time `json:"date"`
+}
+
+// StatusHandler төлөв өөрчлөгдөхөд дуудагдах функц
+type StatusHandler func(change StatusChange)
+
+var (
+	statusHandlersMu sync.RWMutex
+	statusHandlers   = make(map[string][]StatusHandler)
+)
+
+// OnStatusChange баримтын төрлийн төлөв өөрчлөгдөх үйл явдлыг сонсоно. "*" бол бүх төрөл.
+func OnStatusChange(documentType string, handler StatusHandler) {
+	statusHandlersMu.Lock()
+	defer statusHandlersMu.Unlock()
+	statusHandlers[documentType] = append(statusHandlers[documentType], handler)
+}
+
+// PublishStatusChange transaction commit хийгдсэний дараа сонсогчдод мэдэгдэнэ
+func PublishStatusChange(changes ...*StatusChange) {
+	statusHandlersMu.RLock()
+	defer statusHandlersMu.RUnlock()
+
+	for _, change := range changes {
+		if change == nil {
+			continue
+		}
+
+		var handlers []StatusHandler
+		handlers = append(handlers, statusHandlers["*"]...)
+		handlers = append(handlers, statusHandlers[change.DocumentType]...)
+		for _, handler := range handlers {
+			func() {
+				defer func() {
+					if r := recover(); r != nil {
+						log.Println("status handler", change.DocumentType, "panic:", r)
+					}
+				}()
+				handler(*change)
+			}()
+		}
+	}
+}
+
+// Workflow баримтын төлөвийг зөвшөөрөгдсөн шилжилтийн дагуу өөрчилнө.
+// Дуудагчийн transaction дотор ажиллана.
+type Workflow struct {
+	DB             *gorm.DB
+	CreditOverride *CreditOverride // Зээлийн хязгаар хэтэрсэн захиалга, зарлагыг эхлүүлэх зөвшөөрөл
+}
+
+// NewWorkflow ...
+func NewWorkflow(tx *gorm.DB) Workflow {
+	return Workflow{DB: tx}
+}
+
+// WithCreditOverride хязгаар хэтэрсэн захиалга, зарлагыг override-оор эхлүүлнэ
+func (w Workflow) WithCreditOverride(override *CreditOverride) Workflow {
+	w.CreditOverride = override
+	return w
+}
+
+// Start шинээр үүссэн баримтын анхны төлөвийг бүртгэнэ. Төлөвийн төрөлд граф тохируулсан
+// бол statusID нь графын анхны төлөв байх ёстой. Захиалга, зарлагын дүнг харилцагчийн
+// зээлийн хязгаараар шалгаж, хэтэрсэн бол анхны төлөвийн түүхэнд тэмдэглэнэ.
+func (w Workflow) Start(documentType string, recordID, statusID uint, user databases.MedSystemUser) (*StatusChange, error) {
+	if _, ok := DocumentTables[documentType]; !ok {
+		return nil, ErrUnknownDocument
+	}
+
+	startID, err := w.startStatus(statusID)
+	if err != nil {
+		return nil, err
+	}
+	if startID != 0 && startID != statusID {
+		return nil, fmt.Errorf("%w: %d", ErrWorkflowStart, startID)
+	}
+
+	change := &StatusChange{
+		DocumentType: documentType,
+		RecordID:     recordID,
+		ToStatusID:   statusID,
+		UserID:       user.Base.ID,
+		Date:         time.Now(),
+	}
+
+	check, err := EnforceDocumentCredit(w.DB, documentType, recordID, w.CreditOverride, user)
+	if err != nil {
+		return nil, err
+	}
+	if check != nil && check.Exceeded {
+		change.Description = creditNote(check, w.CreditOverride)
+	}
+	return change, w.log(change, user)
+}
+
+// Transition баримтыг statusID төлөвт шилжүүлнэ
+func (w Workflow) Transition(documentType string, recordID, statusID uint, description string, user databases.MedSystemUser) (*StatusChange, error) {
+	table, ok := DocumentTables[documentType]
+	if !ok {
+		return nil, ErrUnknownDocument
+	}
+
+	currentStatusID, err := w.currentStatus(table, recordID)
+	if err != nil {
+		return nil, err
+	}
+
+	transition, err := w.findTransition(documentType, currentStatusID, statusID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(transition.Roles) > 0 {
+		allowed, err := w.hasRole(user, transition.Roles)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, ErrTransitionRole
+		}
+	}
+
+	if transition.RequireComment && strings.TrimSpace(description) == "" {
+		return nil, ErrTransitionComment
+	}
+
+	now := time.Now()
+	result := w.DB.Table(table).Where("id = ?", recordID).Updates(map[string]interface{}{
+		"status_id":        statusID,
+		"modified_date":    now,
+		"modified_user_id": user.Base.ID,
+	})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	change := &StatusChange{
+		DocumentType: documentType,
+		RecordID:     recordID,
+		FromStatusID: currentStatusID,
+		ToStatusID:   statusID,
+		Description:  description,
+		UserID:       user.Base.ID,
+		Date:         now,
+	}
+	return change, w.log(change, user)
+}
+
+// Available одоогийн төлөвөөс хэрэглэгчийн хийж болох шилжилтүүд
+func (w Workflow) Available(documentType string, recordID uint, user databases.MedSystemUser) ([]databases.MedStatusTransition, error) {
+	table, ok := DocumentTables[documentType]
+	if !ok {
+		return nil, ErrUnknownDocument
+	}
+
+	var currentStatusID uint
+	result := w.DB.Table(table).Select("status_id").Where("id = ?", recordID).Scan(&currentStatusID)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	var transitions []databases.MedStatusTransition
+	result = w.DB.
+		Where("document_type = ?", documentType).
+		Where("from_status_id = ?", currentStatusID).
+		Where("is_active = ?", true).
+		Preload("ToStatus").
+		Preload("Roles").
+		Find(&transitions)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	var available []databases.MedStatusTransition
+	for _, transition := range transitions {
+		if len(transition.Roles) > 0 {
+			allowed, err := w.hasRole(user, transition.Roles)
+			if err != nil {
+				return nil, err
+			}
+			if !allowed {
+				continue
+			}
+		}
+		available = append(available, transition)
+	}
+	return available, nil
+}
+
+// startStatus statusID-ийн төрлийн графын анхны төлөв, граф тохируулаагүй бол 0
+func (w Workflow) startStatus(statusID uint) (uint, error) {
+	var startID uint
+	result := w.DB.Model(&databases.MedStatusNode{}).
+		Select("med_status_nodes.status_id").
+		Joins("JOIN med_statuses ON med_statuses.id = med_status_nodes.status_id").
+		Where("med_statuses.status_type_id = (?)", w.DB.Model(&databases.MedStatus{}).
+			Select("status_type_id").
+			Where("id = ?", statusID)).
+		Where("med_status_nodes.is_start = ?", true).
+		Limit(1).
+		Scan(&startID)
+	return startID, result.Error
+}
+
+// currentStatus баримтын мөрийг түгжээд одоогийн төлөвийг уншина
+func (w Workflow) currentStatus(table string, recordID uint) (uint, error) {
+	var currentStatusID uint
+	result := w.DB.Table(table).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("status_id").
+		Where("id = ?", recordID).
+		Scan(&currentStatusID)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return 0, gorm.ErrRecordNotFound
+	}
+	return currentStatusID, nil
+}
+
+func (w Workflow) findTransition(documentType string, fromStatusID, toStatusID uint) (databases.MedStatusTransition, error) {
+	var transition databases.MedStatusTransition
+	result := w.DB.
+		Where("document_type = ?", documentType).
+		Where("from_status_id = ?", fromStatusID).
+		Where("to_status_id = ?", toStatusID).
+		Where("is_active = ?", true).
+		Preload("Roles").
+		Limit(1).
+		Find(&transition)
+	if result.Error != nil {
+		return transition, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return transition, fmt.Errorf("%w: %d -> %d", ErrTransitionNotAllowed, fromStatusID, toStatusID)
+	}
+	return transition, nil
+}
+
+func (w Workflow) hasRole(user databases.MedSystemUser, roles []*databases.MedSystemRole) (bool, error) {
+	if user.Base.ID == 0 {
+		return false, nil
+	}
+
+	var userRoles []databases.MedSystemRole
+	err := w.DB.Model(&user).Association("Roles").Find(&userRoles)
+	if err != nil {
+		return false, err
+	}
+
+	for _, userRole := range userRoles {
+		for _, role := range roles {
+			if userRole.Base.ID == role.Base.ID {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func (w Workflow) log(change *StatusChange, user databases.MedSystemUser) error {
+	statusLog := databases.MedStatusLog{
+		CreatedUser:  &user,
+		RecordID:     change.RecordID,
+		StatusID:     change.ToStatusID,
+		Description:  change.Description,
+		HdrTableName: DocumentTables[change.DocumentType],
+		Base: databases.Base{
+			CreatedDate: change.Date,
+		},
+	}
+	return w.DB.Create(&statusLog).Error
+}
+
+// SeedWorkflow системийн өөрөө хийдэг шилжилтүүдийг тохиргоонд байхгүй бол нэмнэ
+func SeedWorkflow(db *gorm.DB) {
+	defaults := []databases.MedStatusTransition{
+		{
+			DocumentType: DocumentCustomer,
+			Name:         "Нэвтрэх эрх үүсгэх",
+			FromStatusID: StatusID(constracts.StatusCustomerAccountConfirmed),
+			ToStatusID:   StatusID(constracts.StatusCustomerPermissionCreated),
+			IsActive:     true,
+		},
+	}
+
+	for _, transition := range defaults {
+		var count int64
+		db.Model(&databases.MedStatusTransition{}).
+			Where("document_type = ?", transition.DocumentType).
+			Where("from_status_id = ?", transition.FromStatusID).
+			Where("to_status_id = ?", transition.ToStatusID).
+			Count(&count)
+		if count > 0 {
+			continue
+		}
+
+		transition.CreatedDate = time.Now()
+		transition.ModifiedDate = time.Now()
+		if err := db.Create(&transition).Error; err != nil {
+			log.Println("workflow seed failed:", err)
+		}
+	}
+}
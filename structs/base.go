@@ -25,4 +25,18 @@ type (
 		ErrorMsg   string `json:"error_msg"`
 		Body       string `json:"body"`
 	}
+
+	// FieldError struct
+	FieldError struct {
+		Field   string `json:"field"`   // addresses[0].street_id
+		Rule    string `json:"rule"`    // required, email, min ...
+		Message string `json:"message"` //
+	}
+
+	// ValidationErrorResponse struct
+	ValidationErrorResponse struct {
+		StatusCode int          `json:"status_code"`
+		ErrorMsg   string       `json:"error_msg"`
+		Body       []FieldError `json:"body"`
+	}
 )
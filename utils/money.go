@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"strings"
+
+	"github.com/shopspring/decimal"
+	"github.com/spf13/viper"
+	databases "gitlab.com/fibocloud/medtech/gin/databases"
+)
+
+// Бүхэлчлэх дүрэм
+const (
+	RoundHalfUp   = "half_up"   // 0.5-аас дээш бол ихэсгэнэ
+	RoundHalfEven = "half_even" // Банкны дүрэм, 0.5 бол тэгш тоо руу
+	RoundUp       = "up"        // Үргэлж ихэсгэнэ
+	RoundDown     = "down"      // Үргэлж таслана
+)
+
+// Money валюттай дүн. float64-ийн алдаагүй тооцоолохын тулд decimal ашиглана.
+type Money struct {
+	Amount   databases.Decimal `json:"amount"`
+	Currency string            `json:"currency"` // MNT, USD
+}
+
+// NewMoney float64 дүнгээс үүсгэнэ
+func NewMoney(amount float64, currency string) Money {
+	return NewMoneyDecimal(decimal.NewFromFloat(amount), currency)
+}
+
+// NewMoneyDecimal decimal дүнгээс үүсгэнэ
+func NewMoneyDecimal(amount decimal.Decimal, currency string) Money {
+	return Money{Amount: databases.NewDecimal(amount), Currency: strings.ToUpper(currency)}
+}
+
+// Add ижил валютын дүн нэмнэ
+func (m Money) Add(other Money) Money {
+	return NewMoneyDecimal(m.Amount.Add(other.Amount.Decimal), m.Currency)
+}
+
+// Sub ижил валютын дүн хасна
+func (m Money) Sub(other Money) Money {
+	return NewMoneyDecimal(m.Amount.Sub(other.Amount.Decimal), m.Currency)
+}
+
+// Mul тоо ширхэг, хувиар үржүүлнэ
+func (m Money) Mul(factor decimal.Decimal) Money {
+	return NewMoneyDecimal(m.Amount.Mul(factor), m.Currency)
+}
+
+// Convert rate ханшаар currency руу хөрвүүлж бүхэлчилнэ
+func (m Money) Convert(rate decimal.Decimal, currency string) Money {
+	return NewMoneyDecimal(m.Amount.Mul(rate), currency).Round()
+}
+
+// Round валютын тохиргооны дүрмээр бүхэлчилнэ
+func (m Money) Round() Money {
+	places, mode := CurrencyRounding(m.Currency)
+	return NewMoneyDecimal(RoundDecimal(m.Amount.Decimal, places, mode), m.Currency)
+}
+
+// Float64 хуучин float талбарт хадгалахад
+func (m Money) Float64() float64 {
+	value, _ := m.Amount.Float64()
+	return value
+}
+
+// CurrencyRounding currency.rounding.<код>.places, mode тохиргоо, үгүй бол 2 орон, half_up
+func CurrencyRounding(currency string) (int32, string) {
+	key := "currency.rounding." + strings.ToUpper(currency)
+
+	places := int32(2)
+	if viper.IsSet(key + ".places") {
+		places = viper.GetInt32(key + ".places")
+	}
+
+	mode := viper.GetString(key + ".mode")
+	if mode == "" {
+		mode = RoundHalfUp
+	}
+	return places, mode
+}
+
+// RoundDecimal places орон хүртэл mode дүрмээр бүхэлчилнэ
+func RoundDecimal(value decimal.Decimal, places int32, mode string) decimal.Decimal {
+	switch mode {
+	case RoundHalfEven:
+		return value.RoundBank(places)
+	case RoundUp:
+		shifted := value.Shift(places)
+		if value.IsNegative() {
+			return shifted.Floor().Shift(-places)
+		}
+		return shifted.Ceil().Shift(-places)
+	case RoundDown:
+		return value.Truncate(places)
+	default:
+		return value.Round(places)
+	}
+}
@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+
+	excelize "github.com/360EntSecGroup-Skylar/excelize/v2"
+)
+
+// ErrSpreadsheetFormat csv, xlsx-ээс өөр файл
+var ErrSpreadsheetFormat = errors.New("Зөвхөн csv, xlsx файл оруулна уу")
+
+// ReadSpreadsheet эхний мөрийг толгой болгож мөр бүрийг багана - утга map болгоно.
+// Толгойн нэрийг жижиг үсэг, зайгүй болгоно. xlsx бол эхний sheet-ийг уншина.
+func ReadSpreadsheet(reader io.Reader, fileName string) ([]map[string]string, error) {
+	var records [][]string
+	var err error
+
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv":
+		csvReader := csv.NewReader(reader)
+		csvReader.FieldsPerRecord = -1
+		csvReader.TrimLeadingSpace = true
+		records, err = csvReader.ReadAll()
+	case ".xlsx":
+		var file *excelize.File
+		file, err = excelize.OpenReader(reader)
+		if err == nil {
+			records, err = file.GetRows(file.GetSheetName(0))
+		}
+	default:
+		return nil, ErrSpreadsheetFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(records) == 0 {
+		return []map[string]string{}, nil
+	}
+
+	headers := make([]string, len(records[0]))
+	for i, header := range records[0] {
+		// Excel-ээс хадгалсан csv-ийн BOM
+		header = strings.TrimPrefix(header, "\ufeff")
+		headers[i] = strings.ToLower(strings.TrimSpace(header))
+	}
+
+	rows := make([]map[string]string, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := map[string]string{}
+		empty := true
+		for i, value := range record {
+			if i >= len(headers) || headers[i] == "" {
+				continue
+			}
+			value = strings.TrimSpace(value)
+			if value != "" {
+				empty = false
+			}
+			row[headers[i]] = value
+		}
+		// хоосон мөрийг алгасахгүй бол мөрийн дугаар зөрнө
+		if empty {
+			row = nil
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}